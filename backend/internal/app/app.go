@@ -26,6 +26,9 @@ type App struct {
 func New() (*App, error) {
 	// Load configuration
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
 
 	// Initialize database
 	db, err := initDB(cfg)
@@ -41,7 +44,9 @@ func New() (*App, error) {
 		&models.Service{},
 		&models.ServiceContainer{},
 		&models.Build{},
+		&models.BuildLog{},
 		&models.Setup{},
+		&models.APIKey{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -55,7 +60,11 @@ func New() (*App, error) {
 	// Initialize services
 	merger := services.NewMerger()
 	linter := services.NewLinter()
-	packager := services.NewPackager(store)
+	packager := services.NewPackagerWithOptions(store, services.PackagerOptions{
+		KeyTemplate:      cfg.PackageKeyTemplate,
+		KubernetesOutput: cfg.KubernetesOutputEnabled,
+		MaxFileSize:      cfg.PackageMaxFileSize,
+	})
 
 	return &App{
 		Config:   cfg,
@@ -83,7 +92,9 @@ func NewWithConfig(cfg *config.Config) (*App, error) {
 		&models.Service{},
 		&models.ServiceContainer{},
 		&models.Build{},
+		&models.BuildLog{},
 		&models.Setup{},
+		&models.APIKey{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -97,7 +108,11 @@ func NewWithConfig(cfg *config.Config) (*App, error) {
 	// Initialize services
 	merger := services.NewMerger()
 	linter := services.NewLinter()
-	packager := services.NewPackager(store)
+	packager := services.NewPackagerWithOptions(store, services.PackagerOptions{
+		KeyTemplate:      cfg.PackageKeyTemplate,
+		KubernetesOutput: cfg.KubernetesOutputEnabled,
+		MaxFileSize:      cfg.PackageMaxFileSize,
+	})
 
 	return &App{
 		Config:   cfg,
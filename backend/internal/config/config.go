@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -27,9 +30,13 @@ type Config struct {
 	S3AccessKeyID       string
 	S3SecretAccessKey   string
 	S3UseSSL            bool
+	S3ForcePathStyle    bool
 	S3PathPrefix        string
 	LocalStoragePath    string
 	LocalStorageMaxSize string
+	StoragePrefix       string
+	PackageKeyTemplate  string
+	PackageMaxFileSize  int64
 
 	// JWT
 	JWTSecret            string
@@ -54,17 +61,43 @@ type Config struct {
 
 	// Build Worker
 	BuildWorkerCount   int
-	BuildTimeout       time.Duration
+	BuildTimeout       time.Duration // cancels a build that runs longer
 	BuildTempDir       string
 	BuildRetentionDays int
 
+	// Logging driver applied to built services that do not declare one
+	BuildDefaultLoggingDriver  string
+	BuildDefaultLoggingOptions map[string]string
+
+	// Resolve docker-compose ${VAR:-default} syntax when merging builds
+	BuildComposeInterpolation bool
+
+	// Webhooks
+	WebhookSecret     string
+	PublishWebhookURL string
+
+	// Build Callbacks
+	BuildCallbackSecret       string
+	BuildCallbackAllowPrivate bool
+
+	// Container Versions
+	EnforceVersionOrder bool
+
+	// Experimental
+	KubernetesOutputEnabled bool
+
 	// Logging
 	LogLevel  string
 	LogFormat string
+
+	// parseErrors holds the variables Load could not parse; Validate
+	// reports them
+	parseErrors []error
 }
 
 func Load() *Config {
-	return &Config{
+	env := &envLoader{}
+	cfg := &Config{
 		// Database
 		DBHost:               getEnv("DB_HOST", "localhost"),
 		DBPort:               getEnv("DB_PORT", "5432"),
@@ -72,53 +105,151 @@ func Load() *Config {
 		DBUser:               getEnv("DB_USER", "burndler"),
 		DBPassword:           getEnv("DB_PASSWORD", "changeme"),
 		DBSSLMode:            getEnv("DB_SSL_MODE", "disable"),
-		DBMaxConnections:     getEnvAsInt("DB_MAX_CONNECTIONS", 25),
-		DBMaxIdleConnections: getEnvAsInt("DB_MAX_IDLE_CONNECTIONS", 5),
-		DBConnectionLifetime: getEnvAsDuration("DB_CONNECTION_LIFETIME", "300s"),
+		DBMaxConnections:     env.getEnvAsInt("DB_MAX_CONNECTIONS", 25),
+		DBMaxIdleConnections: env.getEnvAsInt("DB_MAX_IDLE_CONNECTIONS", 5),
+		DBConnectionLifetime: env.getEnvAsDuration("DB_CONNECTION_LIFETIME", "300s"),
 
 		// Storage
 		StorageMode:         getEnv("STORAGE_MODE", "local"),
-		S3Endpoint:          getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+		S3Endpoint:          getEnv("S3_ENDPOINT", ""),
 		S3Region:            getEnv("S3_REGION", "us-east-1"),
 		S3Bucket:            getEnv("S3_BUCKET", "burndler-artifacts"),
 		S3AccessKeyID:       getEnv("S3_ACCESS_KEY_ID", ""),
 		S3SecretAccessKey:   getEnv("S3_SECRET_ACCESS_KEY", ""),
-		S3UseSSL:            getEnvAsBool("S3_USE_SSL", true),
+		S3UseSSL:            env.getEnvAsBool("S3_USE_SSL", true),
+		S3ForcePathStyle:    env.getEnvAsBool("S3_FORCE_PATH_STYLE", true),
 		S3PathPrefix:        getEnv("S3_PATH_PREFIX", "packages/"),
 		LocalStoragePath:    getEnv("LOCAL_STORAGE_PATH", "/tmp/burndler/storage"),
 		LocalStorageMaxSize: getEnv("LOCAL_STORAGE_MAX_SIZE", "10GB"),
+		StoragePrefix:       getEnv("STORAGE_PREFIX", ""),
+		PackageKeyTemplate:  getEnv("PACKAGE_KEY_TEMPLATE", "{name}-{build_id}"),
+		PackageMaxFileSize:  env.getEnvAsInt64("PACKAGE_MAX_FILE_SIZE", 10*1024*1024), // 10MB
 
 		// JWT
 		JWTSecret:            getEnv("JWT_SECRET", "changeme-generate-secure-secret"),
 		JWTIssuer:            getEnv("JWT_ISSUER", "burndler"),
 		JWTAudience:          getEnv("JWT_AUDIENCE", "burndler-api"),
-		JWTExpiration:        getEnvAsDuration("JWT_EXPIRATION", "24h"),
-		JWTRefreshExpiration: getEnvAsDuration("JWT_REFRESH_EXPIRATION", "168h"),
+		JWTExpiration:        env.getEnvAsDuration("JWT_EXPIRATION", "24h"),
+		JWTRefreshExpiration: env.getEnvAsDuration("JWT_REFRESH_EXPIRATION", "168h"),
 
 		// Server
 		ServerPort:           getEnv("SERVER_PORT", "8080"),
 		ServerHost:           getEnv("SERVER_HOST", "0.0.0.0"),
-		ServerReadTimeout:    getEnvAsDuration("SERVER_READ_TIMEOUT", "30s"),
-		ServerWriteTimeout:   getEnvAsDuration("SERVER_WRITE_TIMEOUT", "30s"),
-		ServerMaxRequestSize: getEnvAsInt64("SERVER_MAX_REQUEST_SIZE", 100*1024*1024), // 100MB
+		ServerReadTimeout:    env.getEnvAsDuration("SERVER_READ_TIMEOUT", "30s"),
+		ServerWriteTimeout:   env.getEnvAsDuration("SERVER_WRITE_TIMEOUT", "30s"),
+		ServerMaxRequestSize: env.getEnvAsInt64("SERVER_MAX_REQUEST_SIZE", 100*1024*1024), // 100MB
 
 		// CORS
 		CORSAllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
 
 		// Static Files
 		StaticFilesPath:  getEnv("STATIC_FILES_PATH", "../frontend/dist"),
-		ServeStaticFiles: getEnvAsBool("SERVE_STATIC_FILES", true),
+		ServeStaticFiles: env.getEnvAsBool("SERVE_STATIC_FILES", true),
 
 		// Build Worker
-		BuildWorkerCount:   getEnvAsInt("BUILD_WORKER_COUNT", 4),
-		BuildTimeout:       getEnvAsDuration("BUILD_TIMEOUT", "30m"),
+		BuildWorkerCount:   env.getEnvAsInt("BUILD_WORKER_COUNT", 4),
+		BuildTimeout:       env.getEnvAsDuration("BUILD_TIMEOUT", "30m"),
 		BuildTempDir:       getEnv("BUILD_TEMP_DIR", "/tmp/burndler-builds"),
-		BuildRetentionDays: getEnvAsInt("BUILD_RETENTION_DAYS", 7),
+		BuildRetentionDays: env.getEnvAsInt("BUILD_RETENTION_DAYS", 7),
+
+		BuildDefaultLoggingDriver:  getEnv("BUILD_DEFAULT_LOGGING_DRIVER", ""),
+		BuildDefaultLoggingOptions: env.getEnvAsMap("BUILD_DEFAULT_LOGGING_OPTIONS"),
+		BuildComposeInterpolation:  env.getEnvAsBool("BUILD_COMPOSE_INTERPOLATION", false),
+
+		// Webhooks
+		WebhookSecret:     getEnv("WEBHOOK_SECRET", ""),
+		PublishWebhookURL: getEnv("PUBLISH_WEBHOOK_URL", ""),
+
+		// Build Callbacks
+		BuildCallbackSecret:       getEnv("BUILD_CALLBACK_SECRET", ""),
+		BuildCallbackAllowPrivate: env.getEnvAsBool("BUILD_CALLBACK_ALLOW_PRIVATE", false),
+
+		// Container Versions
+		EnforceVersionOrder: env.getEnvAsBool("ENFORCE_VERSION_ORDER", false),
+
+		// Experimental
+		KubernetesOutputEnabled: env.getEnvAsBool("EXPERIMENTAL_KUBERNETES_OUTPUT", false),
 
 		// Logging
 		LogLevel:  getEnv("LOG_LEVEL", "info"),
 		LogFormat: getEnv("LOG_FORMAT", "json"),
 	}
+	cfg.parseErrors = env.errs
+	return cfg
+}
+
+// Validate checks for missing or contradictory settings and returns every
+// problem found, naming the environment variable to fix
+func (c *Config) Validate() error {
+	errs := append([]error{}, c.parseErrors...)
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	switch c.StorageMode {
+	case "s3":
+		if c.S3Bucket == "" {
+			add("S3_BUCKET is required when STORAGE_MODE=s3")
+		}
+		if c.S3Region == "" {
+			add("S3_REGION is required when STORAGE_MODE=s3")
+		}
+		if (c.S3AccessKeyID == "") != (c.S3SecretAccessKey == "") {
+			add("S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set together")
+		}
+	case "local":
+		if c.LocalStoragePath == "" {
+			add("LOCAL_STORAGE_PATH is required when STORAGE_MODE=local")
+		}
+	default:
+		add("STORAGE_MODE must be \"local\" or \"s3\", got %q", c.StorageMode)
+	}
+	if c.PackageMaxFileSize <= 0 {
+		add("PACKAGE_MAX_FILE_SIZE must be positive, got %d", c.PackageMaxFileSize)
+	}
+
+	if c.JWTSecret == "" {
+		add("JWT_SECRET is required")
+	}
+	if c.JWTExpiration <= 0 {
+		add("JWT_EXPIRATION must be positive, got %s", c.JWTExpiration)
+	}
+	if c.JWTRefreshExpiration < c.JWTExpiration {
+		add("JWT_REFRESH_EXPIRATION (%s) must not be shorter than JWT_EXPIRATION (%s)", c.JWTRefreshExpiration, c.JWTExpiration)
+	}
+
+	if port, err := strconv.Atoi(c.ServerPort); err != nil || port < 1 || port > 65535 {
+		add("SERVER_PORT must be a port number between 1 and 65535, got %q", c.ServerPort)
+	}
+
+	if c.DBMaxConnections < 1 {
+		add("DB_MAX_CONNECTIONS must be at least 1, got %d", c.DBMaxConnections)
+	}
+	if c.DBMaxIdleConnections > c.DBMaxConnections {
+		add("DB_MAX_IDLE_CONNECTIONS (%d) must not exceed DB_MAX_CONNECTIONS (%d)", c.DBMaxIdleConnections, c.DBMaxConnections)
+	}
+
+	if c.BuildWorkerCount < 1 {
+		add("BUILD_WORKER_COUNT must be at least 1, got %d", c.BuildWorkerCount)
+	}
+	if c.BuildTimeout <= 0 {
+		add("BUILD_TIMEOUT must be positive, got %s", c.BuildTimeout)
+	}
+	if c.BuildRetentionDays < 0 {
+		add("BUILD_RETENTION_DAYS must not be negative, got %d", c.BuildRetentionDays)
+	}
+
+	if len(c.BuildDefaultLoggingOptions) > 0 && c.BuildDefaultLoggingDriver == "" {
+		add("BUILD_DEFAULT_LOGGING_OPTIONS requires BUILD_DEFAULT_LOGGING_DRIVER")
+	}
+
+	if c.PublishWebhookURL != "" {
+		if u, err := url.Parse(c.PublishWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add("PUBLISH_WEBHOOK_URL must be an absolute http or https URL, got %q", c.PublishWebhookURL)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 func getEnv(key, defaultValue string) string {
@@ -128,43 +259,79 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
+// envLoader reads typed environment variables, falling back to the default
+// when a value cannot be parsed and recording the failure so Validate can
+// report it
+type envLoader struct {
+	errs []error
+}
+
+func (l *envLoader) invalid(key, value, want string) {
+	l.errs = append(l.errs, fmt.Errorf("%s must be %s, got %q", key, want, value))
+}
+
+func (l *envLoader) getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
+		intVal, err := strconv.Atoi(value)
+		if err == nil {
 			return intVal
 		}
+		l.invalid(key, value, "an integer")
 	}
 	return defaultValue
 }
 
-func getEnvAsInt64(key string, defaultValue int64) int64 {
+func (l *envLoader) getEnvAsInt64(key string, defaultValue int64) int64 {
 	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.ParseInt(value, 10, 64); err == nil {
+		intVal, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
 			return intVal
 		}
+		l.invalid(key, value, "an integer")
 	}
 	return defaultValue
 }
 
-func getEnvAsBool(key string, defaultValue bool) bool {
+func (l *envLoader) getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
-		if boolVal, err := strconv.ParseBool(value); err == nil {
+		boolVal, err := strconv.ParseBool(value)
+		if err == nil {
 			return boolVal
 		}
+		l.invalid(key, value, "true or false")
 	}
 	return defaultValue
 }
 
-func getEnvAsDuration(key string, defaultValue string) time.Duration {
+func (l *envLoader) getEnvAsDuration(key string, defaultValue string) time.Duration {
 	value := getEnv(key, defaultValue)
 	if duration, err := time.ParseDuration(value); err == nil {
 		return duration
 	}
+	l.invalid(key, value, "a duration such as 30s or 5m")
 	// Fallback to default
 	duration, _ := time.ParseDuration(defaultValue)
 	return duration
 }
 
+// getEnvAsMap reads comma-separated key=value pairs
+func (l *envLoader) getEnvAsMap(key string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || k == "" {
+			l.invalid(key, value, "comma-separated key=value pairs")
+			return nil
+		}
+		result[k] = v
+	}
+	return result
+}
+
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		return strings.Split(value, ",")
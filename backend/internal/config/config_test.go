@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -34,9 +36,27 @@ func TestLoad(t *testing.T) {
 	if cfg.S3Region != "us-east-1" {
 		t.Errorf("S3Region = %v, want %v", cfg.S3Region, "us-east-1")
 	}
+	if cfg.S3Endpoint != "" {
+		t.Errorf("S3Endpoint = %v, want empty for the regional AWS endpoint", cfg.S3Endpoint)
+	}
 	if !cfg.S3UseSSL {
 		t.Errorf("S3UseSSL = %v, want %v", cfg.S3UseSSL, true)
 	}
+	if !cfg.S3ForcePathStyle {
+		t.Errorf("S3ForcePathStyle = %v, want %v", cfg.S3ForcePathStyle, true)
+	}
+	if cfg.PackageKeyTemplate != "{name}-{build_id}" {
+		t.Errorf("PackageKeyTemplate = %v, want %v", cfg.PackageKeyTemplate, "{name}-{build_id}")
+	}
+	if cfg.PackageMaxFileSize != 10*1024*1024 {
+		t.Errorf("PackageMaxFileSize = %v, want %v", cfg.PackageMaxFileSize, 10*1024*1024)
+	}
+	if cfg.KubernetesOutputEnabled {
+		t.Errorf("KubernetesOutputEnabled = %v, want %v", cfg.KubernetesOutputEnabled, false)
+	}
+	if cfg.EnforceVersionOrder {
+		t.Errorf("EnforceVersionOrder = %v, want %v", cfg.EnforceVersionOrder, false)
+	}
 
 	// Server defaults
 	if cfg.ServerPort != "8080" {
@@ -72,6 +92,9 @@ func TestLoadWithEnvVars(t *testing.T) {
 	if err := os.Setenv("S3_BUCKET", "test-bucket"); err != nil {
 		t.Fatalf("Failed to set S3_BUCKET: %v", err)
 	}
+	if err := os.Setenv("STORAGE_PREFIX", "tenant-a"); err != nil {
+		t.Fatalf("Failed to set STORAGE_PREFIX: %v", err)
+	}
 	if err := os.Setenv("S3_USE_SSL", "false"); err != nil {
 		t.Fatalf("Failed to set S3_USE_SSL: %v", err)
 	}
@@ -105,6 +128,9 @@ func TestLoadWithEnvVars(t *testing.T) {
 		if err := os.Unsetenv("S3_BUCKET"); err != nil {
 			t.Logf("Warning: failed to unset S3_BUCKET: %v", err)
 		}
+		if err := os.Unsetenv("STORAGE_PREFIX"); err != nil {
+			t.Logf("Warning: failed to unset STORAGE_PREFIX: %v", err)
+		}
 		if err := os.Unsetenv("S3_USE_SSL"); err != nil {
 			t.Logf("Warning: failed to unset S3_USE_SSL: %v", err)
 		}
@@ -140,6 +166,9 @@ func TestLoadWithEnvVars(t *testing.T) {
 	if cfg.S3Bucket != "test-bucket" {
 		t.Errorf("S3Bucket = %v, want %v", cfg.S3Bucket, "test-bucket")
 	}
+	if cfg.StoragePrefix != "tenant-a" {
+		t.Errorf("StoragePrefix = %v, want %v", cfg.StoragePrefix, "tenant-a")
+	}
 	if cfg.S3UseSSL != false {
 		t.Errorf("S3UseSSL = %v, want %v", cfg.S3UseSSL, false)
 	}
@@ -254,7 +283,7 @@ func TestGetEnvAsInt(t *testing.T) {
 				}()
 			}
 
-			result := getEnvAsInt(tt.key, tt.defaultValue)
+			result := (&envLoader{}).getEnvAsInt(tt.key, tt.defaultValue)
 			if result != tt.expected {
 				t.Errorf("getEnvAsInt() = %v, want %v", result, tt.expected)
 			}
@@ -306,7 +335,7 @@ func TestGetEnvAsInt64(t *testing.T) {
 				}()
 			}
 
-			result := getEnvAsInt64(tt.key, tt.defaultValue)
+			result := (&envLoader{}).getEnvAsInt64(tt.key, tt.defaultValue)
 			if result != tt.expected {
 				t.Errorf("getEnvAsInt64() = %v, want %v", result, tt.expected)
 			}
@@ -379,7 +408,7 @@ func TestGetEnvAsBool(t *testing.T) {
 				}()
 			}
 
-			result := getEnvAsBool(tt.key, tt.defaultValue)
+			result := (&envLoader{}).getEnvAsBool(tt.key, tt.defaultValue)
 			if result != tt.expected {
 				t.Errorf("getEnvAsBool() = %v, want %v", result, tt.expected)
 			}
@@ -445,7 +474,7 @@ func TestGetEnvAsDuration(t *testing.T) {
 				}()
 			}
 
-			result := getEnvAsDuration(tt.key, tt.defaultValue)
+			result := (&envLoader{}).getEnvAsDuration(tt.key, tt.defaultValue)
 			if result != tt.expected {
 				t.Errorf("getEnvAsDuration() = %v, want %v", result, tt.expected)
 			}
@@ -517,6 +546,35 @@ func TestGetEnvAsSlice(t *testing.T) {
 	}
 }
 
+func TestGetEnvAsMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{name: "returns nil when env not set", envValue: "", expected: nil},
+		{name: "parses pairs", envValue: "max-size=10m, max-file=3", expected: map[string]string{"max-size": "10m", "max-file": "3"}},
+		{name: "allows empty values", envValue: "tag=", expected: map[string]string{"tag": ""}},
+		{name: "rejects entries without =", envValue: "max-size=10m,verbose", expected: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_MAP_VAR", tt.envValue)
+
+			env := &envLoader{}
+			result := env.getEnvAsMap("TEST_MAP_VAR")
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("getEnvAsMap() = %v, want %v", result, tt.expected)
+			}
+			if (len(env.errs) > 0) != tt.wantErr {
+				t.Errorf("getEnvAsMap() errors = %v, wantErr %v", env.errs, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestDurationValues(t *testing.T) {
 	// Test duration parsing with actual config
 	if err := os.Setenv("JWT_EXPIRATION", "48h"); err != nil {
@@ -599,4 +657,128 @@ func TestInvalidTypeConversions(t *testing.T) {
 	if cfg.BuildTimeout != 30*time.Minute {
 		t.Errorf("BuildTimeout = %v, want %v (default)", cfg.BuildTimeout, 30*time.Minute)
 	}
+
+	// Validate reports every value that could not be parsed
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors for unparseable values")
+	}
+	for _, want := range []string{
+		`DB_MAX_CONNECTIONS must be an integer, got "not-a-number"`,
+		`S3_USE_SSL must be true or false, got "maybe"`,
+		`SERVER_MAX_REQUEST_SIZE must be an integer, got "way-too-big"`,
+		`BUILD_TIMEOUT must be a duration such as 30s or 5m, got "forever"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+// validConfig returns a configuration that passes Validate
+func validConfig() *Config {
+	return &Config{
+		StorageMode:          "local",
+		LocalStoragePath:     "/tmp/burndler/storage",
+		PackageMaxFileSize:   10 * 1024 * 1024,
+		JWTSecret:            "secret",
+		JWTExpiration:        24 * time.Hour,
+		JWTRefreshExpiration: 168 * time.Hour,
+		ServerPort:           "8080",
+		DBMaxConnections:     25,
+		DBMaxIdleConnections: 5,
+		BuildWorkerCount:     4,
+		BuildTimeout:         30 * time.Minute,
+		BuildRetentionDays:   7,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr []string
+	}{
+		{
+			name:   "valid config",
+			modify: func(c *Config) {},
+		},
+		{
+			name: "valid s3 config",
+			modify: func(c *Config) {
+				c.StorageMode = "s3"
+				c.S3Bucket = "artifacts"
+				c.S3Region = "us-east-1"
+			},
+		},
+		{
+			name: "s3 without bucket",
+			modify: func(c *Config) {
+				c.StorageMode = "s3"
+				c.S3Region = "us-east-1"
+			},
+			wantErr: []string{"S3_BUCKET is required when STORAGE_MODE=s3"},
+		},
+		{
+			name:    "zero worker count",
+			modify:  func(c *Config) { c.BuildWorkerCount = 0 },
+			wantErr: []string{"BUILD_WORKER_COUNT must be at least 1, got 0"},
+		},
+		{
+			name:    "logging options without a driver",
+			modify:  func(c *Config) { c.BuildDefaultLoggingOptions = map[string]string{"max-size": "10m"} },
+			wantErr: []string{"BUILD_DEFAULT_LOGGING_OPTIONS requires BUILD_DEFAULT_LOGGING_DRIVER"},
+		},
+		{
+			name:    "unknown storage mode",
+			modify:  func(c *Config) { c.StorageMode = "ftp" },
+			wantErr: []string{`STORAGE_MODE must be "local" or "s3", got "ftp"`},
+		},
+		{
+			name: "every problem is reported",
+			modify: func(c *Config) {
+				c.ServerPort = "http"
+				c.DBMaxIdleConnections = 30
+				c.PublishWebhookURL = "hooks.example.com"
+			},
+			wantErr: []string{
+				`SERVER_PORT must be a port number between 1 and 65535, got "http"`,
+				"DB_MAX_IDLE_CONNECTIONS (30) must not exceed DB_MAX_CONNECTIONS (25)",
+				`PUBLISH_WEBHOOK_URL must be an absolute http or https URL, got "hooks.example.com"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors %v", tt.wantErr)
+			}
+			got := strings.Split(err.Error(), "\n")
+			if len(got) != len(tt.wantErr) {
+				t.Fatalf("Validate() = %q, want %q", got, tt.wantErr)
+			}
+			for i := range got {
+				if got[i] != tt.wantErr[i] {
+					t.Errorf("Validate() error %d = %q, want %q", i, got[i], tt.wantErr[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidate_Defaults(t *testing.T) {
+	if err := Load().Validate(); err != nil {
+		t.Errorf("default configuration is invalid: %v", err)
+	}
 }
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/burndler/burndler/internal/middleware"
+	"github.com/burndler/burndler/internal/models"
+	"github.com/burndler/burndler/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles API key management endpoints
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	UserID    uint       `json:"user_id"` // owner; defaults to the caller
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreateAPIKeyResponse returns a new key. The plain key is only shown once.
+type CreateAPIKeyResponse struct {
+	APIKey *models.APIKey `json:"api_key"`
+	Key    string         `json:"key"`
+}
+
+// CreateAPIKey handles POST /api/v1/admin/api-keys
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "A name and at least one scope are required",
+		})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !middleware.IsKnownPermission(middleware.Permission(scope)) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_SCOPE",
+				Message: fmt.Sprintf("Unknown scope '%s'", scope),
+			})
+			return
+		}
+	}
+
+	if req.UserID == 0 {
+		userID, ok := currentUserID(c)
+		if !ok {
+			return
+		}
+		req.UserID = userID
+	}
+
+	apiKey, key, err := h.apiKeyService.CreateAPIKey(services.CreateAPIKeyRequest{
+		Name:      req.Name,
+		UserID:    req.UserID,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "USER_NOT_FOUND",
+				Message: "User not found",
+			})
+		case err.Error() == "api key name is required",
+			err.Error() == "expiry must be in the future":
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_REQUEST",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to create API key",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: apiKey, Key: key})
+}
+
+// ListAPIKeys handles GET /api/v1/admin/api-keys
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListAPIKeys()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to list API keys",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// RevokeAPIKey handles DELETE /api/v1/admin/api-keys/:id
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid API key ID",
+		})
+		return
+	}
+
+	apiKey, err := h.apiKeyService.RevokeAPIKey(uint(id))
+	if err != nil {
+		switch err.Error() {
+		case "api key not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "API_KEY_NOT_FOUND",
+				Message: "API key not found",
+			})
+		case "api key is already revoked":
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "API_KEY_REVOKED",
+				Message: "API key is already revoked",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to revoke API key",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKey)
+}
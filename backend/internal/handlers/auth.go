@@ -65,6 +65,7 @@ type UserResponse struct {
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
+	Details string `json:"details,omitempty"`
 }
 
 // Login handles user authentication
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/burndler/burndler/internal/middleware"
+	"github.com/burndler/burndler/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BuildHandler handles service build HTTP endpoints
+type BuildHandler struct {
+	buildService *services.BuildService
+}
+
+// NewBuildHandler creates a new build handler
+func NewBuildHandler(buildService *services.BuildService) *BuildHandler {
+	return &BuildHandler{
+		buildService: buildService,
+	}
+}
+
+// ForceFailBuildRequest represents the request to force a build to fail
+type ForceFailBuildRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ForceFailBuild handles POST /api/v1/admin/builds/:build_id/fail
+func (h *BuildHandler) ForceFailBuild(c *gin.Context) {
+	buildID, err := uuid.Parse(c.Param("build_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_BUILD_ID",
+			Message: "Invalid build ID format",
+		})
+		return
+	}
+
+	var req ForceFailBuildRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "A reason is required",
+		})
+		return
+	}
+
+	build, err := h.buildService.ForceFailBuild(buildID, req.Reason)
+	if err != nil {
+		h.respondBuildError(c, err, "Failed to fail build")
+		return
+	}
+
+	c.JSON(http.StatusOK, build)
+}
+
+// RetryBuild handles POST /api/v1/admin/builds/:build_id/retry
+func (h *BuildHandler) RetryBuild(c *gin.Context) {
+	buildID, err := uuid.Parse(c.Param("build_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_BUILD_ID",
+			Message: "Invalid build ID format",
+		})
+		return
+	}
+
+	build, err := h.buildService.RetryBuild(buildID, c.GetString(middleware.RequestIDKey))
+	if err != nil {
+		h.respondBuildError(c, err, "Failed to retry build")
+		return
+	}
+
+	h.buildService.ExecuteBuildAsync(build.ID)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":  "Build retry initiated",
+		"build_id": build.ID.String(),
+		"status":   build.Status,
+	})
+}
+
+// respondBuildError maps build state errors to HTTP responses
+func (h *BuildHandler) respondBuildError(c *gin.Context, err error, message string) {
+	switch err.Error() {
+	case "build not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "BUILD_NOT_FOUND",
+			Message: "Build not found",
+		})
+	case "build is not in progress",
+		"only failed or cancelled builds can be retried",
+		"only service builds can be retried",
+		"build is still being processed":
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:   "INVALID_BUILD_STATE",
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: message,
+		})
+	}
+}
+
+// GetBuildSummary handles GET /api/v1/services/:id/build/:build_id/summary
+func (h *BuildHandler) GetBuildSummary(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid service ID",
+		})
+		return
+	}
+
+	buildID, err := uuid.Parse(c.Param("build_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_BUILD_ID",
+			Message: "Invalid build ID format",
+		})
+		return
+	}
+
+	summary, err := h.buildService.GetBuildSummary(uint(id), buildID)
+	if err != nil {
+		switch err.Error() {
+		case "build not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "BUILD_NOT_FOUND",
+				Message: "Build not found",
+			})
+		case "build summary not available":
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "SUMMARY_NOT_AVAILABLE",
+				Message: "Build summary is only available for completed builds",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to get build summary",
+			})
+		}
+		return
+	}
+
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(summary))
+}
+
+// GetBuildLogs handles GET /api/v1/services/:id/build/:build_id/logs
+func (h *BuildHandler) GetBuildLogs(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid service ID",
+		})
+		return
+	}
+
+	buildID, err := uuid.Parse(c.Param("build_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_BUILD_ID",
+			Message: "Invalid build ID format",
+		})
+		return
+	}
+
+	logs, err := h.buildService.GetBuildLogs(uint(id), buildID)
+	if err != nil {
+		if err.Error() == "build not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "BUILD_NOT_FOUND",
+				Message: "Build not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to get build logs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"build_id": buildID.String(),
+		"logs":     logs,
+	})
+}
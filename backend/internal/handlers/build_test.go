@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/burndler/burndler/internal/models"
+	"github.com/burndler/burndler/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// failingUploadStorage fails every upload
+type failingUploadStorage struct {
+	mockStorage
+}
+
+func (m *failingUploadStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64) (string, error) {
+	return "", errors.New("bucket unavailable")
+}
+
+func setupBuildHandlerTest(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.Container{},
+		&models.ContainerVersion{},
+		&models.Service{},
+		&models.ServiceContainer{},
+		&models.Build{},
+		&models.BuildLog{},
+	)
+	assert.NoError(t, err)
+
+	// Background builds must see the same in-memory database
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	return db
+}
+
+func createQueuedServiceBuild(t *testing.T, db *gorm.DB) (*models.Service, *models.Build) {
+	user := createTestUser(t, db, "Developer")
+
+	container := &models.Container{Name: "web"}
+	assert.NoError(t, db.Create(container).Error)
+	version := &models.ContainerVersion{
+		ContainerID:    container.ID,
+		Version:        "v1.0.0",
+		ComposeContent: "services:\n  app:\n    image: nginx:1.25.3",
+	}
+	assert.NoError(t, db.Create(version).Error)
+
+	service := &models.Service{Name: "shop", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(service).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{
+		ServiceID:          service.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+	}).Error)
+
+	build := &models.Build{Name: service.Name, ServiceID: &service.ID, UserID: user.ID, Status: "queued"}
+	assert.NoError(t, db.Create(build).Error)
+
+	return service, build
+}
+
+func TestBuildHandler_GetBuildLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		packager      *services.Packager
+		wantLastLevel string
+		wantLastStage string
+	}{
+		{
+			name:          "completed build",
+			packager:      services.NewPackager(&mockStorage{}),
+			wantLastLevel: "info",
+			wantLastStage: services.BuildStagePackage,
+		},
+		{
+			name:          "failed build",
+			packager:      services.NewPackager(&failingUploadStorage{}),
+			wantLastLevel: "error",
+			wantLastStage: services.BuildStagePackage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupBuildHandlerTest(t)
+			buildService := services.NewBuildService(db, services.NewMerger(), services.NewLinter(), tt.packager)
+			handler := NewBuildHandler(buildService)
+
+			service, build := createQueuedServiceBuild(t, db)
+			_ = buildService.ExecuteBuild(context.Background(), build.ID)
+
+			router := gin.New()
+			router.GET("/services/:id/build/:build_id/logs", handler.GetBuildLogs)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/services/%d/build/%s/logs", service.ID, build.ID), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				BuildID string            `json:"build_id"`
+				Logs    []models.BuildLog `json:"logs"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, build.ID.String(), response.BuildID)
+			assert.NotEmpty(t, response.Logs)
+
+			assert.Equal(t, services.BuildStageValidation, response.Logs[0].Stage)
+			for i := 1; i < len(response.Logs); i++ {
+				assert.False(t, response.Logs[i].Timestamp.Before(response.Logs[i-1].Timestamp), "logs must be ordered by time")
+			}
+
+			last := response.Logs[len(response.Logs)-1]
+			assert.Equal(t, tt.wantLastStage, last.Stage)
+			assert.Equal(t, tt.wantLastLevel, last.Level)
+			if tt.wantLastLevel == "error" {
+				assert.Contains(t, last.Message, "bucket unavailable")
+			}
+		})
+	}
+}
+
+func TestBuildHandler_GetBuildLogs_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupBuildHandlerTest(t)
+	handler := NewBuildHandler(services.NewBuildService(db, services.NewMerger(), services.NewLinter(), services.NewPackager(&mockStorage{})))
+
+	service, build := createQueuedServiceBuild(t, db)
+
+	router := gin.New()
+	router.GET("/services/:id/build/:build_id/logs", handler.GetBuildLogs)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"invalid build id", fmt.Sprintf("/services/%d/build/not-a-uuid/logs", service.ID), http.StatusBadRequest},
+		{"build of another service", fmt.Sprintf("/services/%d/build/%s/logs", service.ID+1, build.ID), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestBuildHandler_ForceFailBuild(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupBuildHandlerTest(t)
+	handler := NewBuildHandler(services.NewBuildService(db, services.NewMerger(), services.NewLinter(), services.NewPackager(&mockStorage{})))
+
+	_, build := createQueuedServiceBuild(t, db)
+	assert.NoError(t, db.Model(build).Update("status", "building").Error)
+
+	router := gin.New()
+	router.POST("/admin/builds/:build_id/fail", handler.ForceFailBuild)
+
+	tests := []struct {
+		name           string
+		buildID        string
+		body           string
+		expectedStatus int
+	}{
+		{name: "force-fail in-progress build", buildID: build.ID.String(), body: `{"reason":"stuck"}`, expectedStatus: http.StatusOK},
+		{name: "build already failed", buildID: build.ID.String(), body: `{"reason":"stuck"}`, expectedStatus: http.StatusConflict},
+		{name: "missing reason", buildID: build.ID.String(), body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid build ID", buildID: "not-a-uuid", body: `{"reason":"stuck"}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/builds/"+tt.buildID+"/fail", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "failed", updated.Status)
+	assert.Equal(t, "stuck", updated.Error)
+}
+
+func TestBuildHandler_RetryBuild(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupBuildHandlerTest(t)
+	handler := NewBuildHandler(services.NewBuildService(db, services.NewMerger(), services.NewLinter(), services.NewPackager(&mockStorage{})))
+
+	_, build := createQueuedServiceBuild(t, db)
+	assert.NoError(t, db.Model(build).Updates(map[string]interface{}{"status": "failed", "error": "bucket unavailable"}).Error)
+
+	router := gin.New()
+	router.POST("/admin/builds/:build_id/retry", handler.RetryBuild)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/builds/"+build.ID.String()+"/retry", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// The re-enqueued build runs to completion in the background
+	assert.Eventually(t, func() bool {
+		var current models.Build
+		return db.First(&current, "id = ?", build.ID).Error == nil && current.IsComplete()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Completed builds cannot be retried
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/builds/"+build.ID.String()+"/retry", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/builds/"+uuid.New().String()+"/retry", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
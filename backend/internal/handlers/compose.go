@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/burndler/burndler/internal/services"
 	"github.com/gin-gonic/gin"
@@ -25,19 +26,18 @@ func NewComposeHandler(merger *services.Merger, linter *services.Linter) *Compos
 func (h *ComposeHandler) Merge(c *gin.Context) {
 	var req services.MergeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "INVALID_REQUEST",
-			"message": "Invalid merge request",
-			"details": err.Error(),
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid merge request",
 		})
 		return
 	}
 
 	// Validate input
 	if len(req.Modules) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "NO_MODULES",
-			"message": "At least one module is required",
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "NO_MODULES",
+			Message: "At least one module is required",
 		})
 		return
 	}
@@ -45,10 +45,14 @@ func (h *ComposeHandler) Merge(c *gin.Context) {
 	// Perform merge
 	result, err := h.merger.Merge(&req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "MERGE_FAILED",
-			"message": "Failed to merge compose files",
-			"details": err.Error(),
+		status := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "failed to marshal") {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "MERGE_FAILED",
+			Message: "Failed to merge compose files",
+			Details: err.Error(),
 		})
 		return
 	}
@@ -60,30 +64,30 @@ func (h *ComposeHandler) Merge(c *gin.Context) {
 func (h *ComposeHandler) Lint(c *gin.Context) {
 	var req services.LintRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "INVALID_REQUEST",
-			"message": "Invalid lint request",
-			"details": err.Error(),
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid lint request",
 		})
 		return
 	}
 
 	// Validate input
 	if req.Compose == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "NO_COMPOSE",
-			"message": "Compose content is required",
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "NO_COMPOSE",
+			Message: "Compose content is required",
 		})
 		return
 	}
 
 	// Perform lint
+	// Lint only fails on compose it cannot parse
 	result, err := h.linter.Lint(&req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "LINT_FAILED",
-			"message": "Failed to lint compose file",
-			"details": err.Error(),
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "LINT_FAILED",
+			Message: "Failed to lint compose file",
+			Details: err.Error(),
 		})
 		return
 	}
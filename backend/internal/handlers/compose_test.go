@@ -32,11 +32,12 @@ func TestComposeHandler_Merge(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
-		name           string
-		requestBody    interface{}
-		expectedStatus int
-		expectedError  string
-		checkResponse  func(t *testing.T, body []byte)
+		name            string
+		requestBody     interface{}
+		expectedStatus  int
+		expectedError   string
+		expectedDetails string
+		checkResponse   func(t *testing.T, body []byte)
 	}{
 		{
 			name: "successful merge",
@@ -81,8 +82,21 @@ func TestComposeHandler_Merge(t *testing.T) {
 					{Name: "module1", Compose: "invalid: yaml: content:"},
 				},
 			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedError:  "MERGE_FAILED",
+			expectedStatus:  http.StatusBadRequest,
+			expectedError:   "MERGE_FAILED",
+			expectedDetails: "failed to parse compose for module module1",
+		},
+		{
+			name: "duplicate namespaced service",
+			requestBody: services.MergeRequest{
+				Modules: []services.Module{
+					{Name: "app", Compose: "services:\n  web:\n    image: nginx:latest"},
+					{Name: "app", Compose: "services:\n  web:\n    image: httpd:latest"},
+				},
+			},
+			expectedStatus:  http.StatusBadRequest,
+			expectedError:   "MERGE_FAILED",
+			expectedDetails: "duplicate service key 'app__web' produced by modules 'app' and 'app'",
 		},
 	}
 
@@ -117,6 +131,9 @@ func TestComposeHandler_Merge(t *testing.T) {
 				if errorCode, ok := response["error"].(string); !ok || errorCode != tt.expectedError {
 					t.Errorf("Merge() error = %v, want %v", response["error"], tt.expectedError)
 				}
+				if details, _ := response["details"].(string); !strings.Contains(details, tt.expectedDetails) {
+					t.Errorf("Merge() details = %q, want it to contain %q", details, tt.expectedDetails)
+				}
 			}
 
 			if tt.checkResponse != nil {
@@ -130,11 +147,12 @@ func TestComposeHandler_Lint(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	tests := []struct {
-		name           string
-		requestBody    interface{}
-		expectedStatus int
-		expectedError  string
-		checkResponse  func(t *testing.T, body []byte)
+		name            string
+		requestBody     interface{}
+		expectedStatus  int
+		expectedError   string
+		expectedDetails string
+		checkResponse   func(t *testing.T, body []byte)
 	}{
 		{
 			name: "successful lint - valid compose",
@@ -199,8 +217,9 @@ func TestComposeHandler_Lint(t *testing.T) {
 			requestBody: services.LintRequest{
 				Compose: "invalid: yaml: content:",
 			},
-			expectedStatus: http.StatusInternalServerError,
-			expectedError:  "LINT_FAILED",
+			expectedStatus:  http.StatusBadRequest,
+			expectedError:   "LINT_FAILED",
+			expectedDetails: "failed to parse compose",
 		},
 	}
 
@@ -235,6 +254,9 @@ func TestComposeHandler_Lint(t *testing.T) {
 				if errorCode, ok := response["error"].(string); !ok || errorCode != tt.expectedError {
 					t.Errorf("Lint() error = %v, want %v", response["error"], tt.expectedError)
 				}
+				if details, _ := response["details"].(string); !strings.Contains(details, tt.expectedDetails) {
+					t.Errorf("Lint() details = %q, want it to contain %q", details, tt.expectedDetails)
+				}
 			}
 
 			if tt.checkResponse != nil {
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/burndler/burndler/internal/models"
 	"github.com/burndler/burndler/internal/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -50,6 +51,8 @@ type ContainerListQuery struct {
 	Author      string `form:"author"`
 	ShowDeleted bool   `form:"show_deleted,default=false"`
 	Published   bool   `form:"published_only,default=false"`
+	Search      string `form:"search"`
+	Sort        string `form:"sort"`
 }
 
 // CreateVersionRequest represents the request to create a container version
@@ -59,6 +62,16 @@ type CreateVersionRequest struct {
 	Variables     map[string]interface{} `json:"variables"`
 	ResourcePaths []string               `json:"resource_paths"`
 	Dependencies  map[string]string      `json:"dependencies"`
+	Channel       string                 `json:"channel" binding:"omitempty,oneof=stable beta edge"`
+}
+
+// BulkCreateVersionsResponse reports the outcome of each item of a bulk
+// version import
+type BulkCreateVersionsResponse struct {
+	Error   string                       `json:"error,omitempty"`
+	Message string                       `json:"message,omitempty"`
+	Created int                          `json:"created"`
+	Results []services.BulkVersionResult `json:"results"`
 }
 
 // UpdateVersionRequest represents the request to update a container version
@@ -67,6 +80,7 @@ type UpdateVersionRequest struct {
 	Variables     map[string]interface{} `json:"variables"`
 	ResourcePaths []string               `json:"resource_paths"`
 	Dependencies  map[string]string      `json:"dependencies"`
+	Channel       string                 `json:"channel" binding:"omitempty,oneof=stable beta edge"`
 }
 
 // ValidateSemVer validates semantic versioning format
@@ -107,6 +121,8 @@ func (h *ContainerHandler) ListContainers(c *gin.Context) {
 		Active:       query.Active,
 		Author:       query.Author,
 		PublishedOnly: query.Published,
+		Search:       query.Search,
+		Sort:         query.Sort,
 	}
 
 	// Handle soft delete logic - GORM automatically handles soft delete filtering
@@ -115,6 +131,13 @@ func (h *ContainerHandler) ListContainers(c *gin.Context) {
 
 	result, err := h.containerService.ListContainers(filters)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid sort field") {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_SORT",
+				Message: err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "INTERNAL_ERROR",
 			Message: "Failed to list containers",
@@ -289,6 +312,44 @@ func (h *ContainerHandler) DeleteContainer(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// RestoreContainer handles POST /api/v1/containers/:id/restore
+func (h *ContainerHandler) RestoreContainer(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid container ID",
+		})
+		return
+	}
+
+	container, err := h.containerService.RestoreContainer(uint(id))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "MODULE_NOT_FOUND",
+				Message: "Container not found",
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "not deleted") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "MODULE_NOT_DELETED",
+				Message: "Container is not deleted",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to restore container",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, container)
+}
+
 // ListVersions handles GET /api/v1/containers/:id/versions
 func (h *ContainerHandler) ListVersions(c *gin.Context) {
 	idParam := c.Param("id")
@@ -302,8 +363,16 @@ func (h *ContainerHandler) ListVersions(c *gin.Context) {
 	}
 
 	publishedOnly := c.Query("published_only") == "true"
+	channel := c.Query("channel")
+	if channel != "" && !models.IsValidVersionChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_CHANNEL",
+			Message: "Channel must be stable, beta or edge",
+		})
+		return
+	}
 
-	versions, err := h.containerService.ListVersions(uint(id), publishedOnly)
+	versions, err := h.containerService.ListVersions(uint(id), publishedOnly, channel)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -366,6 +435,7 @@ func (h *ContainerHandler) CreateVersion(c *gin.Context) {
 		Variables:     req.Variables,
 		ResourcePaths: req.ResourcePaths,
 		Dependencies:  req.Dependencies,
+		Channel:       req.Channel,
 	}
 
 	version, err := h.containerService.CreateVersion(uint(id), serviceReq)
@@ -391,6 +461,13 @@ func (h *ContainerHandler) CreateVersion(c *gin.Context) {
 			})
 			return
 		}
+		if strings.Contains(err.Error(), "missing resource paths") {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "RESOURCE_PATHS_MISSING",
+				Message: err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "INTERNAL_ERROR",
 			Message: "Failed to create version",
@@ -401,6 +478,47 @@ func (h *ContainerHandler) CreateVersion(c *gin.Context) {
 	c.JSON(http.StatusCreated, version)
 }
 
+// DiffVersions handles GET /api/v1/containers/:id/versions/diff?from=...&to=...
+func (h *ContainerHandler) DiffVersions(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid container ID",
+		})
+		return
+	}
+
+	from := c.Query("from")
+	to := c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Both from and to versions are required",
+		})
+		return
+	}
+
+	diff, err := h.containerService.DiffVersions(uint(id), from, to)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "VERSION_NOT_FOUND",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to diff versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
 // GetVersion handles GET /api/v1/containers/:id/versions/:version
 func (h *ContainerHandler) GetVersion(c *gin.Context) {
 	idParam := c.Param("id")
@@ -434,6 +552,158 @@ func (h *ContainerHandler) GetVersion(c *gin.Context) {
 	c.JSON(http.StatusOK, version)
 }
 
+// BulkCreateVersions handles POST /api/v1/containers/:id/versions/bulk
+func (h *ContainerHandler) BulkCreateVersions(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid container ID",
+		})
+		return
+	}
+
+	var reqs []CreateVersionRequest
+	if err := c.ShouldBindJSON(&reqs); err != nil || len(reqs) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "VALIDATION_FAILED",
+			Message: "Request body must be a non-empty array of versions",
+		})
+		return
+	}
+
+	serviceReqs := make([]services.CreateVersionRequest, len(reqs))
+	for i, req := range reqs {
+		// Ensure valid versions start with 'v'; invalid ones are reported per item
+		if ValidateSemVer(req.Version) == nil && !strings.HasPrefix(req.Version, "v") {
+			req.Version = "v" + req.Version
+		}
+		serviceReqs[i] = services.CreateVersionRequest{
+			Version:       req.Version,
+			Compose:       req.Compose,
+			Variables:     req.Variables,
+			ResourcePaths: req.ResourcePaths,
+			Dependencies:  req.Dependencies,
+			Channel:       req.Channel,
+		}
+	}
+
+	results, err := h.containerService.CreateVersions(uint(id), serviceReqs)
+	if err != nil {
+		if results != nil {
+			c.JSON(http.StatusBadRequest, BulkCreateVersionsResponse{
+				Error:   "BULK_VALIDATION_FAILED",
+				Message: err.Error(),
+				Results: results,
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "MODULE_NOT_FOUND",
+				Message: "Container not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to create versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, BulkCreateVersionsResponse{
+		Created: len(results),
+		Results: results,
+	})
+}
+
+// GetLatestPublishedVersion handles GET /api/v1/containers/:id/versions/latest
+func (h *ContainerHandler) GetLatestPublishedVersion(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid container ID",
+		})
+		return
+	}
+
+	version, err := h.containerService.GetLatestPublishedVersion(uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "VERSION_NOT_FOUND",
+				Message: "No published version found",
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "MODULE_NOT_FOUND",
+				Message: "Container not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to resolve latest version",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// GetLatestChannelVersion handles GET /api/v1/containers/:id/channels/:channel/latest
+func (h *ContainerHandler) GetLatestChannelVersion(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid container ID",
+		})
+		return
+	}
+
+	channel := c.Param("channel")
+	if !models.IsValidVersionChannel(channel) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_CHANNEL",
+			Message: "Channel must be stable, beta or edge",
+		})
+		return
+	}
+
+	version, err := h.containerService.GetLatestVersionInChannel(uint(id), channel)
+	if err != nil {
+		if strings.Contains(err.Error(), "no published version") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "VERSION_NOT_FOUND",
+				Message: err.Error(),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "MODULE_NOT_FOUND",
+				Message: "Container not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to resolve latest version",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
 // UpdateVersion handles PUT /api/v1/containers/:id/versions/:version
 func (h *ContainerHandler) UpdateVersion(c *gin.Context) {
 	idParam := c.Param("id")
@@ -463,6 +733,7 @@ func (h *ContainerHandler) UpdateVersion(c *gin.Context) {
 		Variables:     req.Variables,
 		ResourcePaths: req.ResourcePaths,
 		Dependencies:  req.Dependencies,
+		Channel:       req.Channel,
 	}
 
 	version, err := h.containerService.UpdateVersion(uint(id), versionParam, serviceReq)
@@ -528,6 +799,13 @@ func (h *ContainerHandler) PublishVersion(c *gin.Context) {
 			})
 			return
 		}
+		if strings.Contains(err.Error(), "must be greater than the latest published version") {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "VERSION_OUT_OF_ORDER",
+				Message: err.Error(),
+			})
+			return
+		}
 		if strings.Contains(err.Error(), "validation failed") {
 			c.JSON(http.StatusBadRequest, ErrorResponse{
 				Error:   "COMPOSE_VALIDATION_FAILED",
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/burndler/burndler/internal/models"
+	"github.com/burndler/burndler/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupContainerHandlerTest(t *testing.T) (*gorm.DB, *ContainerHandler) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = db.AutoMigrate(&models.Container{}, &models.ContainerVersion{})
+	assert.NoError(t, err)
+
+	containerService := services.NewContainerService(db, &mockStorage{}, services.NewLinter())
+	return db, NewContainerHandler(containerService, db)
+}
+
+func TestContainerHandler_ListContainers_Sort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupContainerHandlerTest(t)
+
+	for _, container := range []*models.Container{
+		{Name: "beta", Author: "carol"},
+		{Name: "alpha", Author: "bob"},
+		{Name: "gamma", Author: "alice"},
+	} {
+		assert.NoError(t, db.Create(container).Error)
+	}
+
+	router := gin.New()
+	router.GET("/containers", handler.ListContainers)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedNames  []string
+		expectedError  string
+	}{
+		{name: "default is name ascending", query: "", expectedStatus: http.StatusOK, expectedNames: []string{"alpha", "beta", "gamma"}},
+		{name: "name descending", query: "?sort=-name", expectedStatus: http.StatusOK, expectedNames: []string{"gamma", "beta", "alpha"}},
+		{name: "author ascending", query: "?sort=author", expectedStatus: http.StatusOK, expectedNames: []string{"gamma", "alpha", "beta"}},
+		{name: "unknown field", query: "?sort=password", expectedStatus: http.StatusBadRequest, expectedError: "INVALID_SORT"},
+		{name: "injection attempt", query: "?sort=name%3BDROP+TABLE+containers", expectedStatus: http.StatusBadRequest, expectedError: "INVALID_SORT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/containers"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				var response ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedError, response.Error)
+				return
+			}
+
+			var response services.PaginatedResponse[models.Container]
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			var names []string
+			for _, container := range response.Data {
+				names = append(names, container.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestContainerHandler_DiffVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupContainerHandlerTest(t)
+
+	container := &models.Container{Name: "web", Author: "alice"}
+	assert.NoError(t, db.Create(container).Error)
+	for _, version := range []*models.ContainerVersion{
+		{ContainerID: container.ID, Version: "1.0.0", ComposeContent: "services:\n  web:\n    image: nginx:1.25\n  cache:\n    image: redis:7\n"},
+		{ContainerID: container.ID, Version: "1.1.0", ComposeContent: "services:\n  web:\n    image: nginx:1.27\n  db:\n    image: postgres:16\n"},
+	} {
+		assert.NoError(t, db.Create(version).Error)
+	}
+
+	router := gin.New()
+	router.GET("/containers/:id/versions/diff", handler.DiffVersions)
+
+	t.Run("reports added, removed and changed services", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/containers/%d/versions/diff?from=1.0.0&to=1.1.0", container.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var diff services.VersionDiff
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+		assert.Equal(t, []string{"db"}, diff.AddedServices)
+		assert.Equal(t, []string{"cache"}, diff.RemovedServices)
+		assert.Equal(t, []services.ServiceChange{{Service: "web", Fields: []string{"image"}}}, diff.ChangedServices)
+		assert.Contains(t, diff.UnifiedDiff, "-    image: nginx:1.25")
+		assert.Contains(t, diff.UnifiedDiff, "+    image: nginx:1.27")
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/containers/%d/versions/diff?from=1.0.0&to=9.9.9", container.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var response ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "VERSION_NOT_FOUND", response.Error)
+	})
+
+	t.Run("missing query parameter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/containers/%d/versions/diff?from=1.0.0", container.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestContainerHandler_BulkCreateVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupContainerHandlerTest(t)
+
+	container := &models.Container{Name: "web", Author: "alice"}
+	assert.NoError(t, db.Create(container).Error)
+
+	router := gin.New()
+	router.POST("/containers/:id/versions/bulk", handler.BulkCreateVersions)
+
+	compose := "services:\n  web:\n    image: nginx:1.25.3\n"
+	bulkCreate := func(items []CreateVersionRequest) (*httptest.ResponseRecorder, BulkCreateVersionsResponse) {
+		body, err := json.Marshal(items)
+		assert.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/containers/%d/versions/bulk", container.ID), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response BulkCreateVersionsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+	countVersions := func() int64 {
+		var count int64
+		assert.NoError(t, db.Model(&models.ContainerVersion{}).Where("container_id = ?", container.ID).Count(&count).Error)
+		return count
+	}
+
+	t.Run("invalid item rolls back the whole batch", func(t *testing.T) {
+		w, response := bulkCreate([]CreateVersionRequest{
+			{Version: "1.0.0", Compose: compose},
+			{Version: "not-a-version", Compose: compose},
+			{Version: "v1.1.0", Compose: compose},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "BULK_VALIDATION_FAILED", response.Error)
+		assert.Zero(t, response.Created)
+		assert.Len(t, response.Results, 3)
+		assert.Equal(t, services.BulkVersionSkipped, response.Results[0].Status)
+		assert.Equal(t, services.BulkVersionInvalid, response.Results[1].Status)
+		assert.Equal(t, "version 'not-a-version' is not a semantic version", response.Results[1].Error)
+		assert.Equal(t, services.BulkVersionSkipped, response.Results[2].Status)
+		assert.Zero(t, countVersions())
+	})
+
+	t.Run("all valid items are created", func(t *testing.T) {
+		w, response := bulkCreate([]CreateVersionRequest{
+			{Version: "1.0.0", Compose: compose},
+			{Version: "v1.1.0", Compose: compose, Channel: models.VersionChannelBeta},
+		})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 2, response.Created)
+		assert.Equal(t, "v1.0.0", response.Results[0].Version)
+		for _, result := range response.Results {
+			assert.Equal(t, services.BulkVersionCreated, result.Status)
+			assert.NotNil(t, result.ContainerVersion)
+		}
+		assert.Equal(t, models.VersionChannelBeta, response.Results[1].ContainerVersion.Channel)
+		assert.Equal(t, int64(2), countVersions())
+	})
+
+	t.Run("existing version is reported per item", func(t *testing.T) {
+		w, response := bulkCreate([]CreateVersionRequest{
+			{Version: "v1.2.0", Compose: compose},
+			{Version: "v1.0.0", Compose: compose},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, services.BulkVersionInvalid, response.Results[1].Status)
+		assert.Equal(t, "version 'v1.0.0' already exists for container 'web'", response.Results[1].Error)
+		assert.Equal(t, int64(2), countVersions())
+	})
+}
@@ -14,8 +14,9 @@ import (
 
 // PackageHandler handles package-related endpoints
 type PackageHandler struct {
-	packager *services.Packager
-	db       *gorm.DB
+	packager     *services.Packager
+	db           *gorm.DB
+	buildService *services.BuildService
 }
 
 // NewPackageHandler creates a new package handler
@@ -26,23 +27,42 @@ func NewPackageHandler(packager *services.Packager, db *gorm.DB) *PackageHandler
 	}
 }
 
+// SetBuildService configures the build service whose worker queue runs
+// package builds and reports their queue positions
+func (h *PackageHandler) SetBuildService(buildService *services.BuildService) {
+	h.buildService = buildService
+}
+
 // Create handles package creation requests (Developer only)
 func (h *PackageHandler) Create(c *gin.Context) {
 	var req services.PackageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "INVALID_REQUEST",
-			"message": "Invalid package request",
-			"details": err.Error(),
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid package request",
 		})
 		return
 	}
 
 	// Validate input
 	if req.Name == "" || req.Compose == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "MISSING_FIELDS",
-			"message": "Name and compose content are required",
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "MISSING_FIELDS",
+			Message: "Name and compose content are required",
+		})
+		return
+	}
+	if req.Format != "" && req.Format != services.PackageFormatTarGz && req.Format != services.PackageFormatZip {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_FORMAT",
+			Message: "Package format must be targz or zip",
+		})
+		return
+	}
+	if err := services.ValidateDownloadAssets(req.DownloadAssets); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_DOWNLOAD_ASSET",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -61,16 +81,22 @@ func (h *PackageHandler) Create(c *gin.Context) {
 	}
 
 	if err := h.db.Create(build).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "DB_ERROR",
-			"message": "Failed to create build record",
-			"details": err.Error(),
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DB_ERROR",
+			Message: "Failed to create build record",
 		})
 		return
 	}
 
-	// Start async package creation
-	go h.processPackage(build, &req)
+	// Start async package creation, sharing the build workers when configured
+	req.BuildID = build.ID.String()
+	if h.buildService != nil {
+		h.buildService.RunBuildAsync(build.ID, func(ctx context.Context) error {
+			return h.processPackage(ctx, build, &req)
+		})
+	} else {
+		go h.processPackage(context.Background(), build, &req)
+	}
 
 	c.JSON(http.StatusAccepted, gin.H{
 		"build_id": build.ID.String(),
@@ -83,9 +109,9 @@ func (h *PackageHandler) Status(c *gin.Context) {
 	buildIDStr := c.Param("id")
 	buildID, err := uuid.Parse(buildIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "INVALID_BUILD_ID",
-			"message": "Invalid build ID format",
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_BUILD_ID",
+			Message: "Invalid build ID format",
 		})
 		return
 	}
@@ -93,55 +119,67 @@ func (h *PackageHandler) Status(c *gin.Context) {
 	var build models.Build
 	if err := h.db.First(&build, "id = ?", buildID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "BUILD_NOT_FOUND",
-				"message": "Build not found",
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "BUILD_NOT_FOUND",
+				Message: "Build not found",
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "DB_ERROR",
-			"message": "Failed to fetch build",
-			"details": err.Error(),
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "DB_ERROR",
+			Message: "Failed to fetch build",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"build_id":     build.ID.String(),
-		"status":       build.Status,
-		"progress":     build.Progress,
-		"download_url": build.DownloadURL,
-		"error":        build.Error,
-		"created_at":   build.CreatedAt,
-		"completed_at": build.CompletedAt,
-	})
+	response := gin.H{
+		"build_id":         build.ID.String(),
+		"status":           build.Status,
+		"progress":         build.Progress,
+		"download_url":     build.DownloadURL,
+		"package_checksum": build.PackageChecksum,
+		"package_size":     build.PackageSize,
+		"error":            build.Error,
+		"created_at":       build.CreatedAt,
+		"completed_at":     build.CompletedAt,
+	}
+	if build.Status == "queued" && h.buildService != nil {
+		if position := h.buildService.QueuePosition(build.ID); position > 0 {
+			response["queue_position"] = position
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
-// processPackage handles async package creation
-func (h *PackageHandler) processPackage(build *models.Build, req *services.PackageRequest) {
+// processPackage handles async package creation and returns the packaging
+// error, if any, after recording it on the build
+func (h *PackageHandler) processPackage(ctx context.Context, build *models.Build, req *services.PackageRequest) error {
 	// Update status to building
 	build.Status = "building"
 	build.Progress = 10
 	h.db.Save(build)
 
 	// Create package
-	ctx := context.Background()
-	url, err := h.packager.CreatePackage(ctx, req)
+	pkg, err := h.packager.BuildPackage(ctx, req)
 
 	if err != nil {
 		// Update build with error
 		build.Status = "failed"
 		build.Error = err.Error()
 		h.db.Save(build)
-		return
+		return err
 	}
 
 	// Update build with success
 	now := gorm.DeletedAt{}
 	build.Status = "completed"
 	build.Progress = 100
-	build.DownloadURL = url
+	build.DownloadURL = pkg.URL
+	build.PackageChecksum = pkg.Checksum
+	build.PackageKey = pkg.Key
+	build.PackageSize = pkg.Size
 	build.CompletedAt = &now.Time
 	h.db.Save(build)
+	return nil
 }
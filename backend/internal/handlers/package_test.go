@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -156,6 +157,30 @@ func TestPackageHandler_Create(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "MISSING_FIELDS",
 		},
+		{
+			name: "unsupported format",
+			requestBody: services.PackageRequest{
+				Name:    "test-package",
+				Compose: "version: '3'",
+				Format:  "rar",
+			},
+			userID:         "1",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_FORMAT",
+		},
+		{
+			name: "download asset outside the package",
+			requestBody: services.PackageRequest{
+				Name:    "test-package",
+				Compose: "version: '3'",
+				DownloadAssets: []services.DownloadAsset{
+					{Path: "../../etc/cron.d/x", URL: "https://assets.example.com/x"},
+				},
+			},
+			userID:         "1",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_DOWNLOAD_ASSET",
+		},
 	}
 
 	for _, tt := range tests {
@@ -384,7 +409,7 @@ func TestPackageHandler_ProcessPackage(t *testing.T) {
 				Name:    "test-package",
 				Compose: "version: '3'",
 			}
-			handler.processPackage(build, req)
+			handler.processPackage(context.Background(), build, req)
 
 			// Wait for async processing
 			time.Sleep(100 * time.Millisecond)
@@ -402,3 +427,199 @@ func TestPackageHandler_ProcessPackage(t *testing.T) {
 		})
 	}
 }
+
+// Test /build/package builds wait for a free build worker
+func TestPackageHandler_Create_UsesBuildWorkers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupTestDB(t)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Background builds must share the single in-memory database connection
+	sqlDB.SetMaxOpenConns(1)
+
+	packager := services.NewPackager(&mockStorage{})
+	buildService := services.NewBuildService(db, services.NewMerger(), services.NewLinter(), packager)
+	buildService.SetWorkerCount(1)
+	handler := NewPackageHandler(packager, db)
+	handler.SetBuildService(buildService)
+
+	// Occupy the only worker
+	release := make(chan struct{})
+	buildService.RunBuildAsync(uuid.New(), func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	router := gin.New()
+	router.POST("/package", func(c *gin.Context) {
+		c.Set("user_id", "1")
+		handler.Create(c)
+	})
+	router.GET("/package/:id", handler.Status)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/package", strings.NewReader(`{"name":"queued-package","compose":"version: '3'"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Create() status = %v, want %v", w.Code, http.StatusAccepted)
+	}
+	var created map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal("Failed to parse response:", err)
+	}
+	buildID := created["build_id"].(string)
+
+	status := func() map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/package/"+buildID, nil)
+		router.ServeHTTP(w, req)
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal("Failed to parse response:", err)
+		}
+		return response
+	}
+
+	if response := status(); response["status"] != "queued" || response["queue_position"] != float64(1) {
+		t.Errorf("Status() = %v, want queued at position 1", response)
+	}
+
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for status()["status"] != "completed" {
+		if time.Now().After(deadline) {
+			t.Fatalf("package build did not complete once the worker was free: %v", status())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// recordingDeleteStorage records the keys deleted from storage
+type recordingDeleteStorage struct {
+	mockStorage
+	deleted []string
+}
+
+func (m *recordingDeleteStorage) Delete(ctx context.Context, key string) error {
+	m.deleted = append(m.deleted, key)
+	return nil
+}
+
+// Test packages built through /build/package are removed by build cleanup
+func TestPackageHandler_ProcessPackage_Cleanup(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&models.User{}, &models.Service{}, &models.BuildLog{}); err != nil {
+		t.Fatal("Failed to migrate test database:", err)
+	}
+	storage := &recordingDeleteStorage{}
+	packager := services.NewPackager(storage)
+	handler := NewPackageHandler(packager, db)
+
+	build := &models.Build{Name: "test-package", Status: "queued", UserID: 1}
+	if err := db.Create(build).Error; err != nil {
+		t.Fatal("Failed to create build:", err)
+	}
+	handler.processPackage(context.Background(), build, &services.PackageRequest{
+		Name:    "test-package",
+		Compose: "version: '3'",
+		BuildID: build.ID.String(),
+	})
+
+	var packaged models.Build
+	if err := db.First(&packaged, "id = ?", build.ID).Error; err != nil {
+		t.Fatal("Failed to fetch build:", err)
+	}
+	if packaged.Status != "completed" || packaged.PackageKey == "" {
+		t.Fatalf("processPackage() status = %v, package key = %q, want completed with a key", packaged.Status, packaged.PackageKey)
+	}
+
+	if err := db.Model(&packaged).Update("created_at", time.Now().AddDate(0, 0, -8)).Error; err != nil {
+		t.Fatal("Failed to age build:", err)
+	}
+	buildService := services.NewBuildService(db, services.NewMerger(), services.NewLinter(), packager)
+	buildService.SetBuildRetentionDays(7)
+	removed, err := buildService.CleanupExpiredBuilds(context.Background())
+	if err != nil {
+		t.Fatal("CleanupExpiredBuilds() failed:", err)
+	}
+	if removed != 1 {
+		t.Errorf("CleanupExpiredBuilds() removed = %d, want 1", removed)
+	}
+	if len(storage.deleted) != 1 || storage.deleted[0] != packaged.PackageKey {
+		t.Errorf("CleanupExpiredBuilds() deleted = %v, want [%s]", storage.deleted, packaged.PackageKey)
+	}
+}
+
+// Test compose and package errors use the same ErrorResponse shape as the other handlers
+func TestErrorResponseShape(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := setupTestDB(t)
+	packageHandler := NewPackageHandler(services.NewPackager(&mockStorage{}), db)
+	composeHandler := NewComposeHandler(services.NewMerger(), services.NewLinter())
+
+	router := gin.New()
+	router.POST("/merge", composeHandler.Merge)
+	router.POST("/lint", composeHandler.Lint)
+	router.POST("/package", func(c *gin.Context) {
+		c.Set("user_id", "1")
+		packageHandler.Create(c)
+	})
+	router.GET("/package/:id", packageHandler.Status)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{"merge with invalid body", http.MethodPost, "/merge", "invalid-json", http.StatusBadRequest, "INVALID_REQUEST"},
+		{"merge with invalid compose", http.MethodPost, "/merge", `{"modules":[{"name":"m","compose":"invalid: yaml: content:"}]}`, http.StatusBadRequest, "MERGE_FAILED"},
+		{"lint without compose", http.MethodPost, "/lint", `{"compose":""}`, http.StatusBadRequest, "NO_COMPOSE"},
+		{"package without fields", http.MethodPost, "/package", `{"name":""}`, http.StatusBadRequest, "MISSING_FIELDS"},
+		{"package status with invalid id", http.MethodGet, "/package/not-a-uuid", "", http.StatusBadRequest, "INVALID_BUILD_ID"},
+		{"package status not found", http.MethodGet, "/package/" + uuid.New().String(), "", http.StatusNotFound, "BUILD_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.expectedStatus)
+			}
+
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatal("Failed to parse error response:", err)
+			}
+			if response["error"] != tt.expectedError {
+				t.Errorf("error = %v, want %v", response["error"], tt.expectedError)
+			}
+			if message, ok := response["message"].(string); !ok || message == "" {
+				t.Errorf("message = %v, want a non-empty string", response["message"])
+			}
+			for field, value := range response {
+				if field == "details" {
+					if _, ok := value.(string); !ok {
+						t.Errorf("details = %v, want a string", value)
+					}
+				} else if field != "error" && field != "message" {
+					t.Errorf("response = %v, want only error, message and details fields", response)
+				}
+			}
+		})
+	}
+}
@@ -1,24 +1,32 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/burndler/burndler/internal/middleware"
+	"github.com/burndler/burndler/internal/models"
 	"github.com/burndler/burndler/internal/services"
 	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 )
 
 // ServiceHandler handles service-related HTTP endpoints
 type ServiceHandler struct {
 	serviceService *services.ServiceService
+	buildService   *services.BuildService
 	db             *gorm.DB
 }
 
 // NewServiceHandler creates a new service handler
-func NewServiceHandler(serviceService *services.ServiceService, db *gorm.DB) *ServiceHandler {
+func NewServiceHandler(serviceService *services.ServiceService, buildService *services.BuildService, db *gorm.DB) *ServiceHandler {
 	return &ServiceHandler{
 		serviceService: serviceService,
+		buildService:   buildService,
 		db:             db,
 	}
 }
@@ -31,18 +39,20 @@ type CreateServiceRequest struct {
 
 // UpdateServiceRequest represents the request to update a service
 type UpdateServiceRequest struct {
-	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
-	Description *string `json:"description" binding:"omitempty,max=500"`
-	Active      *bool   `json:"active"`
+	Name               *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description        *string `json:"description" binding:"omitempty,max=500"`
+	Active             *bool   `json:"active"`
+	BuildRetentionDays *int    `json:"build_retention_days" binding:"omitempty,min=0"`
 }
 
 // ServiceListQuery represents query parameters for listing services
 type ServiceListQuery struct {
-	Page     int    `form:"page,default=1" binding:"min=1"`
-	PageSize int    `form:"page_size,default=10" binding:"min=1"`
-	Active   *bool  `form:"active"`
-	UserID   uint   `form:"user_id"`
-	Name     string `form:"name"`
+	Page            int    `form:"page,default=1" binding:"min=1"`
+	PageSize        int    `form:"page_size,default=10" binding:"min=1"`
+	Active          *bool  `form:"active"`
+	IncludeArchived bool   `form:"include_archived"`
+	UserID          uint   `form:"user_id"`
+	Name            string `form:"name"`
 }
 
 // AddContainerToServiceRequest represents the request to add a container to service
@@ -58,9 +68,15 @@ type AddContainerToServiceRequest struct {
 type UpdateServiceContainerRequest struct {
 	Order        *int                   `json:"order"`
 	Enabled      *bool                  `json:"enabled"`
+	Pinned       *bool                  `json:"pinned"`
 	OverrideVars map[string]interface{} `json:"override_vars"`
 }
 
+// BuildServiceRequest represents the optional body of a service build request
+type BuildServiceRequest struct {
+	CallbackURL string `json:"callback_url" binding:"omitempty,max=2048"`
+}
+
 // CreateService handles POST /api/v1/services
 func (h *ServiceHandler) CreateService(c *gin.Context) {
 	var req CreateServiceRequest
@@ -232,11 +248,12 @@ func (h *ServiceHandler) ListServices(c *gin.Context) {
 
 	// Convert to service filters
 	filters := services.ServiceFilters{
-		Active:   query.Active,
-		UserID:   uint(userID), // Only show user's own services
-		Name:     query.Name,
-		Page:     query.Page,
-		PageSize: query.PageSize,
+		Active:          query.Active,
+		IncludeArchived: query.IncludeArchived,
+		UserID:          uint(userID), // Only show user's own services
+		Name:            query.Name,
+		Page:            query.Page,
+		PageSize:        query.PageSize,
 	}
 
 	// Admin users can see all services if user_id is specified
@@ -279,9 +296,10 @@ func (h *ServiceHandler) UpdateService(c *gin.Context) {
 
 	// Convert to service request
 	serviceReq := services.UpdateServiceRequest{
-		Name:        req.Name,
-		Description: req.Description,
-		Active:      req.Active,
+		Name:               req.Name,
+		Description:        req.Description,
+		Active:             req.Active,
+		BuildRetentionDays: req.BuildRetentionDays,
 	}
 
 	service, err := h.serviceService.UpdateService(uint(id), serviceReq)
@@ -334,6 +352,245 @@ func (h *ServiceHandler) DeleteService(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ExportAllServices handles GET /api/v1/services/export-all. The export is
+// JSON unless YAML is requested with ?format=yaml or Accept: application/x-yaml.
+func (h *ServiceHandler) ExportAllServices(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.serviceService.ExportServices(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to export services",
+		})
+		return
+	}
+
+	if wantsYAML(c) {
+		data, err := marshalExportYAML(export)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to export services",
+			})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="services-export.yaml"`)
+		c.Data(http.StatusOK, "application/x-yaml; charset=utf-8", data)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="services-export.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// wantsYAML reports whether the client asked for a YAML response
+func wantsYAML(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return format == "yaml" || format == "yml"
+	}
+	accept := c.GetHeader("Accept")
+	return strings.Contains(accept, "application/x-yaml") ||
+		strings.Contains(accept, "application/yaml")
+}
+
+// marshalExportYAML encodes an export as YAML with the same keys as its JSON
+// form, so the document can be converted back to JSON for import
+func marshalExportYAML(export *services.ServiceExport) ([]byte, error) {
+	data, err := json.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
+
+// ImportServices handles POST /api/v1/services/import. With
+// validate_only=true the document is checked without creating anything and
+// a per-service report is returned instead.
+func (h *ServiceHandler) ImportServices(c *gin.Context) {
+	var export services.ServiceExport
+	if err := c.ShouldBindJSON(&export); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_REQUEST",
+			Message: "Invalid export document",
+		})
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("validate_only") == "true" {
+		report, err := h.serviceService.ValidateImport(userID, &export)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "unsupported export version") {
+				c.JSON(http.StatusBadRequest, ErrorResponse{
+					Error:   "INVALID_IMPORT",
+					Message: err.Error(),
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to validate import",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"validate_only": true,
+			"report":        report,
+		})
+		return
+	}
+
+	imported, err := h.serviceService.ImportServices(userID, &export)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "already exists"):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "SERVICE_EXISTS",
+				Message: err.Error(),
+			})
+		case strings.Contains(err.Error(), "not found"),
+			strings.HasPrefix(err.Error(), "unsupported export version"),
+			err.Error() == "name is required":
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_IMPORT",
+				Message: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to import services",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"imported": len(imported),
+		"services": imported,
+	})
+}
+
+// currentUserID reads the authenticated user ID, responding with an error
+// when it is missing or malformed
+func currentUserID(c *gin.Context) (uint, bool) {
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "UNAUTHORIZED",
+			Message: "User not authenticated",
+		})
+		return 0, false
+	}
+
+	userID, err := strconv.ParseUint(fmt.Sprint(userIDStr), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Invalid user ID format",
+		})
+		return 0, false
+	}
+
+	return uint(userID), true
+}
+
+// RequireServiceAccess rejects requests for a service the caller does not
+// own with 404. Admins may access every service. Routes without an :id
+// parameter are not checked.
+func (h *ServiceHandler) RequireServiceAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			// Missing or invalid IDs are left to the handler
+			c.Next()
+			return
+		}
+
+		userID, ok := currentUserID(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		if err := h.serviceService.CheckServiceAccess(uint(id), userID, c.GetString("role") == "Admin"); err != nil {
+			if err.Error() == "service not found" {
+				c.JSON(http.StatusNotFound, ErrorResponse{
+					Error:   "SERVICE_NOT_FOUND",
+					Message: "Service not found",
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, ErrorResponse{
+					Error:   "INTERNAL_ERROR",
+					Message: "Failed to check service access",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ArchiveService handles POST /api/v1/services/:id/archive
+func (h *ServiceHandler) ArchiveService(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+// UnarchiveService handles POST /api/v1/services/:id/unarchive
+func (h *ServiceHandler) UnarchiveService(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+// setArchived archives or restores the service named by the :id parameter
+func (h *ServiceHandler) setArchived(c *gin.Context, archived bool) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid service ID",
+		})
+		return
+	}
+
+	var service *models.Service
+	if archived {
+		service, err = h.serviceService.ArchiveService(uint(id))
+	} else {
+		service, err = h.serviceService.UnarchiveService(uint(id))
+	}
+	if err != nil {
+		if err.Error() == "service not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "SERVICE_NOT_FOUND",
+				Message: "Service not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to update service",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, service)
+}
+
 // GetServiceContainers handles GET /api/v1/services/:id/containers
 func (h *ServiceHandler) GetServiceContainers(c *gin.Context) {
 	idParam := c.Param("id")
@@ -453,6 +710,7 @@ func (h *ServiceHandler) UpdateServiceContainer(c *gin.Context) {
 	serviceReq := services.UpdateServiceContainerRequest{
 		Order:        req.Order,
 		Enabled:      req.Enabled,
+		Pinned:       req.Pinned,
 		OverrideVars: req.OverrideVars,
 	}
 
@@ -516,6 +774,47 @@ func (h *ServiceHandler) RemoveContainerFromService(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ResetServiceContainerConfig handles POST /api/v1/services/:id/containers/:container_id/reset-config
+func (h *ServiceHandler) ResetServiceContainerConfig(c *gin.Context) {
+	idParam := c.Param("id")
+	serviceID, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid service ID",
+		})
+		return
+	}
+
+	containerIDParam := c.Param("container_id")
+	containerID, err := strconv.ParseUint(containerIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid container ID",
+		})
+		return
+	}
+
+	serviceContainer, err := h.serviceService.ResetServiceContainerConfig(uint(serviceID), uint(containerID))
+	if err != nil {
+		if err.Error() == "container not found in service" {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "CONTAINER_NOT_FOUND_IN_SERVICE",
+				Message: "Container not found in service",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to reset service container configuration",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, serviceContainer)
+}
+
 // ValidateService handles POST /api/v1/services/:id/validate
 func (h *ServiceHandler) ValidateService(c *gin.Context) {
 	idParam := c.Param("id")
@@ -547,8 +846,8 @@ func (h *ServiceHandler) ValidateService(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// BuildService handles POST /api/v1/services/:id/build
-func (h *ServiceHandler) BuildService(c *gin.Context) {
+// GetResolvedVariables handles GET /api/v1/services/:id/variables/resolved
+func (h *ServiceHandler) GetResolvedVariables(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
@@ -559,7 +858,7 @@ func (h *ServiceHandler) BuildService(c *gin.Context) {
 		return
 	}
 
-	canBuild, err := h.serviceService.CanBuild(uint(id))
+	containers, err := h.serviceService.ResolveVariables(uint(id))
 	if err != nil {
 		if err.Error() == "service not found" {
 			c.JSON(http.StatusNotFound, ErrorResponse{
@@ -570,22 +869,244 @@ func (h *ServiceHandler) BuildService(c *gin.Context) {
 		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "INTERNAL_ERROR",
-			Message: "Failed to check service build status",
+			Message: "Failed to resolve service variables",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service_id": uint(id),
+		"containers": containers,
+	})
+}
+
+// GetResolvedContainerVariables handles GET /api/v1/services/:id/containers/:container_id/variables/resolved
+func (h *ServiceHandler) GetResolvedContainerVariables(c *gin.Context) {
+	idParam := c.Param("id")
+	serviceID, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid service ID",
 		})
 		return
 	}
 
-	if !canBuild {
+	containerIDParam := c.Param("container_id")
+	containerID, err := strconv.ParseUint(containerIDParam, 10, 32)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "SERVICE_NOT_BUILDABLE",
-			Message: "Service is not ready for building",
+			Error:   "INVALID_ID",
+			Message: "Invalid container ID",
 		})
 		return
 	}
 
-	// TODO: Implement actual build logic
-	// For now, return a success response
+	resolved, err := h.serviceService.ResolveContainerVariables(uint(serviceID), uint(containerID))
+	if err != nil {
+		switch err.Error() {
+		case "service not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "SERVICE_NOT_FOUND",
+				Message: "Service not found",
+			})
+			return
+		case "container not found in service":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "CONTAINER_NOT_FOUND_IN_SERVICE",
+				Message: "Container not found in service",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to resolve container variables",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resolved)
+}
+
+// GetUpgradeCandidates handles GET /api/v1/services/:id/upgrades
+func (h *ServiceHandler) GetUpgradeCandidates(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid service ID",
+		})
+		return
+	}
+
+	candidates, err := h.serviceService.GetUpgradeCandidates(uint(id))
+	if err != nil {
+		if err.Error() == "service not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "SERVICE_NOT_FOUND",
+				Message: "Service not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Failed to get upgrade candidates",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service_id": uint(id),
+		"upgrades":   candidates,
+	})
+}
+
+// BuildService handles POST /api/v1/services/:id/build
+func (h *ServiceHandler) BuildService(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_ID",
+			Message: "Invalid service ID",
+		})
+		return
+	}
+
+	if c.Query("dry_run") == "true" {
+		h.dryRunBuild(c, uint(id))
+		return
+	}
+
+	userIDStr, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "UNAUTHORIZED",
+			Message: "User not authenticated",
+		})
+		return
+	}
+	userID, err := strconv.ParseUint(fmt.Sprint(userIDStr), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "INTERNAL_ERROR",
+			Message: "Invalid user ID format",
+		})
+		return
+	}
+
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if len(idempotencyKey) > 255 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "INVALID_IDEMPOTENCY_KEY",
+			Message: "Idempotency-Key must be at most 255 characters",
+		})
+		return
+	}
+
+	var req BuildServiceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_REQUEST",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+	if req.CallbackURL != "" {
+		if err := h.buildService.ValidateCallbackURL(c.Request.Context(), req.CallbackURL); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "INVALID_CALLBACK_URL",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	build, existing, err := h.buildService.QueueBuildWithOptions(uint(id), uint(userID), services.QueueBuildOptions{
+		IdempotencyKey: idempotencyKey,
+		CallbackURL:    req.CallbackURL,
+		RequestID:      c.GetString(middleware.RequestIDKey),
+	})
+	if err != nil {
+		switch err.Error() {
+		case "service not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "SERVICE_NOT_FOUND",
+				Message: "Service not found",
+			})
+		case "service is not ready for building":
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "SERVICE_NOT_BUILDABLE",
+				Message: "Service is not ready for building",
+			})
+		case "service is archived":
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "SERVICE_ARCHIVED",
+				Message: "Archived services cannot be built",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "INTERNAL_ERROR",
+				Message: "Failed to start service build",
+			})
+		}
+		return
+	}
+
+	if existing {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":  "Service build already initiated",
+			"build_id": build.ID.String(),
+			"status":   build.Status,
+		})
+		return
+	}
+
+	h.buildService.ExecuteBuildAsync(build.ID)
+
 	c.JSON(http.StatusAccepted, gin.H{
-		"message": "Service build initiated",
+		"message":  "Service build initiated",
+		"build_id": build.ID.String(),
+		"status":   build.Status,
+	})
+}
+
+// dryRunBuild handles POST /api/v1/services/:id/build?dry_run=true by
+// returning the merged compose without creating a build or package
+func (h *ServiceHandler) dryRunBuild(c *gin.Context, id uint) {
+	compose, err := h.buildService.DryRun(c.Request.Context(), id)
+	if err != nil {
+		switch err.Error() {
+		case "service not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "SERVICE_NOT_FOUND",
+				Message: "Service not found",
+			})
+		case "service is not ready for building":
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "SERVICE_NOT_BUILDABLE",
+				Message: "Service is not ready for building",
+			})
+		case "service is archived":
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "SERVICE_ARCHIVED",
+				Message: "Archived services cannot be built",
+			})
+		default:
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "DRY_RUN_FAILED",
+				Message: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"service_id": id,
+		"dry_run":    true,
+		"compose":    compose,
 	})
 }
@@ -8,11 +8,14 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/burndler/burndler/internal/models"
 	"github.com/burndler/burndler/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"gorm.io/datatypes"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -28,11 +31,19 @@ func setupServiceHandlerTest(t *testing.T) (*gorm.DB, *ServiceHandler) {
 		&models.ContainerVersion{},
 		&models.Service{},
 		&models.ServiceContainer{},
+		&models.Build{},
+		&models.BuildLog{},
 	)
 	assert.NoError(t, err)
 
+	// Background builds must see the same in-memory database
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
 	serviceService := services.NewServiceService(db, nil)
-	handler := NewServiceHandler(serviceService, db)
+	buildService := services.NewBuildService(db, services.NewMerger(), services.NewLinter(), services.NewPackager(&mockStorage{}))
+	handler := NewServiceHandler(serviceService, buildService, db)
 
 	return db, handler
 }
@@ -197,6 +208,65 @@ func TestServiceHandler_GetService(t *testing.T) {
 	}
 }
 
+func TestServiceHandler_RequireServiceAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	owner := createTestUser(t, db, "Developer")
+	other := &models.User{Email: "other@example.com", Name: "other", Role: "Developer"}
+	assert.NoError(t, db.Create(other).Error)
+	admin := createTestUser(t, db, "Admin")
+
+	testService := &models.Service{Name: "owned-service", UserID: owner.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+
+	newRouter := func(user *models.User) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", strconv.Itoa(int(user.ID)))
+			c.Set("email", user.Email)
+			c.Set("role", user.Role)
+			c.Next()
+		})
+		routes := router.Group("/services")
+		routes.Use(handler.RequireServiceAccess())
+		routes.GET("", handler.ListServices)
+		routes.GET("/:id", handler.GetService)
+		routes.PUT("/:id", handler.UpdateService)
+		routes.DELETE("/:id", handler.DeleteService)
+		return router
+	}
+
+	serviceURL := fmt.Sprintf("/services/%d", testService.ID)
+	serve := func(user *models.User, method, url, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		newRouter(user).ServeHTTP(w, req)
+		return w
+	}
+
+	// Another user can neither see nor change the service
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+		w := serve(other, method, serviceURL, `{"description":"taken over"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code, method)
+		assert.Contains(t, w.Body.String(), "SERVICE_NOT_FOUND")
+	}
+	var unchanged models.Service
+	assert.NoError(t, db.First(&unchanged, testService.ID).Error)
+	assert.Empty(t, unchanged.Description)
+
+	// Routes without a service ID are not checked
+	assert.Equal(t, http.StatusOK, serve(other, http.MethodGet, "/services", "").Code)
+
+	// The owner and admins have access
+	assert.Equal(t, http.StatusOK, serve(owner, http.MethodGet, serviceURL, "").Code)
+	assert.Equal(t, http.StatusOK, serve(admin, http.MethodGet, serviceURL, "").Code)
+	assert.Equal(t, http.StatusOK, serve(admin, http.MethodPut, serviceURL, `{"description":"by admin"}`).Code)
+	assert.Equal(t, http.StatusNoContent, serve(admin, http.MethodDelete, serviceURL, "").Code)
+}
+
 func TestServiceHandler_ListServices(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db, handler := setupServiceHandlerTest(t)
@@ -407,3 +477,628 @@ func TestServiceHandler_DeleteService(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceHandler_ResetServiceContainerConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	user := createTestUser(t, db, "Developer")
+
+	testService := &models.Service{Name: "test-service", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+
+	container := &models.Container{Name: "redis", Active: true}
+	assert.NoError(t, db.Create(container).Error)
+
+	version := &models.ContainerVersion{
+		ContainerID:    container.ID,
+		Version:        "v1.0.0",
+		ComposeContent: "services:\n  cache:\n    image: redis:7\n",
+		Variables:      datatypes.JSON(`{"REDIS_PORT":"6379"}`),
+	}
+	assert.NoError(t, db.Create(version).Error)
+
+	serviceContainer := &models.ServiceContainer{
+		ServiceID:          testService.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+		OverrideVars:       datatypes.JSON(`{"REDIS_PORT":"7000"}`),
+	}
+	assert.NoError(t, db.Create(serviceContainer).Error)
+
+	tests := []struct {
+		name           string
+		serviceID      string
+		containerID    string
+		expectedStatus int
+	}{
+		{
+			name:           "reset existing service container",
+			serviceID:      strconv.Itoa(int(testService.ID)),
+			containerID:    strconv.Itoa(int(container.ID)),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "container not in service",
+			serviceID:      strconv.Itoa(int(testService.ID)),
+			containerID:    "999",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid container ID",
+			serviceID:      strconv.Itoa(int(testService.ID)),
+			containerID:    "invalid",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/services/"+tt.serviceID+"/containers/"+tt.containerID+"/reset-config", nil)
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/services/:id/containers/:container_id/reset-config", handler.ResetServiceContainerConfig)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response models.ServiceContainer
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, map[string]interface{}{"REDIS_PORT": "6379"}, response.GetEffectiveVariables())
+			}
+		})
+	}
+}
+
+func TestServiceHandler_GetUpgradeCandidates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	user := createTestUser(t, db, "Developer")
+
+	testService := &models.Service{Name: "test-service", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+
+	for _, name := range []string{"redis", "nginx"} {
+		container := &models.Container{Name: name, Active: true}
+		assert.NoError(t, db.Create(container).Error)
+
+		v1 := &models.ContainerVersion{ContainerID: container.ID, Version: "v1.0.0", ComposeContent: "services: {}", Published: true}
+		assert.NoError(t, db.Create(v1).Error)
+		v2 := &models.ContainerVersion{ContainerID: container.ID, Version: "v1.1.0", ComposeContent: "services: {}", Published: true}
+		assert.NoError(t, db.Create(v2).Error)
+
+		// redis is outdated, nginx already uses the latest version
+		versionID := v1.ID
+		if name == "nginx" {
+			versionID = v2.ID
+		}
+		assert.NoError(t, db.Create(&models.ServiceContainer{
+			ServiceID:          testService.ID,
+			ContainerID:        container.ID,
+			ContainerVersionID: versionID,
+			Enabled:            true,
+		}).Error)
+	}
+
+	tests := []struct {
+		name           string
+		serviceID      string
+		expectedStatus int
+	}{
+		{name: "service with outdated container", serviceID: strconv.Itoa(int(testService.ID)), expectedStatus: http.StatusOK},
+		{name: "service not found", serviceID: "999", expectedStatus: http.StatusNotFound},
+		{name: "invalid service ID", serviceID: "invalid", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/services/"+tt.serviceID+"/upgrades", nil)
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.GET("/services/:id/upgrades", handler.GetUpgradeCandidates)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					Upgrades []services.UpgradeCandidate `json:"upgrades"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Len(t, response.Upgrades, 1)
+				assert.Equal(t, "redis", response.Upgrades[0].ContainerName)
+				assert.Equal(t, "v1.0.0", response.Upgrades[0].FromVersion)
+				assert.Equal(t, "v1.1.0", response.Upgrades[0].ToVersion)
+			}
+		})
+	}
+}
+
+func TestServiceHandler_BuildService_DryRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	user := createTestUser(t, db, "Developer")
+
+	testService := &models.Service{Name: "test-service", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+	emptyService := &models.Service{Name: "empty-service", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(emptyService).Error)
+
+	container := &models.Container{Name: "web", Active: true}
+	assert.NoError(t, db.Create(container).Error)
+	version := &models.ContainerVersion{
+		ContainerID:    container.ID,
+		Version:        "v1.0.0",
+		ComposeContent: "services:\n  app:\n    image: nginx:1.25.3\n",
+	}
+	assert.NoError(t, db.Create(version).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{
+		ServiceID:          testService.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+	}).Error)
+
+	tests := []struct {
+		name           string
+		serviceID      string
+		expectedStatus int
+	}{
+		{name: "dry run returns merged compose", serviceID: strconv.Itoa(int(testService.ID)), expectedStatus: http.StatusOK},
+		{name: "service without containers", serviceID: strconv.Itoa(int(emptyService.ID)), expectedStatus: http.StatusBadRequest},
+		{name: "service not found", serviceID: "999", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/services/"+tt.serviceID+"/build?dry_run=true", nil)
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.POST("/services/:id/build", handler.BuildService)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					DryRun  bool   `json:"dry_run"`
+					Compose string `json:"compose"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.True(t, response.DryRun)
+				assert.Contains(t, response.Compose, "web__app")
+			}
+		})
+	}
+}
+
+func TestServiceHandler_BuildService(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	user := createTestUser(t, db, "Developer")
+
+	testService := &models.Service{Name: "shop", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+	emptyService := &models.Service{Name: "empty-service", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(emptyService).Error)
+
+	container := &models.Container{Name: "web", Active: true}
+	assert.NoError(t, db.Create(container).Error)
+	version := &models.ContainerVersion{
+		ContainerID:    container.ID,
+		Version:        "v1.0.0",
+		ComposeContent: "services:\n  app:\n    image: nginx:1.25.3\n",
+	}
+	assert.NoError(t, db.Create(version).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{
+		ServiceID:          testService.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+	}).Error)
+
+	tests := []struct {
+		name           string
+		serviceID      string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "build queued", serviceID: strconv.Itoa(int(testService.ID)), expectedStatus: http.StatusAccepted},
+		{name: "service without containers", serviceID: strconv.Itoa(int(emptyService.ID)), expectedStatus: http.StatusBadRequest},
+		{name: "service not found", serviceID: "999", expectedStatus: http.StatusNotFound},
+		{
+			name:           "callback to link-local address",
+			serviceID:      strconv.Itoa(int(testService.ID)),
+			body:           `{"callback_url":"http://169.254.169.254/latest/meta-data"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "INVALID_CALLBACK_URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "/services/"+tt.serviceID+"/build", bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", strconv.Itoa(int(user.ID)))
+				c.Next()
+			})
+			router.POST("/services/:id/build", handler.BuildService)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+
+			if tt.expectedStatus == http.StatusAccepted {
+				var response struct {
+					BuildID string `json:"build_id"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+				var build models.Build
+				assert.NoError(t, db.First(&build, "id = ?", response.BuildID).Error)
+				assert.Equal(t, testService.ID, *build.ServiceID)
+				assert.Equal(t, user.ID, build.UserID)
+
+				// The background build runs to completion
+				assert.Eventually(t, func() bool {
+					var current models.Build
+					return db.First(&current, "id = ?", response.BuildID).Error == nil && current.IsComplete()
+				}, 5*time.Second, 10*time.Millisecond)
+			}
+		})
+	}
+}
+
+func TestServiceHandler_BuildService_IdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	user := createTestUser(t, db, "Developer")
+	testService := &models.Service{Name: "shop", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+	container := &models.Container{Name: "web", Active: true}
+	assert.NoError(t, db.Create(container).Error)
+	version := &models.ContainerVersion{ContainerID: container.ID, Version: "v1.0.0", ComposeContent: "services:\n  app:\n    image: nginx:1.25.3\n"}
+	assert.NoError(t, db.Create(version).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{ServiceID: testService.ID, ContainerID: container.ID, ContainerVersionID: version.ID, Enabled: true}).Error)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", strconv.Itoa(int(user.ID)))
+		c.Next()
+	})
+	router.POST("/services/:id/build", handler.BuildService)
+
+	trigger := func(key string) string {
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/services/%d/build", testService.ID), nil)
+		req.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var response struct {
+			BuildID string `json:"build_id"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.BuildID
+	}
+	countBuilds := func() int64 {
+		var count int64
+		assert.NoError(t, db.Model(&models.Build{}).Where("service_id = ?", testService.ID).Count(&count).Error)
+		return count
+	}
+	waitForBuilds := func() {
+		assert.Eventually(t, func() bool {
+			var pending int64
+			return db.Model(&models.Build{}).Where("status IN ?", []string{"queued", "building"}).Count(&pending).Error == nil && pending == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	first := trigger("click-1")
+	repeated := trigger("click-1")
+	assert.Equal(t, first, repeated, "the same key returns the existing build")
+	assert.Equal(t, int64(1), countBuilds())
+	waitForBuilds()
+
+	other := trigger("click-2")
+	assert.NotEqual(t, first, other, "a different key queues a new build")
+	assert.Equal(t, int64(2), countBuilds())
+	waitForBuilds()
+
+	// Keys expire after the TTL
+	assert.NoError(t, db.Model(&models.Build{}).Where("id = ?", first).
+		Update("created_at", time.Now().Add(-services.BuildIdempotencyTTL-time.Minute)).Error)
+	assert.NotEqual(t, first, trigger("click-1"))
+	assert.Equal(t, int64(3), countBuilds())
+	waitForBuilds()
+}
+
+func TestServiceHandler_ExportImportServices(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	owner := createTestUser(t, db, "Developer")
+	target := &models.User{Email: "target@example.com", Name: "target", Role: "Developer"}
+	assert.NoError(t, db.Create(target).Error)
+
+	web := &models.Container{Name: "web"}
+	cache := &models.Container{Name: "cache"}
+	assert.NoError(t, db.Create(web).Error)
+	assert.NoError(t, db.Create(cache).Error)
+	webVersion := &models.ContainerVersion{ContainerID: web.ID, Version: "v1.0.0", ComposeContent: "services:\n  app:\n    image: nginx:1.25.3"}
+	cacheVersion := &models.ContainerVersion{ContainerID: cache.ID, Version: "v7.2.0", ComposeContent: "services:\n  redis:\n    image: redis:7.2"}
+	assert.NoError(t, db.Create(webVersion).Error)
+	assert.NoError(t, db.Create(cacheVersion).Error)
+
+	retention := 14
+	shop := &models.Service{Name: "shop", Description: "Storefront", UserID: owner.ID, Active: true, BuildRetentionDays: &retention,
+		Variables: datatypes.JSON(`{"REGION":"eu"}`)}
+	legacy := &models.Service{Name: "legacy", UserID: owner.ID, Active: true}
+	assert.NoError(t, db.Create(shop).Error)
+	assert.NoError(t, db.Create(legacy).Error)
+	assert.NoError(t, db.Model(legacy).Updates(map[string]interface{}{"active": false, "archived": true}).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{ServiceID: shop.ID, ContainerID: web.ID, ContainerVersionID: webVersion.ID, Order: 1, Enabled: true,
+		OverrideVars: datatypes.JSON(`{"PORT":"8080"}`)}).Error)
+	cacheLink := &models.ServiceContainer{ServiceID: shop.ID, ContainerID: cache.ID, ContainerVersionID: cacheVersion.ID, Order: 2, Enabled: true, Pinned: true}
+	assert.NoError(t, db.Create(cacheLink).Error)
+	assert.NoError(t, db.Model(cacheLink).Update("enabled", false).Error)
+
+	routerFor := func(user *models.User) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", strconv.Itoa(int(user.ID)))
+			c.Set("role", user.Role)
+			c.Next()
+		})
+		router.GET("/services/export-all", handler.ExportAllServices)
+		router.POST("/services/import", handler.ImportServices)
+		return router
+	}
+
+	exportAll := func(user *models.User) services.ServiceExport {
+		w := httptest.NewRecorder()
+		routerFor(user).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/services/export-all", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var export services.ServiceExport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+		return export
+	}
+
+	exported := exportAll(owner)
+	assert.Equal(t, services.ServiceExportVersion, exported.Version)
+	assert.Len(t, exported.Services, 2)
+
+	body, err := json.Marshal(exported)
+	assert.NoError(t, err)
+
+	// Importing for the owner clashes with the existing services
+	w := httptest.NewRecorder()
+	routerFor(owner).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/import", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	routerFor(target).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/import", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		Imported int `json:"imported"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Imported)
+
+	roundTripped := exportAll(target)
+	assert.Equal(t, exported.Services, roundTripped.Services)
+
+	// A document referencing an unknown container version is rejected as a whole
+	exported.Services[0].Name = "renamed"
+	exported.Services[1].Name = "renamed-too"
+	exported.Services[1].Containers = []services.ExportedServiceContainer{{Container: "web", Version: "v9.9.9", Enabled: true}}
+	body, err = json.Marshal(exported)
+	assert.NoError(t, err)
+	w = httptest.NewRecorder()
+	routerFor(target).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/import", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var count int64
+	assert.NoError(t, db.Model(&models.Service{}).Where("user_id = ? AND name = ?", target.ID, "renamed").Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestServiceHandler_ExportAllServices_YAML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	owner := createTestUser(t, db, "Developer")
+	target := &models.User{Email: "target@example.com", Name: "target", Role: "Developer"}
+	assert.NoError(t, db.Create(target).Error)
+
+	web := &models.Container{Name: "web"}
+	assert.NoError(t, db.Create(web).Error)
+	webVersion := &models.ContainerVersion{ContainerID: web.ID, Version: "v1.0.0", ComposeContent: "services:\n  app:\n    image: nginx:1.25.3"}
+	assert.NoError(t, db.Create(webVersion).Error)
+	shop := &models.Service{Name: "shop", Description: "Storefront", UserID: owner.ID, Active: true,
+		Variables: datatypes.JSON(`{"REGION":"eu"}`)}
+	assert.NoError(t, db.Create(shop).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{ServiceID: shop.ID, ContainerID: web.ID, ContainerVersionID: webVersion.ID, Order: 1, Enabled: true,
+		OverrideVars: datatypes.JSON(`{"PORT":"8080"}`)}).Error)
+
+	routerFor := func(user *models.User) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", strconv.Itoa(int(user.ID)))
+			c.Set("role", user.Role)
+			c.Next()
+		})
+		router.GET("/services/export-all", handler.ExportAllServices)
+		router.POST("/services/import", handler.ImportServices)
+		return router
+	}
+	exportAll := func(user *models.User, query, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/services/export-all"+query, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		routerFor(user).ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	// JSON stays the default
+	w := exportAll(owner, "", "")
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	var exported services.ServiceExport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &exported))
+
+	byHeader := exportAll(owner, "", "application/x-yaml")
+	assert.Contains(t, byHeader.Header().Get("Content-Type"), "application/x-yaml")
+	assert.Contains(t, byHeader.Header().Get("Content-Disposition"), "services-export.yaml")
+
+	w = exportAll(owner, "?format=yaml", "")
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/x-yaml")
+	assert.Contains(t, w.Body.String(), "name: shop")
+
+	// The YAML document, re-encoded as JSON, imports like the JSON export
+	var doc interface{}
+	assert.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &doc))
+	body, err := json.Marshal(doc)
+	assert.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	routerFor(target).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/import", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var roundTripped services.ServiceExport
+	assert.NoError(t, json.Unmarshal(exportAll(target, "", "").Body.Bytes(), &roundTripped))
+	assert.Equal(t, exported.Services, roundTripped.Services)
+}
+
+func TestServiceHandler_ImportServices_ValidateOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, handler := setupServiceHandlerTest(t)
+
+	user := createTestUser(t, db, "Developer")
+	web := &models.Container{Name: "web"}
+	assert.NoError(t, db.Create(web).Error)
+	assert.NoError(t, db.Create(&models.ContainerVersion{ContainerID: web.ID, Version: "v1.0.0", ComposeContent: "services:\n  app:\n    image: nginx:1.25.3"}).Error)
+	assert.NoError(t, db.Create(&models.Service{Name: "legacy", UserID: user.ID, Active: true}).Error)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", strconv.Itoa(int(user.ID)))
+		c.Set("role", user.Role)
+		c.Next()
+	})
+	router.POST("/services/import", handler.ImportServices)
+
+	post := func(query string, export services.ServiceExport) *httptest.ResponseRecorder {
+		body, err := json.Marshal(export)
+		assert.NoError(t, err)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/services/import"+query, bytes.NewReader(body)))
+		return w
+	}
+	validate := func(export services.ServiceExport) services.ImportReport {
+		w := post("?validate_only=true", export)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Report services.ImportReport `json:"report"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Report
+	}
+	names := func(entries []services.ImportReportEntry) []string {
+		result := []string{}
+		for _, entry := range entries {
+			result = append(result, entry.Name)
+		}
+		return result
+	}
+	countRows := func() (services, links int64) {
+		assert.NoError(t, db.Model(&models.Service{}).Count(&services).Error)
+		assert.NoError(t, db.Model(&models.ServiceContainer{}).Count(&links).Error)
+		return services, links
+	}
+
+	// Every entry is checked, not just the ones before the first problem
+	mixed := services.ServiceExport{
+		Version: services.ServiceExportVersion,
+		Services: []services.ExportedService{
+			{Name: "legacy", Active: true},
+			{Name: "shop", Active: true, Containers: []services.ExportedServiceContainer{{Container: "web", Version: "v9.9.9", Enabled: true}}},
+			{Name: "blog", Active: true},
+			{Name: "", Active: true},
+			{Name: "blog", Active: true},
+		},
+	}
+	report := validate(mixed)
+	assert.False(t, report.Valid)
+	assert.Equal(t, []string{"blog"}, names(report.WouldImport))
+	assert.Equal(t, []services.ImportReportEntry{
+		{Index: 0, Name: "legacy", Reason: "service with name 'legacy' already exists"},
+	}, report.Skipped)
+	assert.Equal(t, []services.ImportReportEntry{
+		{Index: 1, Name: "shop", Reason: "version 'v9.9.9' of container 'web' not found"},
+		{Index: 3, Name: "", Reason: "name is required"},
+		{Index: 4, Name: "blog", Reason: "service 'blog' appears more than once in the document"},
+	}, report.Invalid)
+
+	servicesCount, linksCount := countRows()
+	assert.Equal(t, int64(1), servicesCount, "validate-only must not create services")
+	assert.Zero(t, linksCount, "validate-only must not create service containers")
+
+	// A real import of an invalid document fails as a whole
+	assert.Equal(t, http.StatusConflict, post("", mixed).Code)
+
+	valid := services.ServiceExport{
+		Version: services.ServiceExportVersion,
+		Services: []services.ExportedService{
+			{Name: "shop", Active: true, Containers: []services.ExportedServiceContainer{{Container: "web", Version: "v1.0.0", Enabled: true}}},
+			{Name: "blog", Active: true},
+		},
+	}
+	report = validate(valid)
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Skipped)
+	assert.Empty(t, report.Invalid)
+	servicesCount, linksCount = countRows()
+	assert.Equal(t, int64(1), servicesCount)
+	assert.Zero(t, linksCount)
+
+	w := post("", valid)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		Services []models.Service `json:"services"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	var imported []string
+	for _, service := range response.Services {
+		imported = append(imported, service.Name)
+	}
+	assert.Equal(t, names(report.WouldImport), imported, "the validate-only report matches the real import")
+	servicesCount, linksCount = countRows()
+	assert.Equal(t, int64(3), servicesCount)
+	assert.Equal(t, int64(1), linksCount)
+
+	// Once imported, the same document reports every service as skipped
+	report = validate(valid)
+	assert.False(t, report.Valid)
+	assert.Equal(t, []string{"shop", "blog"}, names(report.Skipped))
+}
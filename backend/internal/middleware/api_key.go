@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/burndler/burndler/internal/config"
+	"github.com/burndler/burndler/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader carries an API key as an alternative to the Authorization header
+	APIKeyHeader = "X-API-Key"
+	// APIKeyScopesKey is the gin context key holding the scopes of the API key
+	// used for the request. It is unset for JWT-authenticated requests.
+	APIKeyScopesKey = "api_key_scopes"
+)
+
+// Authenticate accepts either an API key, sent as X-API-Key or as a bearer
+// token starting with services.APIKeyPrefix, or a JWT. API keys resolve to
+// their owner's user ID and role so RequireRole and RequirePermission apply
+// unchanged, further limited to the key's scopes.
+func Authenticate(cfg *config.Config, apiKeys *services.APIKeyService) gin.HandlerFunc {
+	jwtAuth := JWTAuth(cfg)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && strings.HasPrefix(token, services.APIKeyPrefix) {
+				key = token
+			}
+		}
+		if key == "" {
+			jwtAuth(c)
+			return
+		}
+
+		apiKey, err := apiKeys.Authenticate(key)
+		if err != nil {
+			status, code, message := http.StatusUnauthorized, "INVALID_API_KEY", "Invalid API key"
+			switch {
+			case errors.Is(err, services.ErrAPIKeyExpired):
+				code, message = "API_KEY_EXPIRED", "API key has expired"
+			case errors.Is(err, services.ErrAPIKeyRevoked):
+				code, message = "API_KEY_REVOKED", "API key has been revoked"
+			case errors.Is(err, services.ErrUserInactive), errors.Is(err, services.ErrUserNotFound):
+				code, message = "INVALID_API_KEY", "API key owner is not active"
+			case !errors.Is(err, services.ErrInvalidAPIKey):
+				status, code, message = http.StatusInternalServerError, "AUTH_ERROR", "Failed to verify API key"
+			}
+			c.JSON(status, gin.H{
+				"error":   code,
+				"message": message,
+			})
+			c.Abort()
+			return
+		}
+
+		// Store user info in context like JWTAuth
+		c.Set("user_id", strconv.FormatUint(uint64(apiKey.UserID), 10))
+		c.Set("email", apiKey.User.Email)
+		c.Set("role", apiKey.User.Role)
+		c.Set("api_key_id", apiKey.ID)
+		c.Set(APIKeyScopesKey, apiKey.GetScopes())
+
+		c.Next()
+	}
+}
+
+// scopeAllows reports whether the API key used for the request, if any,
+// grants the permission. JWT-authenticated requests are not scope-limited.
+func scopeAllows(c *gin.Context, permission Permission) bool {
+	value, exists := c.Get(APIKeyScopesKey)
+	if !exists {
+		return true
+	}
+	scopes, _ := value.([]string)
+	for _, scope := range scopes {
+		if Permission(scope) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// insufficientScope aborts a request whose API key lacks a permission
+func insufficientScope(c *gin.Context, permission Permission) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "INSUFFICIENT_SCOPE",
+		"message": "API key scope does not allow this action",
+		"details": gin.H{
+			"required_scope": permission,
+		},
+	})
+	c.Abort()
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/burndler/burndler/internal/config"
+	"github.com/burndler/burndler/internal/models"
+	"github.com/burndler/burndler/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.User{}, &models.APIKey{}))
+
+	user := &models.User{Email: "ci@example.com", Name: "ci", Role: "Developer", Active: true}
+	assert.NoError(t, db.Create(user).Error)
+
+	apiKeys := services.NewAPIKeyService(db)
+	createKey := func(name string, scopes ...string) (*models.APIKey, string) {
+		apiKey, key, err := apiKeys.CreateAPIKey(services.CreateAPIKeyRequest{Name: name, UserID: user.ID, Scopes: scopes})
+		assert.NoError(t, err)
+		return apiKey, key
+	}
+
+	_, validKey := createKey("valid", "read", "write", "build")
+	expired, expiredKey := createKey("expired", "read", "write", "build")
+	assert.NoError(t, db.Model(expired).Update("expires_at", time.Now().Add(-time.Hour)).Error)
+	revoked, revokedKey := createKey("revoked", "read", "write", "build")
+	_, err = apiKeys.RevokeAPIKey(revoked.ID)
+	assert.NoError(t, err)
+	_, readOnlyKey := createKey("read-only", "read")
+
+	router := gin.New()
+	router.Use(Authenticate(&config.Config{JWTSecret: "test-secret"}, apiKeys))
+	router.POST("/api/v1/containers", RequireRole("Developer"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id"), "role": c.GetString("role")})
+	})
+	router.POST("/api/v1/services/:id/build", RequirePermission(PermissionBuild), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "build started"})
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		header         string
+		value          string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "valid key as bearer token",
+			path:           "/api/v1/containers",
+			header:         "Authorization",
+			value:          "Bearer " + validKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "valid key in X-API-Key",
+			path:           "/api/v1/services/1/build",
+			header:         APIKeyHeader,
+			value:          validKey,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "expired key",
+			path:           "/api/v1/containers",
+			header:         APIKeyHeader,
+			value:          expiredKey,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "API_KEY_EXPIRED",
+		},
+		{
+			name:           "revoked key",
+			path:           "/api/v1/containers",
+			header:         "Authorization",
+			value:          "Bearer " + revokedKey,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "API_KEY_REVOKED",
+		},
+		{
+			name:           "unknown key",
+			path:           "/api/v1/containers",
+			header:         APIKeyHeader,
+			value:          services.APIKeyPrefix + "unknown",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "INVALID_API_KEY",
+		},
+		{
+			name:           "key without write scope",
+			path:           "/api/v1/containers",
+			header:         APIKeyHeader,
+			value:          readOnlyKey,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "INSUFFICIENT_SCOPE",
+		},
+		{
+			name:           "key without build scope",
+			path:           "/api/v1/services/1/build",
+			header:         APIKeyHeader,
+			value:          readOnlyKey,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "INSUFFICIENT_SCOPE",
+		},
+		{
+			name:           "non-key bearer token falls back to JWT",
+			path:           "/api/v1/containers",
+			header:         "Authorization",
+			value:          "Bearer not-a-jwt",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "INVALID_TOKEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, tt.path, nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+			}
+		})
+	}
+
+	// The key acts as its owner
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/containers", nil)
+	req.Header.Set(APIKeyHeader, validKey)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.JSONEq(t, fmt.Sprintf(`{"user_id":"%d","role":"Developer"}`, user.ID), w.Body.String())
+}
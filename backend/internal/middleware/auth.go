@@ -131,6 +131,26 @@ func RequireRole(requiredRole string) gin.HandlerFunc {
 			return
 		}
 
+		if requiredRole == "Admin" && userRole != "Admin" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "INSUFFICIENT_PERMISSIONS",
+				"message": "This operation requires Admin role",
+			})
+			c.Abort()
+			return
+		}
+
+		// For API keys, Developer operations need the write scope and Admin
+		// operations the admin scope
+		if requiredRole == "Developer" && !scopeAllows(c, PermissionWrite) {
+			insufficientScope(c, PermissionWrite)
+			return
+		}
+		if requiredRole == "Admin" && !scopeAllows(c, PermissionAdmin) {
+			insufficientScope(c, PermissionAdmin)
+			return
+		}
+
 		c.Next()
 	}
 }
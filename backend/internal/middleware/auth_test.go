@@ -317,6 +317,21 @@ func TestRequireRole(t *testing.T) {
 			hasRole:        true,
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "Developer accessing Admin-only endpoint",
+			requiredRole:   "Admin",
+			contextRole:    "Developer",
+			hasRole:        true,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "INSUFFICIENT_PERMISSIONS",
+		},
+		{
+			name:           "Admin accessing Admin-only endpoint",
+			requiredRole:   "Admin",
+			contextRole:    "Admin",
+			hasRole:        true,
+			expectedStatus: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -23,6 +23,7 @@ const (
 	PermissionWrite  Permission = "write"
 	PermissionDelete Permission = "delete"
 	PermissionAdmin  Permission = "admin"
+	PermissionBuild  Permission = "build" // trigger service builds
 )
 
 // RolePermissions maps roles to their permissions
@@ -32,6 +33,7 @@ var RolePermissions = map[RBACRoles][]Permission{
 		PermissionWrite,
 		PermissionDelete,
 		PermissionAdmin,
+		PermissionBuild,
 	},
 	RoleEngineer: {
 		PermissionRead,
@@ -41,6 +43,7 @@ var RolePermissions = map[RBACRoles][]Permission{
 		PermissionWrite,
 		PermissionDelete,
 		PermissionAdmin,
+		PermissionBuild,
 	},
 }
 
@@ -100,6 +103,11 @@ func RequirePermission(permission Permission) gin.HandlerFunc {
 			return
 		}
 
+		if !scopeAllows(c, permission) {
+			insufficientScope(c, permission)
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -135,6 +143,18 @@ func HasPermission(role RBACRoles, permission Permission) bool {
 	return false
 }
 
+// IsKnownPermission checks if any role grants the permission
+func IsKnownPermission(permission Permission) bool {
+	for _, permissions := range RolePermissions {
+		for _, p := range permissions {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // EnforceReadOnly ensures Engineers can only perform read operations
 func EnforceReadOnly() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -178,6 +178,81 @@ func TestRequirePermission(t *testing.T) {
 	}
 }
 
+func TestRequirePermission_Build(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Editor is a test-only role that may change configuration but not build
+	const roleEditor RBACRoles = "Editor"
+	RolePermissions[roleEditor] = []Permission{PermissionRead, PermissionWrite}
+	defer delete(RolePermissions, roleEditor)
+
+	tests := []struct {
+		name        string
+		role        RBACRoles
+		buildStatus int
+		saveStatus  int
+	}{
+		{
+			name:        "write without build",
+			role:        roleEditor,
+			buildStatus: http.StatusForbidden,
+			saveStatus:  http.StatusOK,
+		},
+		{
+			name:        "write and build",
+			role:        RoleDeveloper,
+			buildStatus: http.StatusOK,
+			saveStatus:  http.StatusOK,
+		},
+		{
+			name:        "read only",
+			role:        RoleEngineer,
+			buildStatus: http.StatusForbidden,
+			saveStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("role", string(tt.role))
+				c.Next()
+			})
+			router.PUT("/api/v1/services/:id", RequirePermission(PermissionWrite), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "saved"})
+			})
+			router.POST("/api/v1/services/:id/build", RequirePermission(PermissionBuild), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "build started"})
+			})
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/services/1/build", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.buildStatus {
+				t.Errorf("build status = %v, want %v", w.Code, tt.buildStatus)
+			}
+			if w.Code == http.StatusForbidden {
+				var response map[string]interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				details, _ := response["details"].(map[string]interface{})
+				if details["required_permission"] != string(PermissionBuild) {
+					t.Errorf("required_permission = %v, want %v", details["required_permission"], PermissionBuild)
+				}
+			}
+
+			req, _ = http.NewRequest(http.MethodPut, "/api/v1/services/1", nil)
+			w = httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.saveStatus {
+				t.Errorf("save status = %v, want %v", w.Code, tt.saveStatus)
+			}
+		})
+	}
+}
+
 func TestGetUserRole(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -528,15 +603,17 @@ func TestRolePermissions(t *testing.T) {
 		shouldHaveWrite   bool
 		shouldHaveDelete  bool
 		shouldHaveAdmin   bool
+		shouldHaveBuild   bool
 	}{
 		{
 			name:              "Developer permissions",
 			role:              RoleDeveloper,
-			expectedPermCount: 4,
+			expectedPermCount: 5,
 			shouldHaveRead:    true,
 			shouldHaveWrite:   true,
 			shouldHaveDelete:  true,
 			shouldHaveAdmin:   true,
+			shouldHaveBuild:   true,
 		},
 		{
 			name:              "Engineer permissions",
@@ -546,15 +623,17 @@ func TestRolePermissions(t *testing.T) {
 			shouldHaveWrite:   false,
 			shouldHaveDelete:  false,
 			shouldHaveAdmin:   false,
+			shouldHaveBuild:   false,
 		},
 		{
 			name:              "Admin permissions",
 			role:              RoleAdmin,
-			expectedPermCount: 4,
+			expectedPermCount: 5,
 			shouldHaveRead:    true,
 			shouldHaveWrite:   true,
 			shouldHaveDelete:  true,
 			shouldHaveAdmin:   true,
+			shouldHaveBuild:   true,
 		},
 	}
 
@@ -588,6 +667,11 @@ func TestRolePermissions(t *testing.T) {
 			if hasAdmin != tt.shouldHaveAdmin {
 				t.Errorf("HasPermission(Admin) = %v, want %v", hasAdmin, tt.shouldHaveAdmin)
 			}
+
+			hasBuild := HasPermission(tt.role, PermissionBuild)
+			if hasBuild != tt.shouldHaveBuild {
+				t.Errorf("HasPermission(Build) = %v, want %v", hasBuild, tt.shouldHaveBuild)
+			}
 		})
 	}
 }
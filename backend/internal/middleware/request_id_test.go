@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/api/v1/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(RequestIDKey))
+	})
+
+	tests := []struct {
+		name    string
+		inbound string
+		reused  bool
+	}{
+		{name: "generated when missing", inbound: "", reused: false},
+		{name: "inbound ID reused", inbound: "req-1234", reused: true},
+		{name: "inbound ID with spaces replaced", inbound: "bad id", reused: false},
+		{name: "overlong inbound ID replaced", inbound: strings.Repeat("a", maxRequestIDLength+1), reused: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/api/v1/ping", nil)
+			if tt.inbound != "" {
+				req.Header.Set(RequestIDHeader, tt.inbound)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(RequestIDHeader)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, requestID, w.Body.String(), "handlers see the response's request ID")
+			if tt.reused {
+				assert.Equal(t, tt.inbound, requestID)
+			} else {
+				_, err := uuid.Parse(requestID)
+				assert.NoError(t, err, "expected a generated UUID, got %q", requestID)
+			}
+		})
+	}
+}
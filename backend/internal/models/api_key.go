@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// APIKey is a long-lived credential for non-interactive clients such as CI.
+// Requests made with the key act as the owning user, limited to the key's
+// scopes.
+type APIKey struct {
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	Name       string         `gorm:"not null" json:"name"`
+	Prefix     string         `gorm:"not null" json:"prefix"`        // first characters of the key, to tell keys apart
+	KeyHash    string         `gorm:"uniqueIndex;not null" json:"-"` // hex SHA-256 of the key
+	UserID     uint           `gorm:"not null;index" json:"user_id"` // owner the key acts as
+	Scopes     datatypes.JSON `gorm:"type:jsonb" json:"scopes"`      // permissions the key may use
+	ExpiresAt  *time.Time     `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time     `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// GetScopes returns the key's scopes
+func (k *APIKey) GetScopes() []string {
+	var scopes []string
+	if len(k.Scopes) > 0 {
+		json.Unmarshal(k.Scopes, &scopes)
+	}
+	return scopes
+}
+
+// IsExpired checks if the key has passed its expiry time
+func (k *APIKey) IsExpired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// IsRevoked checks if the key has been revoked
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
@@ -9,20 +9,31 @@ import (
 
 // Build represents a package build job
 type Build struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	Name      string         `gorm:"not null" json:"name"`
-	ServiceID *uint          `gorm:"index" json:"service_id"`
-	UserID    uint           `gorm:"not null" json:"user_id"`
-	Status       string         `gorm:"not null;default:'queued'" json:"status"` // queued, building, completed, failed
-	Progress     int            `gorm:"default:0" json:"progress"`               // 0-100
-	DownloadURL  string         `json:"download_url,omitempty"`
-	Error        string         `json:"error,omitempty"`
-	ComposeYAML  string         `gorm:"type:text" json:"compose_yaml,omitempty"`
-	ManifestJSON string         `gorm:"type:text" json:"manifest_json,omitempty"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	CompletedAt  *time.Time     `json:"completed_at,omitempty"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Name            string         `gorm:"not null" json:"name"`
+	ServiceID       *uint          `gorm:"index;uniqueIndex:idx_builds_idempotency,priority:1" json:"service_id"`
+	UserID          uint           `gorm:"not null;uniqueIndex:idx_builds_idempotency,priority:2" json:"user_id"`
+	Status          string         `gorm:"not null;default:'queued'" json:"status"` // queued, building, completed, failed, cancelled
+	Progress        int            `gorm:"default:0" json:"progress"`               // 0-100
+	DownloadURL     string         `json:"download_url,omitempty"`
+	PackageChecksum string         `json:"package_checksum,omitempty"` // hex SHA-256 of the archive
+	PackageKey      string         `json:"-"`                          // storage key of the archive
+	PackageSize     int64          `json:"package_size,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	ComposeYAML     string         `gorm:"type:text" json:"compose_yaml,omitempty"`
+	ManifestJSON    string         `gorm:"type:text" json:"manifest_json,omitempty"`
+	SummaryMarkdown string         `gorm:"type:text" json:"-"`     // BUILD_SUMMARY.md of a completed service build
+	CallbackURL     string         `json:"callback_url,omitempty"` // notified when the build finishes
+	RequestID       string         `json:"request_id,omitempty"`   // X-Request-ID of the request that last queued the build
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	CompletedAt     *time.Time     `json:"completed_at,omitempty"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Idempotency-Key header of the triggering request. A key is unique per
+	// service and user while set; QueueBuildWithOptions clears it once the
+	// TTL has passed.
+	IdempotencyKey string `gorm:"uniqueIndex:idx_builds_idempotency,priority:3,where:idempotency_key <> ''" json:"-"`
 
 	// Relationships
 	User    User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -52,6 +63,11 @@ func (b *Build) IsFailed() bool {
 	return b.Status == "failed"
 }
 
+// IsCancelled checks if build was cancelled
+func (b *Build) IsCancelled() bool {
+	return b.Status == "cancelled"
+}
+
 // IsInProgress checks if build is in progress
 func (b *Build) IsInProgress() bool {
 	return b.Status == "building"
@@ -87,3 +103,16 @@ func (b *Build) GetSourceName() string {
 	}
 	return ""
 }
+
+// IsExpired checks if the build is older than its retention window.
+// The owning service's override is used when loaded, otherwise defaultRetentionDays.
+func (b *Build) IsExpired(now time.Time, defaultRetentionDays int) bool {
+	retentionDays := defaultRetentionDays
+	if b.Service != nil {
+		retentionDays = b.Service.GetBuildRetentionDays(defaultRetentionDays)
+	}
+	if retentionDays <= 0 {
+		return false
+	}
+	return b.CreatedAt.Before(now.AddDate(0, 0, -retentionDays))
+}
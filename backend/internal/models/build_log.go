@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BuildLog represents a single log entry written during a build stage
+type BuildLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	BuildID   uuid.UUID `gorm:"type:uuid;not null;index" json:"build_id"`
+	RequestID string    `json:"request_id,omitempty"` // X-Request-ID of the request that triggered the build
+	Stage     string    `gorm:"not null" json:"stage"`
+	Level     string    `gorm:"not null;default:'info'" json:"level"` // info, warn, error
+	Message   string    `gorm:"type:text" json:"message"`
+	Timestamp time.Time `gorm:"not null;index" json:"timestamp"`
+}
+
+// TableName specifies the table name for BuildLog model
+func (BuildLog) TableName() string {
+	return "build_logs"
+}
+
+// IsError checks if the log entry is an error
+func (l *BuildLog) IsError() bool {
+	return l.Level == "error"
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/driver/sqlite"
@@ -182,3 +183,50 @@ func TestBuild_GetBuildType(t *testing.T) {
 func ptrUint(i uint) *uint {
 	return &i
 }
+
+// Test IsExpired honors per-service retention overrides
+func TestBuild_IsExpired(t *testing.T) {
+	now := time.Now()
+	longRetention := 30
+	globalRetentionDays := 7
+
+	tests := []struct {
+		name     string
+		build    Build
+		expected bool
+	}{
+		{
+			name:     "direct build within global window",
+			build:    Build{CreatedAt: now.AddDate(0, 0, -3)},
+			expected: false,
+		},
+		{
+			name:     "direct build past global window",
+			build:    Build{CreatedAt: now.AddDate(0, 0, -10)},
+			expected: true,
+		},
+		{
+			name:     "service without override uses global window",
+			build:    Build{CreatedAt: now.AddDate(0, 0, -10), Service: &Service{}},
+			expected: true,
+		},
+		{
+			name:     "service with longer override keeps build past global window",
+			build:    Build{CreatedAt: now.AddDate(0, 0, -10), Service: &Service{BuildRetentionDays: &longRetention}},
+			expected: false,
+		},
+		{
+			name:     "service with longer override expires past its own window",
+			build:    Build{CreatedAt: now.AddDate(0, 0, -31), Service: &Service{BuildRetentionDays: &longRetention}},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.build.IsExpired(now, globalRetentionDays); got != tt.expected {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ type ContainerVersion struct {
 	Variables       datatypes.JSON `gorm:"type:text" json:"variables"`
 	ResourcePaths   datatypes.JSON `gorm:"type:text" json:"resource_paths"`
 	Dependencies    datatypes.JSON `gorm:"type:text" json:"dependencies"`
+	Channel         string         `gorm:"default:'stable';index" json:"channel"` // stable, beta, edge
 	Published       bool           `gorm:"default:false" json:"published"`
 	PublishedAt     *time.Time     `json:"published_at"`
 	CreatedAt       time.Time      `json:"created_at"`
@@ -26,6 +27,22 @@ type ContainerVersion struct {
 	Container Container `gorm:"foreignKey:ContainerID" json:"container,omitempty"`
 }
 
+// Release channels a container version can be published to
+const (
+	VersionChannelStable = "stable"
+	VersionChannelBeta   = "beta"
+	VersionChannelEdge   = "edge"
+)
+
+// IsValidVersionChannel checks if channel is a known release channel
+func IsValidVersionChannel(channel string) bool {
+	switch channel {
+	case VersionChannelStable, VersionChannelBeta, VersionChannelEdge:
+		return true
+	}
+	return false
+}
+
 // TableName specifies the table name for ContainerVersion model
 func (ContainerVersion) TableName() string {
 	return "container_versions"
@@ -9,16 +9,18 @@ import (
 
 // Service represents a collection of containers for deployment
 type Service struct {
-	ID              uint           `gorm:"primaryKey" json:"id"`
-	Name            string         `gorm:"not null" json:"name"`
-	Description     string         `json:"description"`
-	UserID          uint           `gorm:"not null" json:"user_id"`
-	Variables       datatypes.JSON `gorm:"type:text" json:"variables"`
-	EnvironmentVars datatypes.JSON `gorm:"type:text" json:"environment_vars"`
-	Active          bool           `gorm:"default:true" json:"active"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	Name               string         `gorm:"not null" json:"name"`
+	Description        string         `json:"description"`
+	UserID             uint           `gorm:"not null" json:"user_id"`
+	Variables          datatypes.JSON `gorm:"type:text" json:"variables"`
+	EnvironmentVars    datatypes.JSON `gorm:"type:text" json:"environment_vars"`
+	Active             bool           `gorm:"default:true" json:"active"`
+	Archived           bool           `gorm:"default:false;index" json:"archived"` // retired: hidden from default listings, never built
+	BuildRetentionDays *int           `json:"build_retention_days,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
 	User              User               `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -65,5 +67,13 @@ func (s *Service) HasContainer(containerID uint) bool {
 
 // CanBuild checks if service is ready for building
 func (s *Service) CanBuild() bool {
-	return s.Active && s.GetContainerCount() > 0
-}
\ No newline at end of file
+	return s.Active && !s.Archived && s.GetContainerCount() > 0
+}
+
+// GetBuildRetentionDays returns the service's retention override or the given default
+func (s *Service) GetBuildRetentionDays(defaultDays int) int {
+	if s.BuildRetentionDays != nil && *s.BuildRetentionDays > 0 {
+		return *s.BuildRetentionDays
+	}
+	return defaultDays
+}
@@ -15,6 +15,7 @@ type ServiceContainer struct {
 	ContainerVersionID uint           `gorm:"not null;index" json:"container_version_id"`
 	Order              int            `gorm:"default:0" json:"order"`
 	Enabled            bool           `gorm:"default:true" json:"enabled"`
+	Pinned             bool           `gorm:"default:false" json:"pinned"`
 	OverrideVars       datatypes.JSON `gorm:"type:text" json:"override_vars"`
 	CreatedAt          time.Time      `json:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at"`
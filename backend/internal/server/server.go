@@ -21,6 +21,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// buildCleanupInterval is how often expired builds are removed
+const buildCleanupInterval = time.Hour
+
 // Server represents the HTTP server
 type Server struct {
 	config        *config.Config
@@ -33,6 +36,8 @@ type Server struct {
 	setupService     *services.SetupService
 	containerService *services.ContainerService
 	serviceService   *services.ServiceService
+	buildService     *services.BuildService
+	apiKeyService    *services.APIKeyService
 	router           *gin.Engine
 }
 
@@ -41,7 +46,22 @@ func New(cfg *config.Config, db *gorm.DB, storage storage.Storage, merger *servi
 	authService := services.NewAuthService(cfg, db)
 	setupService := services.NewSetupService(db, cfg)
 	containerService := services.NewContainerService(db, storage, linter)
+	containerService.SetPublishWebhook(services.NewWebhookNotifier(cfg.PublishWebhookURL, cfg.WebhookSecret))
+	containerService.SetEnforceVersionOrder(cfg.EnforceVersionOrder)
 	serviceService := services.NewServiceService(db, storage)
+	buildService := services.NewBuildService(db, merger, linter, packager)
+	buildService.SetCallbackSecret(cfg.BuildCallbackSecret)
+	buildService.SetCallbackAllowPrivate(cfg.BuildCallbackAllowPrivate)
+	buildService.SetBuildRetentionDays(cfg.BuildRetentionDays)
+	if cfg.BuildDefaultLoggingDriver != "" {
+		buildService.SetDefaultLogging(&services.LoggingConfig{
+			Driver:  cfg.BuildDefaultLoggingDriver,
+			Options: cfg.BuildDefaultLoggingOptions,
+		})
+	}
+	buildService.SetComposeInterpolation(cfg.BuildComposeInterpolation)
+	buildService.SetBuildTimeout(cfg.BuildTimeout)
+	buildService.SetWorkerCount(cfg.BuildWorkerCount)
 	s := &Server{
 		config:        cfg,
 		db:            db,
@@ -53,6 +73,8 @@ func New(cfg *config.Config, db *gorm.DB, storage storage.Storage, merger *servi
 		setupService:     setupService,
 		containerService: containerService,
 		serviceService:   serviceService,
+		buildService:     buildService,
+		apiKeyService:    services.NewAPIKeyService(db),
 	}
 	s.setupRouter()
 	return s
@@ -60,14 +82,15 @@ func New(cfg *config.Config, db *gorm.DB, storage storage.Storage, merger *servi
 
 // setupRouter configures all routes and middleware
 func (s *Server) setupRouter() {
-	s.router = gin.Default()
+	s.router = gin.New()
+	s.router.Use(gin.Recovery(), middleware.RequestID())
 
 	// CORS middleware
 	s.router.Use(cors.New(cors.Config{
 		AllowOrigins:     s.config.CORSAllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Content-Type", "Authorization", middleware.RequestIDHeader, middleware.APIKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -78,8 +101,11 @@ func (s *Server) setupRouter() {
 	setupHandler := handlers.NewSetupHandler(s.setupService, s.db)
 	composeHandler := handlers.NewComposeHandler(s.merger, s.linter)
 	packageHandler := handlers.NewPackageHandler(s.packager, s.db)
+	packageHandler.SetBuildService(s.buildService)
 	containerHandler := handlers.NewContainerHandler(s.containerService, s.db)
-	serviceHandler := handlers.NewServiceHandler(s.serviceService, s.db)
+	serviceHandler := handlers.NewServiceHandler(s.serviceService, s.buildService, s.db)
+	buildHandler := handlers.NewBuildHandler(s.buildService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(s.apiKeyService)
 
 	// API v1 routes
 	v1 := s.router.Group("/api/v1")
@@ -110,7 +136,7 @@ func (s *Server) setupRouter() {
 
 	// Protected routes
 	protected := v1.Group("/")
-	protected.Use(middleware.JWTAuth(s.config))
+	protected.Use(middleware.Authenticate(s.config, s.apiKeyService))
 
 	// Compose operations
 	protected.POST("/compose/merge", composeHandler.Merge)
@@ -127,31 +153,60 @@ func (s *Server) setupRouter() {
 	containers.GET("/:id", containerHandler.GetContainer)
 	containers.PUT("/:id", middleware.RequireRole("Developer"), containerHandler.UpdateContainer)
 	containers.DELETE("/:id", middleware.RequireRole("Developer"), containerHandler.DeleteContainer)
+	containers.POST("/:id/restore", middleware.RequireRole("Developer"), containerHandler.RestoreContainer)
 
 	// Container version management
 	containers.GET("/:id/versions", containerHandler.ListVersions)
 	containers.POST("/:id/versions", middleware.RequireRole("Developer"), containerHandler.CreateVersion)
+	containers.POST("/:id/versions/bulk", middleware.RequireRole("Developer"), containerHandler.BulkCreateVersions)
+	containers.GET("/:id/versions/diff", containerHandler.DiffVersions)
+	containers.GET("/:id/versions/latest", containerHandler.GetLatestPublishedVersion)
 	containers.GET("/:id/versions/:version", containerHandler.GetVersion)
 	containers.PUT("/:id/versions/:version", middleware.RequireRole("Developer"), containerHandler.UpdateVersion)
 	containers.POST("/:id/versions/:version/publish", middleware.RequireRole("Developer"), containerHandler.PublishVersion)
+	containers.GET("/:id/channels/:channel/latest", containerHandler.GetLatestChannelVersion)
 
 	// Service management
 	serviceRoutes := protected.Group("/services")
+	serviceRoutes.Use(serviceHandler.RequireServiceAccess())
 	serviceRoutes.GET("", serviceHandler.ListServices)
 	serviceRoutes.POST("", middleware.RequireRole("Developer"), serviceHandler.CreateService)
+	serviceRoutes.GET("/export-all", serviceHandler.ExportAllServices)
+	serviceRoutes.POST("/import", middleware.RequireRole("Developer"), serviceHandler.ImportServices)
 	serviceRoutes.GET("/:id", serviceHandler.GetService)
 	serviceRoutes.PUT("/:id", middleware.RequireRole("Developer"), serviceHandler.UpdateService)
 	serviceRoutes.DELETE("/:id", middleware.RequireRole("Developer"), serviceHandler.DeleteService)
+	serviceRoutes.POST("/:id/archive", middleware.RequireRole("Developer"), serviceHandler.ArchiveService)
+	serviceRoutes.POST("/:id/unarchive", middleware.RequireRole("Developer"), serviceHandler.UnarchiveService)
 
 	// Service container management
 	serviceRoutes.GET("/:id/containers", serviceHandler.GetServiceContainers)
 	serviceRoutes.POST("/:id/containers", middleware.RequireRole("Developer"), serviceHandler.AddContainerToService)
 	serviceRoutes.PUT("/:id/containers/:container_id", middleware.RequireRole("Developer"), serviceHandler.UpdateServiceContainer)
 	serviceRoutes.DELETE("/:id/containers/:container_id", middleware.RequireRole("Developer"), serviceHandler.RemoveContainerFromService)
+	serviceRoutes.POST("/:id/containers/:container_id/reset-config", middleware.RequireRole("Developer"), serviceHandler.ResetServiceContainerConfig)
+	serviceRoutes.GET("/:id/containers/:container_id/variables/resolved", serviceHandler.GetResolvedContainerVariables)
 
 	// Service operations
 	serviceRoutes.POST("/:id/validate", serviceHandler.ValidateService)
-	serviceRoutes.POST("/:id/build", middleware.RequireRole("Developer"), serviceHandler.BuildService)
+	serviceRoutes.GET("/:id/variables/resolved", serviceHandler.GetResolvedVariables)
+	serviceRoutes.GET("/:id/upgrades", serviceHandler.GetUpgradeCandidates)
+	serviceRoutes.POST("/:id/build", middleware.RequirePermission(middleware.PermissionBuild), serviceHandler.BuildService)
+	serviceRoutes.GET("/:id/build/:build_id/logs", buildHandler.GetBuildLogs)
+	serviceRoutes.GET("/:id/build/:build_id/summary", buildHandler.GetBuildSummary)
+
+	// Admin build controls (Admin role only; Developers also hold the admin
+	// permission)
+	admin := protected.Group("/admin")
+	admin.Use(middleware.RequireRole("Admin"))
+	admin.POST("/builds/:build_id/fail", buildHandler.ForceFailBuild)
+	admin.POST("/builds/:build_id/retry", buildHandler.RetryBuild)
+
+	// API keys for CI integrations
+	apiKeys := admin.Group("/api-keys")
+	apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+	apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+	apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
 
 	// Serve static files if enabled
 	if s.config.ServeStaticFiles {
@@ -222,6 +277,11 @@ func (s *Server) Run() error {
 		close(errChan)
 	}()
 
+	// Remove expired builds in the background until shutdown
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	defer stopCleanup()
+	go s.runBuildCleanup(cleanupCtx)
+
 	// Wait for interrupt signal or server error
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -246,3 +306,26 @@ func (s *Server) Run() error {
 	log.Println("Server exited")
 	return nil
 }
+
+// runBuildCleanup removes expired builds at startup and then every
+// buildCleanupInterval until ctx is cancelled
+func (s *Server) runBuildCleanup(ctx context.Context) {
+	ticker := time.NewTicker(buildCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := s.buildService.CleanupExpiredBuilds(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Warning: build cleanup failed: %v", err)
+		}
+		if removed > 0 {
+			log.Printf("Removed %d expired builds", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,10 +10,14 @@ import (
 	"time"
 
 	"github.com/burndler/burndler/internal/config"
+	"github.com/burndler/burndler/internal/models"
 	"github.com/burndler/burndler/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
@@ -67,6 +72,79 @@ func TestServer_setupRouter(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "\"status\":\"healthy\"")
 }
 
+func TestNew_BuildTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{
+		CORSAllowedOrigins: []string{"http://localhost:3000"},
+		BuildWorkerCount:   1,
+		BuildTimeout:       45 * time.Minute,
+	}
+	srv := New(cfg, nil, nil, services.NewMerger(), services.NewLinter(), services.NewPackager(nil))
+
+	deadlines := make(chan time.Time, 1)
+	srv.buildService.RunBuildAsync(uuid.New(), func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		deadlines <- deadline
+		return nil
+	})
+
+	select {
+	case deadline := <-deadlines:
+		assert.WithinDuration(t, time.Now().Add(cfg.BuildTimeout), deadline, time.Minute)
+	case <-time.After(2 * time.Second):
+		t.Fatal("build job did not run")
+	}
+}
+
+func TestServer_AdminBuildRoutesRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Setup{}))
+	require.NoError(t, db.Create(&models.Setup{IsCompleted: true}).Error)
+
+	cfg := &config.Config{
+		JWTSecret:          "test-secret-key",
+		JWTIssuer:          "burndler",
+		JWTAudience:        "burndler-api",
+		CORSAllowedOrigins: []string{"http://localhost:3000"},
+	}
+	srv := New(cfg, db, nil, services.NewMerger(), services.NewLinter(), services.NewPackager(nil))
+
+	token := func(role string) string {
+		claims := &services.Claims{
+			UserID: "1",
+			Email:  "user@example.com",
+			Role:   role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    cfg.JWTIssuer,
+				Audience:  []string{cfg.JWTAudience},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWTSecret))
+		require.NoError(t, err)
+		return signed
+	}
+
+	for _, path := range []string{"/api/v1/admin/builds/not-a-uuid/fail", "/api/v1/admin/builds/not-a-uuid/retry"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token("Developer"))
+		srv.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, path)
+
+		// Admins get past the guard to the handler's own validation
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", "Bearer "+token("Admin"))
+		srv.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
+
 func TestServer_Run(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/burndler/burndler/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix starts every API key so it can be told apart from a JWT
+const APIKeyPrefix = "bdk_"
+
+// apiKeyDisplayLength is how much of a key is stored in clear to identify it
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	ErrAPIKeyExpired = errors.New("api key expired")
+	ErrAPIKeyRevoked = errors.New("api key revoked")
+)
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name      string
+	UserID    uint
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// APIKeyService manages API keys and authenticates requests made with them
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyService creates a new APIKeyService instance
+func NewAPIKeyService(db *gorm.DB) *APIKeyService {
+	return &APIKeyService{db: db}
+}
+
+// CreateAPIKey creates a key for a user and returns it with the plain key.
+// Only a hash of the key is stored, so it cannot be shown again.
+func (s *APIKeyService) CreateAPIKey(req CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, "", fmt.Errorf("api key name is required")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, "", fmt.Errorf("at least one scope is required")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("expiry must be in the future")
+	}
+
+	var user models.User
+	if err := s.db.First(&user, req.UserID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, "", ErrUserNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := APIKeyPrefix + hex.EncodeToString(random)
+
+	scopes, err := json.Marshal(req.Scopes)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode scopes: %w", err)
+	}
+
+	apiKey := &models.APIKey{
+		Name:      req.Name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   hashAPIKey(key),
+		UserID:    user.ID,
+		Scopes:    datatypes.JSON(scopes),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.db.Create(apiKey).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return apiKey, key, nil
+}
+
+// ListAPIKeys returns all API keys, newest first
+func (s *APIKeyService) ListAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := s.db.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key so it can no longer authenticate
+func (s *APIKeyService) RevokeAPIKey(id uint) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := s.db.First(&apiKey, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	if apiKey.IsRevoked() {
+		return nil, fmt.Errorf("api key is already revoked")
+	}
+
+	now := time.Now()
+	apiKey.RevokedAt = &now
+	if err := s.db.Save(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return &apiKey, nil
+}
+
+// Authenticate resolves a plain key to its API key record with the owning
+// user loaded, and records when it was last used
+func (s *APIKeyService) Authenticate(key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var apiKey models.APIKey
+	if err := s.db.Preload("User").Where("key_hash = ?", hashAPIKey(key)).First(&apiKey).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	now := time.Now()
+	if apiKey.IsRevoked() {
+		return nil, ErrAPIKeyRevoked
+	}
+	if apiKey.IsExpired(now) {
+		return nil, ErrAPIKeyExpired
+	}
+	if apiKey.User == nil {
+		return nil, ErrUserNotFound
+	}
+	if !apiKey.User.Active {
+		return nil, ErrUserInactive
+	}
+
+	s.db.Model(&apiKey).UpdateColumn("last_used_at", now)
+	apiKey.LastUsedAt = &now
+
+	return &apiKey, nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a key. Keys carry 256 bits of
+// randomness, so a fast hash is enough and allows lookup by hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/burndler/burndler/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	db := setupServiceTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.APIKey{}))
+	apiKeys := NewAPIKeyService(db)
+
+	user := &models.User{Email: "ci@example.com", Name: "ci", Role: "Developer", Active: true}
+	assert.NoError(t, db.Create(user).Error)
+
+	apiKey, key, err := apiKeys.CreateAPIKey(CreateAPIKeyRequest{Name: "ci", UserID: user.ID, Scopes: []string{"read", "build"}})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, apiKey.Prefix))
+	assert.NotContains(t, apiKey.KeyHash, key[len(APIKeyPrefix):], "only a hash of the key is stored")
+	assert.Equal(t, []string{"read", "build"}, apiKey.GetScopes())
+
+	authenticated, err := apiKeys.Authenticate(key)
+	assert.NoError(t, err)
+	assert.Equal(t, apiKey.ID, authenticated.ID)
+	assert.Equal(t, user.Email, authenticated.User.Email)
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	_, err = apiKeys.Authenticate(key + "x")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	// Expired keys are rejected
+	assert.NoError(t, db.Model(apiKey).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = apiKeys.Authenticate(key)
+	assert.ErrorIs(t, err, ErrAPIKeyExpired)
+
+	// Revoked keys are rejected, and can only be revoked once
+	assert.NoError(t, db.Model(apiKey).Update("expires_at", nil).Error)
+	revoked, err := apiKeys.RevokeAPIKey(apiKey.ID)
+	assert.NoError(t, err)
+	assert.True(t, revoked.IsRevoked())
+	_, err = apiKeys.Authenticate(key)
+	assert.ErrorIs(t, err, ErrAPIKeyRevoked)
+	_, err = apiKeys.RevokeAPIKey(apiKey.ID)
+	assert.EqualError(t, err, "api key is already revoked")
+	_, err = apiKeys.RevokeAPIKey(999)
+	assert.EqualError(t, err, "api key not found")
+}
+
+func TestAPIKeyService_CreateAPIKey_Validation(t *testing.T) {
+	db := setupServiceTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.APIKey{}))
+	apiKeys := NewAPIKeyService(db)
+
+	user := &models.User{Email: "ci@example.com", Name: "ci", Role: "Developer", Active: true}
+	assert.NoError(t, db.Create(user).Error)
+	past := time.Now().Add(-time.Hour)
+
+	_, _, err := apiKeys.CreateAPIKey(CreateAPIKeyRequest{UserID: user.ID, Scopes: []string{"read"}})
+	assert.EqualError(t, err, "api key name is required")
+	_, _, err = apiKeys.CreateAPIKey(CreateAPIKeyRequest{Name: "ci", UserID: user.ID})
+	assert.EqualError(t, err, "at least one scope is required")
+	_, _, err = apiKeys.CreateAPIKey(CreateAPIKeyRequest{Name: "ci", UserID: user.ID, Scopes: []string{"read"}, ExpiresAt: &past})
+	assert.EqualError(t, err, "expiry must be in the future")
+	_, _, err = apiKeys.CreateAPIKey(CreateAPIKeyRequest{Name: "ci", UserID: 999, Scopes: []string{"read"}})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestAPIKeyService_JSONOmitsUnloadedUser(t *testing.T) {
+	db := setupServiceTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.APIKey{}))
+	apiKeys := NewAPIKeyService(db)
+
+	user := &models.User{Email: "ci@example.com", Name: "ci", Role: "Developer", Active: true}
+	assert.NoError(t, db.Create(user).Error)
+
+	apiKey, _, err := apiKeys.CreateAPIKey(CreateAPIKeyRequest{Name: "ci", UserID: user.ID, Scopes: []string{"read"}})
+	assert.NoError(t, err)
+	created, err := json.Marshal(apiKey)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(created), `"user"`)
+
+	keys, err := apiKeys.ListAPIKeys()
+	assert.NoError(t, err)
+	listed, err := json.Marshal(keys)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(listed), `"user"`)
+}
@@ -0,0 +1,940 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/burndler/burndler/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Build stages recorded in build logs
+const (
+	BuildStageValidation = "validation"
+	BuildStageMerge      = "merge"
+	BuildStageLint       = "lint"
+	BuildStagePackage    = "package"
+
+	// BuildStageAdmin records manual operator actions on a build
+	BuildStageAdmin = "admin"
+)
+
+// BuildIdempotencyTTL is how long an Idempotency-Key maps to the build it
+// created
+const BuildIdempotencyTTL = 24 * time.Hour
+
+// errBuildNotQueued is returned by ExecuteBuild when the build was already
+// started, finished or force-failed before a worker picked it up
+var errBuildNotQueued = errors.New("build is not queued")
+
+// errBuildNotRunning is returned when a build stopped being "building" while
+// its worker was running, e.g. because it was force-failed
+var errBuildNotRunning = errors.New("build is no longer running")
+
+// buildStageProgress is the build progress recorded once each stage completes
+var buildStageProgress = map[string]int{
+	BuildStageValidation: 10,
+	BuildStageMerge:      40,
+	BuildStageLint:       60,
+	BuildStagePackage:    100,
+}
+
+// BuildService executes service builds and records their logs
+type BuildService struct {
+	db       *gorm.DB
+	merger   *Merger
+	linter   *Linter
+	packager *Packager
+
+	// callbackSecret signs build completion callbacks
+	callbackSecret string
+	// callbackAllowPrivate lets callback URLs target loopback, private and
+	// link-local addresses
+	callbackAllowPrivate bool
+
+	// defaultLogging is applied to built services without a logging driver
+	defaultLogging *LoggingConfig
+	// composeInterpolation resolves ${VAR:-default} when merging builds
+	composeInterpolation bool
+
+	// retentionDays is how long finished builds are kept unless their
+	// service overrides it
+	retentionDays int
+
+	// buildTimeout cancels a build that runs longer; zero or less means no
+	// limit
+	buildTimeout time.Duration
+
+	// workerCount limits how many builds ExecuteBuildAsync and RunBuildAsync
+	// run at once; zero or less runs every build immediately
+	workerCount int
+	queueMu     sync.Mutex
+	pending     []queuedBuild      // builds waiting for a worker, oldest first
+	running     map[uuid.UUID]bool // builds a worker is executing
+}
+
+// queuedBuild is a build waiting for a worker and the job that runs it
+type queuedBuild struct {
+	id  uuid.UUID
+	run func(ctx context.Context) error
+}
+
+// NewBuildService creates a new BuildService instance
+func NewBuildService(db *gorm.DB, merger *Merger, linter *Linter, packager *Packager) *BuildService {
+	return &BuildService{
+		db:       db,
+		merger:   merger,
+		linter:   linter,
+		packager: packager,
+		running:  make(map[uuid.UUID]bool),
+	}
+}
+
+// SetCallbackSecret configures the secret used to sign build completion
+// callbacks
+func (s *BuildService) SetCallbackSecret(secret string) {
+	s.callbackSecret = secret
+}
+
+// SetCallbackAllowPrivate allows build callbacks to loopback, private and
+// link-local addresses. It is off by default because any developer can
+// choose a build's callback URL.
+func (s *BuildService) SetCallbackAllowPrivate(allow bool) {
+	s.callbackAllowPrivate = allow
+}
+
+// ValidateCallbackURL checks that a callback URL is an absolute http or
+// https URL and, unless private addresses are allowed, that its host only
+// resolves to public addresses
+func (s *BuildService) ValidateCallbackURL(ctx context.Context, callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("callback_url must be an absolute http or https URL")
+	}
+	if s.callbackAllowPrivate {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("callback_url host could not be resolved")
+	}
+	for _, addr := range addrs {
+		if !IsPublicIP(addr.IP) {
+			return fmt.Errorf("callback_url must not point to a loopback, private or link-local address")
+		}
+	}
+	return nil
+}
+
+// SetDefaultLogging configures the logging driver applied to built services
+// that do not declare their own. nil leaves logging untouched.
+func (s *BuildService) SetDefaultLogging(logging *LoggingConfig) {
+	s.defaultLogging = logging
+}
+
+// SetComposeInterpolation enables docker-compose ${VAR:-default} resolution
+// in service builds and dry runs
+func (s *BuildService) SetComposeInterpolation(enabled bool) {
+	s.composeInterpolation = enabled
+}
+
+// SetBuildTimeout configures how long a worker may run one build before its
+// context is cancelled. Zero or less means no limit.
+func (s *BuildService) SetBuildTimeout(timeout time.Duration) {
+	s.buildTimeout = timeout
+}
+
+// SetBuildRetentionDays configures the default retention used by
+// CleanupExpiredBuilds. Zero or less keeps builds forever.
+func (s *BuildService) SetBuildRetentionDays(days int) {
+	s.retentionDays = days
+}
+
+// SetWorkerCount limits how many builds run at once. Builds started beyond
+// the limit stay "queued" until a running build finishes.
+func (s *BuildService) SetWorkerCount(count int) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	s.workerCount = count
+	s.dispatchLocked()
+}
+
+// QueueBuildOptions are the optional settings of a queued build
+type QueueBuildOptions struct {
+	// IdempotencyKey makes repeated requests from the same user return the
+	// build created by the first one within BuildIdempotencyTTL
+	IdempotencyKey string
+	// CallbackURL is notified when the build finishes
+	CallbackURL string
+	// RequestID identifies the API request that triggered the build and is
+	// recorded on its log entries
+	RequestID string
+}
+
+// QueueBuild creates a queued build record for a service
+func (s *BuildService) QueueBuild(serviceID, userID uint) (*models.Build, error) {
+	build, _, err := s.QueueBuildWithOptions(serviceID, userID, QueueBuildOptions{})
+	return build, err
+}
+
+// QueueBuildWithOptions queues a build like QueueBuild. When the same user
+// already triggered a build of the service with the same non-empty
+// idempotency key within BuildIdempotencyTTL, that build is returned
+// instead and existing is true.
+func (s *BuildService) QueueBuildWithOptions(serviceID, userID uint, opts QueueBuildOptions) (build *models.Build, existing bool, err error) {
+	idempotencyKey := opts.IdempotencyKey
+	if idempotencyKey != "" {
+		previous, err := s.findIdempotentBuild(serviceID, userID, idempotencyKey)
+		if err != nil {
+			return nil, false, err
+		}
+		if previous != nil {
+			return previous, true, nil
+		}
+
+		// The key is unique per service and user, so release it from a
+		// build that is past the TTL before reusing it
+		if err := s.db.Unscoped().Model(&models.Build{}).
+			Where("service_id = ? AND user_id = ? AND idempotency_key = ? AND created_at <= ?",
+				serviceID, userID, idempotencyKey, time.Now().Add(-BuildIdempotencyTTL)).
+			Update("idempotency_key", "").Error; err != nil {
+			return nil, false, fmt.Errorf("failed to release idempotency key: %w", err)
+		}
+	}
+
+	service, err := s.loadBuildableService(serviceID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	build = &models.Build{
+		Name:           service.Name,
+		ServiceID:      &service.ID,
+		UserID:         userID,
+		Status:         "queued",
+		IdempotencyKey: idempotencyKey,
+		CallbackURL:    opts.CallbackURL,
+		RequestID:      opts.RequestID,
+	}
+	if err := s.db.Create(build).Error; err != nil {
+		// A concurrent request with the same key won the unique index
+		if idempotencyKey != "" {
+			if previous, findErr := s.findIdempotentBuild(serviceID, userID, idempotencyKey); findErr == nil && previous != nil {
+				return previous, true, nil
+			}
+		}
+		return nil, false, fmt.Errorf("failed to create build: %w", err)
+	}
+
+	return build, false, nil
+}
+
+// findIdempotentBuild returns the build the user triggered for the service
+// with the idempotency key within BuildIdempotencyTTL, or nil if none
+func (s *BuildService) findIdempotentBuild(serviceID, userID uint, idempotencyKey string) (*models.Build, error) {
+	var previous models.Build
+	err := s.db.Where("service_id = ? AND user_id = ? AND idempotency_key = ? AND created_at > ?",
+		serviceID, userID, idempotencyKey, time.Now().Add(-BuildIdempotencyTTL)).
+		Order("created_at DESC").First(&previous).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up build: %w", err)
+	}
+	return &previous, nil
+}
+
+// ExecuteBuildAsync runs ExecuteBuild in the background once a worker is
+// free. The outcome is recorded on the build; failures are also logged.
+// A build that is already waiting or running is not scheduled twice.
+func (s *BuildService) ExecuteBuildAsync(buildID uuid.UUID) {
+	s.RunBuildAsync(buildID, func(ctx context.Context) error {
+		return s.ExecuteBuild(ctx, buildID)
+	})
+}
+
+// RunBuildAsync runs a build job, such as a /build/package archive, on the
+// same worker queue as ExecuteBuildAsync. The job is responsible for
+// recording its outcome on the build; a returned error is only logged.
+func (s *BuildService) RunBuildAsync(buildID uuid.UUID, run func(ctx context.Context) error) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	if s.scheduledLocked(buildID) {
+		return
+	}
+	s.pending = append(s.pending, queuedBuild{id: buildID, run: run})
+	s.dispatchLocked()
+}
+
+// scheduledLocked reports whether a build is waiting for or held by a
+// worker. The caller must hold queueMu.
+func (s *BuildService) scheduledLocked(buildID uuid.UUID) bool {
+	if s.running[buildID] {
+		return true
+	}
+	for _, queued := range s.pending {
+		if queued.id == buildID {
+			return true
+		}
+	}
+	return false
+}
+
+// QueuePosition returns the 1-based position of a build waiting for a
+// worker, or 0 when it is not waiting
+func (s *BuildService) QueuePosition(buildID uuid.UUID) int {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	for i, queued := range s.pending {
+		if queued.id == buildID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// dispatchLocked starts pending builds while workers are free. The caller
+// must hold queueMu.
+func (s *BuildService) dispatchLocked() {
+	for len(s.pending) > 0 && (s.workerCount <= 0 || len(s.running) < s.workerCount) {
+		queued := s.pending[0]
+		s.pending = s.pending[1:]
+		s.running[queued.id] = true
+		go s.runWorker(queued)
+	}
+}
+
+// runWorker executes one build within the build timeout and hands its
+// worker to the next pending build
+func (s *BuildService) runWorker(queued queuedBuild) {
+	defer func() {
+		s.queueMu.Lock()
+		defer s.queueMu.Unlock()
+		delete(s.running, queued.id)
+		s.dispatchLocked()
+	}()
+
+	ctx := context.Background()
+	if s.buildTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.buildTimeout)
+		defer cancel()
+	}
+
+	err := queued.run(ctx)
+	switch {
+	case errors.Is(err, errBuildNotQueued), errors.Is(err, errBuildNotRunning):
+		log.Printf("build %s skipped: %v", queued.id, err)
+	case err != nil:
+		log.Printf("build %s failed: %v", queued.id, err)
+	}
+}
+
+// ForceFailBuild marks a queued or running build as failed with the given
+// reason. A pending build is dropped from the worker queue, and a running
+// worker stops writing to the build at its next stage.
+func (s *BuildService) ForceFailBuild(buildID uuid.UUID, reason string) (*models.Build, error) {
+	build, err := s.getBuild(buildID)
+	if err != nil {
+		return nil, err
+	}
+	if build.Status != "queued" && !build.IsInProgress() {
+		return nil, fmt.Errorf("build is not in progress")
+	}
+
+	s.removePending(buildID)
+
+	now := time.Now()
+	result := s.db.Model(&models.Build{}).Where("id = ? AND status IN ?", build.ID, []string{"queued", "building"}).
+		Updates(map[string]interface{}{"status": "failed", "error": reason, "completed_at": &now})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update build: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// The worker finished the build first
+		return nil, fmt.Errorf("build is not in progress")
+	}
+	s.log(build, BuildStageAdmin, "error", fmt.Sprintf("Build force-failed: %s", reason))
+	build.Status = "failed"
+	build.Error = reason
+	build.CompletedAt = &now
+
+	return build, nil
+}
+
+// RetryBuild resets a failed or cancelled service build to queued so it can
+// be executed again. Previous logs are kept; new ones carry requestID. A
+// build that was force-failed while its worker is still waiting or running
+// cannot be retried until that worker is done.
+func (s *BuildService) RetryBuild(buildID uuid.UUID, requestID string) (*models.Build, error) {
+	build, err := s.getBuild(buildID)
+	if err != nil {
+		return nil, err
+	}
+	if !build.IsFailed() && !build.IsCancelled() {
+		return nil, fmt.Errorf("only failed or cancelled builds can be retried")
+	}
+	if !build.IsServiceBuild() {
+		return nil, fmt.Errorf("only service builds can be retried")
+	}
+	s.queueMu.Lock()
+	scheduled := s.scheduledLocked(buildID)
+	s.queueMu.Unlock()
+	if scheduled {
+		return nil, fmt.Errorf("build is still being processed")
+	}
+
+	build.RequestID = requestID
+	build.Status = "queued"
+	build.Progress = 0
+	build.Error = ""
+	build.DownloadURL = ""
+	build.PackageChecksum = ""
+	build.PackageKey = ""
+	build.PackageSize = 0
+	build.ComposeYAML = ""
+	build.SummaryMarkdown = ""
+	build.CompletedAt = nil
+	result := s.db.Model(&models.Build{}).Where("id = ? AND status IN ?", build.ID, []string{"failed", "cancelled"}).
+		Select("request_id", "status", "progress", "error", "download_url", "package_checksum", "package_key",
+			"package_size", "compose_yaml", "summary_markdown", "completed_at").
+		Updates(build)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update build: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// A concurrent retry already queued the build
+		return nil, fmt.Errorf("only failed or cancelled builds can be retried")
+	}
+	s.log(build, BuildStageAdmin, "info", "Build retried")
+
+	return build, nil
+}
+
+// removePending drops a build from the queue of builds waiting for a worker
+func (s *BuildService) removePending(buildID uuid.UUID) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	for i, queued := range s.pending {
+		if queued.id == buildID {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// getBuild loads a build by ID
+func (s *BuildService) getBuild(buildID uuid.UUID) (*models.Build, error) {
+	var build models.Build
+	if err := s.db.First(&build, "id = ?", buildID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("build not found")
+		}
+		return nil, fmt.Errorf("failed to get build: %w", err)
+	}
+	return &build, nil
+}
+
+// ExecuteBuild runs every build stage for a queued service build and
+// updates the build record with the outcome. Builds that are no longer
+// queued, e.g. because they were force-failed while waiting, are left
+// untouched. Cancelling ctx stops the build before the next stage and marks
+// it "cancelled". The build's callback URL, if any, is notified once the
+// build finishes, unless it was force-failed while running.
+func (s *BuildService) ExecuteBuild(ctx context.Context, buildID uuid.UUID) (err error) {
+	var build models.Build
+	if err := s.db.First(&build, "id = ?", buildID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("build not found")
+		}
+		return fmt.Errorf("failed to get build: %w", err)
+	}
+	if build.Status != "queued" {
+		return errBuildNotQueued
+	}
+
+	// Claim the build so a concurrent force-fail wins over this worker
+	result := s.db.Model(&models.Build{}).Where("id = ? AND status = ?", build.ID, "queued").Update("status", "building")
+	if result.Error != nil {
+		return fmt.Errorf("failed to update build: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errBuildNotQueued
+	}
+	build.Status = "building"
+	defer func() {
+		if !errors.Is(err, errBuildNotRunning) {
+			s.sendBuildCallback(&build)
+		}
+	}()
+
+	// Validation
+	s.log(&build, BuildStageValidation, "info", "Validating service")
+	if build.ServiceID == nil {
+		return s.failBuild(&build, BuildStageValidation, fmt.Errorf("build is not associated with a service"))
+	}
+	service, err := s.loadBuildableService(*build.ServiceID)
+	if err != nil {
+		return s.failBuild(&build, BuildStageValidation, err)
+	}
+	if err := s.completeStage(&build, BuildStageValidation); err != nil {
+		return err
+	}
+
+	// Merge
+	if err := ctx.Err(); err != nil {
+		return s.cancelBuild(&build, BuildStageMerge, err)
+	}
+	s.log(&build, BuildStageMerge, "info", fmt.Sprintf("Merging %d containers", service.GetContainerCount()))
+	mergeResult, err := s.merger.Merge(s.buildMergeRequest(service))
+	if err != nil {
+		return s.failBuild(&build, BuildStageMerge, err)
+	}
+	for _, warning := range mergeResult.Warnings {
+		s.log(&build, BuildStageMerge, "warn", warning)
+	}
+	build.ComposeYAML = mergeResult.MergedCompose
+	if err := s.completeStage(&build, BuildStageMerge); err != nil {
+		return err
+	}
+
+	// Lint
+	if err := ctx.Err(); err != nil {
+		return s.cancelBuild(&build, BuildStageLint, err)
+	}
+	s.log(&build, BuildStageLint, "info", "Linting merged compose")
+	lintResult, err := s.linter.Lint(&LintRequest{Compose: mergeResult.MergedCompose})
+	if err != nil {
+		return s.failBuild(&build, BuildStageLint, err)
+	}
+	for _, warning := range lintResult.Warnings {
+		s.log(&build, BuildStageLint, "warn", warning.Message)
+	}
+	if !lintResult.Valid {
+		for _, issue := range lintResult.Errors {
+			s.log(&build, BuildStageLint, "error", issue.Message)
+		}
+		return s.failBuild(&build, BuildStageLint, fmt.Errorf("compose validation failed with %d errors", len(lintResult.Errors)))
+	}
+	if err := s.completeStage(&build, BuildStageLint); err != nil {
+		return err
+	}
+
+	// Package
+	if err := ctx.Err(); err != nil {
+		return s.cancelBuild(&build, BuildStagePackage, err)
+	}
+	files, err := resolveSecretFiles(service, mergeResult.SecretFiles)
+	if err != nil {
+		return s.failBuild(&build, BuildStagePackage, err)
+	}
+	if len(files) > 0 {
+		s.log(&build, BuildStagePackage, "info", fmt.Sprintf("Packaging %d secret files", len(files)))
+	}
+	s.log(&build, BuildStagePackage, "info", "Creating package")
+	pkg, err := s.packager.BuildPackage(ctx, &PackageRequest{
+		Name:    build.Name,
+		Compose: mergeResult.MergedCompose,
+		Files:   files,
+		BuildID: build.ID.String(),
+		Service: service.Name,
+		Summary: buildSummary(&build, service, lintResult),
+	})
+	if err != nil {
+		return s.failBuild(&build, BuildStagePackage, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return s.cancelBuild(&build, BuildStagePackage, err)
+	}
+
+	now := time.Now()
+	build.Status = "completed"
+	build.Progress = buildStageProgress[BuildStagePackage]
+	build.DownloadURL = pkg.URL
+	build.PackageChecksum = pkg.Checksum
+	build.PackageKey = pkg.Key
+	build.PackageSize = pkg.Size
+	build.SummaryMarkdown = pkg.Summary
+	build.CompletedAt = &now
+	if err := s.saveRunningBuild(&build); err != nil {
+		return err
+	}
+	s.log(&build, BuildStagePackage, "info", "Build completed")
+
+	return nil
+}
+
+// CleanupExpiredBuilds deletes finished builds older than their retention
+// window, together with their logs and stored packages, and returns how many
+// builds were removed. A build whose package cannot be deleted is kept so a
+// later run can retry it.
+func (s *BuildService) CleanupExpiredBuilds(ctx context.Context) (int, error) {
+	if s.retentionDays <= 0 {
+		return 0, nil
+	}
+
+	// No build can expire sooner than the shortest retention in effect,
+	// so older builds are the only candidates
+	minRetentionDays := s.retentionDays
+	var minOverride *int
+	if err := s.db.Model(&models.Service{}).Where("build_retention_days > 0").
+		Select("MIN(build_retention_days)").Scan(&minOverride).Error; err != nil {
+		return 0, fmt.Errorf("failed to get service retention: %w", err)
+	}
+	if minOverride != nil && *minOverride < minRetentionDays {
+		minRetentionDays = *minOverride
+	}
+
+	now := time.Now()
+	var builds []models.Build
+	if err := s.db.Select("id", "service_id", "package_key", "created_at").
+		Preload("Service", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "build_retention_days")
+		}).
+		Where("status IN ? AND created_at < ?", []string{"completed", "failed", "cancelled"}, now.AddDate(0, 0, -minRetentionDays)).
+		Find(&builds).Error; err != nil {
+		return 0, fmt.Errorf("failed to list builds: %w", err)
+	}
+
+	removed := 0
+	for _, build := range builds {
+		if !build.IsExpired(now, s.retentionDays) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+
+		if build.PackageKey != "" {
+			if err := s.packager.DeletePackage(ctx, build.PackageKey); err != nil {
+				log.Printf("Warning: failed to delete package %s of build %s: %v", build.PackageKey, build.ID, err)
+				continue
+			}
+		}
+
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("build_id = ?", build.ID).Delete(&models.BuildLog{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Delete(&models.Build{}, "id = ?", build.ID).Error
+		})
+		if err != nil {
+			return removed, fmt.Errorf("failed to delete build %s: %w", build.ID, err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// DryRun runs validation, merge and lint for a service without creating a
+// build or a package, and returns the merged compose YAML
+func (s *BuildService) DryRun(ctx context.Context, serviceID uint) (string, error) {
+	service, err := s.loadBuildableService(serviceID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	mergeResult, err := s.merger.Merge(s.buildMergeRequest(service))
+	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	lintResult, err := s.linter.Lint(&LintRequest{Compose: mergeResult.MergedCompose})
+	if err != nil {
+		return "", err
+	}
+	if !lintResult.Valid {
+		return "", fmt.Errorf("compose validation failed with %d errors", len(lintResult.Errors))
+	}
+
+	if _, err := resolveSecretFiles(service, mergeResult.SecretFiles); err != nil {
+		return "", err
+	}
+
+	return mergeResult.MergedCompose, nil
+}
+
+// loadBuildableService loads a service with its containers and checks that
+// it is ready to build
+func (s *BuildService) loadBuildableService(serviceID uint) (*models.Service, error) {
+	var service models.Service
+	if err := s.db.Preload("ServiceContainers").Preload("ServiceContainers.Container").Preload("ServiceContainers.ContainerVersion").
+		First(&service, serviceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("service not found")
+		}
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if service.Archived {
+		return nil, fmt.Errorf("service is archived")
+	}
+	if !service.CanBuild() {
+		return nil, fmt.Errorf("service is not ready for building")
+	}
+	return &service, nil
+}
+
+// BuildFinishedEvent is the callback payload sent when a build finishes
+type BuildFinishedEvent struct {
+	Event           string    `json:"event"`
+	BuildID         string    `json:"build_id"`
+	ServiceID       *uint     `json:"service_id"`
+	Status          string    `json:"status"`
+	Error           string    `json:"error,omitempty"`
+	DownloadURL     string    `json:"download_url,omitempty"`
+	PackageChecksum string    `json:"package_checksum,omitempty"`
+	PackageSize     int64     `json:"package_size,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+// sendBuildCallback posts a build.finished event to the build's callback URL
+// once the build has reached a final status. Delivery runs in the
+// background so a slow endpoint does not hold a worker, and failures are
+// only logged; they never change the build's outcome.
+func (s *BuildService) sendBuildCallback(build *models.Build) {
+	if build.CallbackURL == "" {
+		return
+	}
+	if !build.IsComplete() && !build.IsFailed() && !build.IsCancelled() {
+		return
+	}
+
+	event := BuildFinishedEvent{
+		Event:           "build.finished",
+		BuildID:         build.ID.String(),
+		ServiceID:       build.ServiceID,
+		Status:          build.Status,
+		Error:           build.Error,
+		DownloadURL:     build.DownloadURL,
+		PackageChecksum: build.PackageChecksum,
+		PackageSize:     build.PackageSize,
+		Timestamp:       time.Now().UTC(),
+	}
+
+	notifier := NewWebhookNotifier(build.CallbackURL, s.callbackSecret)
+	if !s.callbackAllowPrivate {
+		notifier.RestrictToPublicAddresses()
+	}
+	go func() {
+		if err := notifier.Send(context.Background(), event.Event, event); err != nil {
+			log.Printf("Warning: build callback for %s failed: %v", event.BuildID, err)
+		}
+	}()
+}
+
+// completeStage records the progress reached after a stage finishes
+func (s *BuildService) completeStage(build *models.Build, stage string) error {
+	build.Progress = buildStageProgress[stage]
+	return s.saveRunningBuild(build)
+}
+
+// saveRunningBuild writes the fields a worker changes while the build is
+// still "building". It returns errBuildNotRunning once the build was
+// force-failed meanwhile, so a worker never overwrites that outcome.
+func (s *BuildService) saveRunningBuild(build *models.Build) error {
+	result := s.db.Model(&models.Build{}).Where("id = ? AND status = ?", build.ID, "building").
+		Select("status", "progress", "error", "compose_yaml", "download_url", "package_checksum",
+			"package_key", "package_size", "summary_markdown", "completed_at").
+		Updates(build)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update build: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errBuildNotRunning
+	}
+	return nil
+}
+
+// failBuild marks the build as failed and records the error as a log entry.
+// Errors caused by context cancellation mark the build cancelled instead.
+// It returns the save error, e.g. errBuildNotRunning, if the build could not
+// be updated.
+func (s *BuildService) failBuild(build *models.Build, stage string, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return s.cancelBuild(build, stage, err)
+	}
+	s.log(build, stage, "error", err.Error())
+	now := time.Now()
+	build.Status = "failed"
+	build.Error = err.Error()
+	build.CompletedAt = &now
+	if saveErr := s.saveRunningBuild(build); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// cancelBuild marks the build as cancelled and returns the context error,
+// or the save error if the build could not be updated
+func (s *BuildService) cancelBuild(build *models.Build, stage string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = context.DeadlineExceeded
+	} else {
+		err = context.Canceled
+	}
+	s.log(build, stage, "warn", fmt.Sprintf("Build cancelled: %v", err))
+	now := time.Now()
+	build.Status = "cancelled"
+	build.Error = err.Error()
+	build.CompletedAt = &now
+	if saveErr := s.saveRunningBuild(build); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// buildMergeRequest turns a service's enabled containers into merge modules
+// using the same variable precedence as ResolveVariables, with the build
+// service's merge settings
+func (s *BuildService) buildMergeRequest(service *models.Service) *MergeRequest {
+	containers := service.GetEnabledContainers()
+	sort.SliceStable(containers, func(i, j int) bool {
+		return containers[i].Order < containers[j].Order
+	})
+
+	req := &MergeRequest{
+		Modules:              []Module{},
+		DefaultLogging:       s.defaultLogging,
+		ComposeInterpolation: s.composeInterpolation,
+	}
+	for _, sc := range containers {
+		variables := make(map[string]string)
+		for name, variable := range resolveVariableLayers(service, sc) {
+			variables[name] = fmt.Sprint(variable.Value)
+		}
+
+		req.Modules = append(req.Modules, Module{
+			Name:      sc.Container.Name,
+			Compose:   sc.ContainerVersion.ComposeContent,
+			Variables: variables,
+		})
+	}
+
+	return req
+}
+
+// buildSummary collects the containers and lint warnings of a service build
+// for its summary report
+func buildSummary(build *models.Build, service *models.Service, lintResult *LintResult) *BuildSummary {
+	summary := &BuildSummary{
+		Service:      service.Name,
+		BuildID:      build.ID.String(),
+		Containers:   []SummaryContainer{},
+		LintWarnings: []string{},
+	}
+	containers := service.GetEnabledContainers()
+	sort.SliceStable(containers, func(i, j int) bool {
+		return containers[i].Order < containers[j].Order
+	})
+	for _, sc := range containers {
+		summary.Containers = append(summary.Containers, SummaryContainer{
+			Name:    sc.Container.Name,
+			Version: sc.ContainerVersion.Version,
+		})
+	}
+	for _, warning := range lintResult.Warnings {
+		summary.LintWarnings = append(summary.LintWarnings, warning.Message)
+	}
+	sort.Strings(summary.LintWarnings)
+	return summary
+}
+
+// resolveSecretFiles finds the stored resource backing each compose secret
+// file among the resource paths of the container version that declared it
+func resolveSecretFiles(service *models.Service, secretFiles []SecretFile) ([]PackageFile, error) {
+	resourcePaths := make(map[string][]string) // module name -> resource paths
+	for _, sc := range service.GetEnabledContainers() {
+		var paths []string
+		if len(sc.ContainerVersion.ResourcePaths) > 0 {
+			if err := json.Unmarshal(sc.ContainerVersion.ResourcePaths, &paths); err != nil {
+				return nil, fmt.Errorf("invalid resource paths for container '%s': %w", sc.Container.Name, err)
+			}
+		}
+		resourcePaths[sc.Container.Name] = paths
+	}
+
+	var files []PackageFile
+	for _, secretFile := range secretFiles {
+		source := normalizeResourcePath(secretFile.Source)
+		storageKey := ""
+		for _, resourcePath := range resourcePaths[secretFile.Module] {
+			normalized := normalizeResourcePath(resourcePath)
+			if normalized == source || strings.HasSuffix(normalized, "/"+source) {
+				storageKey = resourcePath
+				break
+			}
+		}
+		if storageKey == "" {
+			return nil, fmt.Errorf("secret file '%s' of module '%s' is not among its resource paths", secretFile.Source, secretFile.Module)
+		}
+		files = append(files, PackageFile{Path: secretFile.Path, StorageKey: storageKey})
+	}
+
+	return files, nil
+}
+
+// GetBuildSummary returns the BUILD_SUMMARY.md report of a completed
+// service build
+func (s *BuildService) GetBuildSummary(serviceID uint, buildID uuid.UUID) (string, error) {
+	var build models.Build
+	if err := s.db.Where("id = ? AND service_id = ?", buildID, serviceID).First(&build).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", fmt.Errorf("build not found")
+		}
+		return "", fmt.Errorf("failed to get build: %w", err)
+	}
+	if build.SummaryMarkdown == "" {
+		return "", fmt.Errorf("build summary not available")
+	}
+
+	return build.SummaryMarkdown, nil
+}
+
+// GetBuildLogs returns the logs of a service build ordered by time
+func (s *BuildService) GetBuildLogs(serviceID uint, buildID uuid.UUID) ([]models.BuildLog, error) {
+	var build models.Build
+	if err := s.db.Where("id = ? AND service_id = ?", buildID, serviceID).First(&build).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("build not found")
+		}
+		return nil, fmt.Errorf("failed to get build: %w", err)
+	}
+
+	var logs []models.BuildLog
+	if err := s.db.Where("build_id = ?", build.ID).Order("timestamp ASC, id ASC").Find(&logs).Error; err != nil {
+		return nil, fmt.Errorf("failed to get build logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// log records a build log entry. Failures to write logs never fail the build.
+func (s *BuildService) log(build *models.Build, stage, level, message string) {
+	s.db.Create(&models.BuildLog{
+		BuildID:   build.ID,
+		RequestID: build.RequestID,
+		Stage:     stage,
+		Level:     level,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,949 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/burndler/burndler/internal/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func setupBuildTestDB(t *testing.T) *gorm.DB {
+	db := setupServiceTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.Build{}, &models.BuildLog{}))
+	return db
+}
+
+// createBuildableService creates a service with one enabled container and a queued build
+func createBuildableService(t *testing.T, db *gorm.DB, compose string) (*models.Service, *models.Build) {
+	user := &models.User{Email: "builder@example.com", Name: "builder", Role: "Developer"}
+	assert.NoError(t, db.Create(user).Error)
+
+	container := &models.Container{Name: "web"}
+	assert.NoError(t, db.Create(container).Error)
+	version := &models.ContainerVersion{ContainerID: container.ID, Version: "v1.0.0", ComposeContent: compose}
+	assert.NoError(t, db.Create(version).Error)
+
+	service := &models.Service{Name: "shop", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(service).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{
+		ServiceID:          service.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+	}).Error)
+
+	build := &models.Build{Name: service.Name, ServiceID: &service.ID, UserID: user.ID, Status: "queued"}
+	assert.NoError(t, db.Create(build).Error)
+
+	return service, build
+}
+
+func buildLogStages(logs []models.BuildLog) []string {
+	var stages []string
+	for _, log := range logs {
+		if len(stages) == 0 || stages[len(stages)-1] != log.Stage {
+			stages = append(stages, log.Stage)
+		}
+	}
+	return stages
+}
+
+func TestBuildService_ExecuteBuild_Completed(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	err := buildService.ExecuteBuild(context.Background(), build.ID)
+	assert.NoError(t, err)
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "completed", updated.Status)
+	assert.Equal(t, 100, updated.Progress)
+	assert.Contains(t, updated.ComposeYAML, "web__app")
+	assert.NotEmpty(t, updated.DownloadURL)
+
+	logs, err := buildService.GetBuildLogs(service.ID, build.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{BuildStageValidation, BuildStageMerge, BuildStageLint, BuildStagePackage}, buildLogStages(logs))
+	for _, log := range logs {
+		assert.False(t, log.IsError(), "unexpected error log: %s", log.Message)
+	}
+}
+
+func TestBuildService_ExecuteBuild_PackageChecksum(t *testing.T) {
+	db := setupBuildTestDB(t)
+	mockStorage := &MockStorage{}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(mockStorage))
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	assert.NoError(t, buildService.ExecuteBuild(context.Background(), build.ID))
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	checksum := sha256.Sum256(mockStorage.Uploaded)
+	assert.Equal(t, hex.EncodeToString(checksum[:]), updated.PackageChecksum)
+	assert.Equal(t, int64(len(mockStorage.Uploaded)), updated.PackageSize)
+	assert.NotZero(t, updated.PackageSize)
+}
+
+func TestBuildService_ExecuteBuild_Summary(t *testing.T) {
+	db := setupBuildTestDB(t)
+	mockStorage := &MockStorage{}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(mockStorage))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	cache := &models.Container{Name: "cache"}
+	assert.NoError(t, db.Create(cache).Error)
+	cacheVersion := &models.ContainerVersion{ContainerID: cache.ID, Version: "v7.2.0",
+		ComposeContent: "services:\n  redis:\n    image: redis@sha256:0000000000000000000000000000000000000000000000000000000000000000"}
+	assert.NoError(t, db.Create(cacheVersion).Error)
+	assert.NoError(t, db.Create(&models.ServiceContainer{ServiceID: service.ID, ContainerID: cache.ID,
+		ContainerVersionID: cacheVersion.ID, Order: 1, Enabled: true}).Error)
+
+	_, err := buildService.GetBuildSummary(service.ID, build.ID)
+	assert.EqualError(t, err, "build summary not available")
+
+	assert.NoError(t, buildService.ExecuteBuild(context.Background(), build.ID))
+
+	summary, err := buildService.GetBuildSummary(service.ID, build.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, summary, "- Service: shop\n")
+	assert.Contains(t, summary, "## Containers (2)\n")
+	assert.Contains(t, summary, "| web | v1.0.0 |\n| cache | v7.2.0 |\n")
+	assert.Contains(t, summary, "## Files (7)\n")
+	assert.Contains(t, summary, "- compose/docker-compose.yaml\n")
+	assert.Contains(t, summary, "- BUILD_SUMMARY.md\n")
+	assert.Contains(t, summary, "## Assets (0 embedded, 0 download)\n")
+	assert.Contains(t, summary, "## Lint Warnings (1)\n\n- Service 'web__app' image 'nginx:1.25.3' doesn't use SHA256 digest\n")
+
+	files := packageFiles(t, mockStorage.Uploaded)
+	assert.Equal(t, summary, files[BuildSummaryFile], "the package ships the same summary")
+
+	_, err = buildService.GetBuildSummary(service.ID+1, build.ID)
+	assert.EqualError(t, err, "build not found")
+}
+
+func TestBuildService_QueueBuildWithOptions_ConcurrentIdempotencyKey(t *testing.T) {
+	db := setupBuildTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// Concurrent requests must share the single in-memory database connection
+	sqlDB.SetMaxOpenConns(1)
+
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, _ := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	assert.NoError(t, db.Where("service_id = ?", service.ID).Delete(&models.Build{}).Error)
+
+	// Hold each create until every request has passed the idempotency
+	// lookup, so they all race to insert
+	const requests = 4
+	var arrived sync.WaitGroup
+	arrived.Add(requests)
+	assert.NoError(t, db.Callback().Create().Before("gorm:begin_transaction").Register("test:barrier", func(tx *gorm.DB) {
+		if tx.Statement.Table != "builds" {
+			return
+		}
+		arrived.Done()
+		arrived.Wait()
+	}))
+
+	start := make(chan struct{})
+	ids := make(chan uuid.UUID, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			build, _, err := buildService.QueueBuildWithOptions(service.ID, service.UserID, QueueBuildOptions{IdempotencyKey: "double-click"})
+			if assert.NoError(t, err) {
+				ids <- build.ID
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	close(ids)
+
+	var first uuid.UUID
+	for id := range ids {
+		if first == uuid.Nil {
+			first = id
+		}
+		assert.Equal(t, first, id, "every request gets the same build")
+	}
+
+	var count int64
+	assert.NoError(t, db.Model(&models.Build{}).Where("service_id = ?", service.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestBuildService_QueueBuild_Archived(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, _ := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	assert.NoError(t, db.Model(service).Update("archived", true).Error)
+
+	_, err := buildService.QueueBuild(service.ID, service.UserID)
+	assert.EqualError(t, err, "service is archived")
+
+	assert.NoError(t, db.Model(service).Update("archived", false).Error)
+	_, err = buildService.QueueBuild(service.ID, service.UserID)
+	assert.NoError(t, err)
+}
+
+func TestBuildService_ExecuteBuild_Failed(t *testing.T) {
+	db := setupBuildTestDB(t)
+	storage := &MockStorage{UploadError: errors.New("bucket unavailable")}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	err := buildService.ExecuteBuild(context.Background(), build.ID)
+	assert.Error(t, err)
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "failed", updated.Status)
+	assert.Contains(t, updated.Error, "bucket unavailable")
+	assert.Equal(t, buildStageProgress[BuildStageLint], updated.Progress)
+	assert.NotNil(t, updated.CompletedAt)
+
+	logs, err := buildService.GetBuildLogs(service.ID, build.ID)
+	assert.NoError(t, err)
+	last := logs[len(logs)-1]
+	assert.Equal(t, BuildStagePackage, last.Stage)
+	assert.Equal(t, "error", last.Level)
+	assert.Equal(t, updated.Error, last.Message)
+}
+
+func TestBuildService_ExecuteBuild_Callback(t *testing.T) {
+	tests := []struct {
+		name       string
+		storage    *MockStorage
+		wantStatus string
+	}{
+		{name: "completed", storage: &MockStorage{}, wantStatus: "completed"},
+		{name: "failed", storage: &MockStorage{UploadError: errors.New("bucket unavailable")}, wantStatus: "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make(chan *http.Request, 2)
+			bodies := make(chan []byte, 2)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				requests <- r
+				bodies <- body
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			db := setupBuildTestDB(t)
+			buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(tt.storage))
+			buildService.SetCallbackSecret("test-secret")
+			// The test server listens on loopback
+			buildService.SetCallbackAllowPrivate(true)
+			_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+			assert.NoError(t, db.Model(build).Update("callback_url", server.URL).Error)
+
+			buildService.ExecuteBuild(context.Background(), build.ID)
+
+			var updated models.Build
+			assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+			assert.Equal(t, tt.wantStatus, updated.Status)
+
+			// Callbacks are delivered in the background
+			var r *http.Request
+			select {
+			case r = <-requests:
+			case <-time.After(2 * time.Second):
+				t.Fatal("callback was not delivered")
+			}
+			body := <-bodies
+			assert.Equal(t, "build.finished", r.Header.Get(WebhookEventHeader))
+			assert.Equal(t, SignWebhookPayload("test-secret", body), r.Header.Get(WebhookSignatureHeader))
+
+			var payload BuildFinishedEvent
+			assert.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, build.ID.String(), payload.BuildID)
+			assert.Equal(t, build.ServiceID, payload.ServiceID)
+			assert.Equal(t, tt.wantStatus, payload.Status)
+			assert.Equal(t, updated.Error, payload.Error)
+			assert.Equal(t, updated.DownloadURL, payload.DownloadURL)
+			assert.Equal(t, updated.PackageChecksum, payload.PackageChecksum)
+			if tt.wantStatus == "completed" {
+				assert.NotEmpty(t, payload.DownloadURL)
+				assert.NotEmpty(t, payload.PackageChecksum)
+			} else {
+				assert.Contains(t, payload.Error, "bucket unavailable")
+			}
+		})
+	}
+}
+
+func TestBuildService_ExecuteBuild_CallbackFailureIgnored(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	buildService.SetCallbackAllowPrivate(true)
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	assert.NoError(t, db.Model(build).Update("callback_url", server.URL).Error)
+
+	assert.NoError(t, buildService.ExecuteBuild(context.Background(), build.ID))
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "completed", updated.Status)
+}
+
+func TestBuildService_ValidateCallbackURL(t *testing.T) {
+	buildService := NewBuildService(setupBuildTestDB(t), NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{name: "public address", url: "https://93.184.216.34/hooks/build"},
+		{name: "not http", url: "ftp://93.184.216.34/hook", wantErr: "callback_url must be an absolute http or https URL"},
+		{name: "relative", url: "/hooks/build", wantErr: "callback_url must be an absolute http or https URL"},
+		{name: "localhost", url: "http://localhost:8080/hook", wantErr: "callback_url must not point to a loopback, private or link-local address"},
+		{name: "loopback", url: "http://127.0.0.1/hook", wantErr: "callback_url must not point to a loopback, private or link-local address"},
+		{name: "IPv6 loopback", url: "http://[::1]/hook", wantErr: "callback_url must not point to a loopback, private or link-local address"},
+		{name: "metadata service", url: "http://169.254.169.254/latest/meta-data", wantErr: "callback_url must not point to a loopback, private or link-local address"},
+		{name: "private network", url: "http://10.0.0.5/hook", wantErr: "callback_url must not point to a loopback, private or link-local address"},
+		{name: "private network 192.168", url: "https://192.168.1.10/hook", wantErr: "callback_url must not point to a loopback, private or link-local address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := buildService.ValidateCallbackURL(context.Background(), tt.url)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	// Private addresses can be allowed explicitly
+	buildService.SetCallbackAllowPrivate(true)
+	assert.NoError(t, buildService.ValidateCallbackURL(context.Background(), "http://127.0.0.1/hook"))
+}
+
+func TestWebhookNotifier_RestrictToPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, "")
+	assert.NoError(t, notifier.Send(context.Background(), "test", map[string]string{}))
+
+	notifier.RestrictToPublicAddresses()
+	err := notifier.Send(context.Background(), "test", map[string]string{})
+	assert.ErrorContains(t, err, "is not allowed")
+}
+
+func TestBuildService_CleanupExpiredBuilds(t *testing.T) {
+	db := setupBuildTestDB(t)
+	mockStorage := &MockStorage{}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(mockStorage))
+	buildService.SetBuildRetentionDays(7)
+	service, _ := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	now := time.Now()
+	// addBuild creates a build with a stored package and one log entry
+	addBuild := func(name, status string, age time.Duration) *models.Build {
+		build := &models.Build{Name: name, ServiceID: &service.ID, UserID: service.UserID, Status: status,
+			PackageKey: "packages/" + name + ".tar.gz"}
+		assert.NoError(t, db.Create(build).Error)
+		assert.NoError(t, db.Model(build).Update("created_at", now.Add(-age)).Error)
+		assert.NoError(t, db.Create(&models.BuildLog{BuildID: build.ID, Stage: BuildStagePackage, Level: "info",
+			Message: "Build completed", Timestamp: now}).Error)
+		return build
+	}
+
+	expired := addBuild("expired", "completed", 8*24*time.Hour)
+	expiredFailed := addBuild("expired-failed", "failed", 30*24*time.Hour)
+	recent := addBuild("recent", "completed", 6*24*time.Hour)
+	running := addBuild("running", "building", 30*24*time.Hour)
+
+	removed, err := buildService.CleanupExpiredBuilds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.ElementsMatch(t, []string{expired.PackageKey, expiredFailed.PackageKey}, mockStorage.Deleted)
+
+	var remaining []models.Build
+	assert.NoError(t, db.Unscoped().Where("name <> ?", service.Name).Find(&remaining).Error)
+	var names []string
+	for _, build := range remaining {
+		names = append(names, build.Name)
+	}
+	assert.ElementsMatch(t, []string{recent.Name, running.Name}, names)
+
+	var logCount int64
+	assert.NoError(t, db.Model(&models.BuildLog{}).Where("build_id IN ?", []uuid.UUID{expired.ID, expiredFailed.ID}).Count(&logCount).Error)
+	assert.Zero(t, logCount)
+
+	// A service override shortens the window for its builds
+	days := 5
+	assert.NoError(t, db.Model(service).Update("build_retention_days", days).Error)
+	removed, err = buildService.CleanupExpiredBuilds(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Contains(t, mockStorage.Deleted, recent.PackageKey)
+}
+
+func TestBuildService_CleanupExpiredBuilds_DeleteFailure(t *testing.T) {
+	db := setupBuildTestDB(t)
+	mockStorage := &MockStorage{DeleteError: errors.New("bucket unavailable")}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(mockStorage))
+	buildService.SetBuildRetentionDays(7)
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	assert.NoError(t, db.Model(build).Updates(map[string]interface{}{
+		"status":      "completed",
+		"package_key": "packages/shop.tar.gz",
+		"created_at":  time.Now().AddDate(0, 0, -8),
+	}).Error)
+
+	removed, err := buildService.CleanupExpiredBuilds(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, removed)
+	assert.NoError(t, db.First(&models.Build{}, "id = ?", build.ID).Error, "build is kept so cleanup can retry")
+}
+
+// blockingStorage holds every package upload until release is closed
+type blockingStorage struct {
+	*MockStorage
+	release chan struct{}
+}
+
+func (s *blockingStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64) (string, error) {
+	<-s.release
+	return "http://mock-storage/" + key, nil
+}
+
+func TestBuildService_ExecuteBuildAsync_WorkerLimit(t *testing.T) {
+	db := setupBuildTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// Background builds must share the single in-memory database connection
+	sqlDB.SetMaxOpenConns(1)
+
+	storage := &blockingStorage{MockStorage: &MockStorage{}, release: make(chan struct{})}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	buildService.SetWorkerCount(1)
+	service, first := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	second, err := buildService.QueueBuild(service.ID, service.UserID)
+	assert.NoError(t, err)
+	third, err := buildService.QueueBuild(service.ID, service.UserID)
+	assert.NoError(t, err)
+
+	status := func(build *models.Build) string {
+		var current models.Build
+		assert.NoError(t, db.First(&current, "id = ?", build.ID).Error)
+		return current.Status
+	}
+
+	for _, build := range []*models.Build{first, second, third} {
+		buildService.ExecuteBuildAsync(build.ID)
+	}
+
+	assert.Eventually(t, func() bool { return status(first) == "building" }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "queued", status(second))
+	assert.Equal(t, "queued", status(third))
+	assert.Equal(t, 0, buildService.QueuePosition(first.ID))
+	assert.Equal(t, 1, buildService.QueuePosition(second.ID))
+	assert.Equal(t, 2, buildService.QueuePosition(third.ID))
+
+	close(storage.release)
+	for _, build := range []*models.Build{first, second, third} {
+		build := build
+		assert.Eventually(t, func() bool { return status(build) == "completed" }, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, 0, buildService.QueuePosition(build.ID))
+	}
+}
+
+func TestBuildService_RunBuildAsync_SharesWorkers(t *testing.T) {
+	db := setupBuildTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// Background builds must share the single in-memory database connection
+	sqlDB.SetMaxOpenConns(1)
+
+	storage := &blockingStorage{MockStorage: &MockStorage{}, release: make(chan struct{})}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	buildService.SetWorkerCount(1)
+	_, running := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	buildService.ExecuteBuildAsync(running.ID)
+	assert.Eventually(t, func() bool {
+		var current models.Build
+		return db.First(&current, "id = ?", running.ID).Error == nil && current.Status == "building"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	jobID := uuid.New()
+	ran := make(chan struct{})
+	buildService.RunBuildAsync(jobID, func(ctx context.Context) error {
+		close(ran)
+		return nil
+	})
+	assert.Equal(t, 1, buildService.QueuePosition(jobID), "the job waits for the busy worker")
+
+	close(storage.release)
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not run once the worker was free")
+	}
+}
+
+func TestBuildService_BuildTimeout(t *testing.T) {
+	db := setupBuildTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// Background builds must share the single in-memory database connection
+	sqlDB.SetMaxOpenConns(1)
+
+	storage := &blockingStorage{MockStorage: &MockStorage{}, release: make(chan struct{})}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	buildService.SetWorkerCount(1)
+	buildService.SetBuildTimeout(50 * time.Millisecond)
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	deadlines := make(chan bool, 1)
+	buildService.RunBuildAsync(uuid.New(), func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		deadlines <- ok
+		return nil
+	})
+	assert.True(t, <-deadlines, "jobs run with the build timeout")
+
+	buildService.ExecuteBuildAsync(build.ID)
+	time.Sleep(100 * time.Millisecond)
+	close(storage.release)
+
+	var current models.Build
+	assert.Eventually(t, func() bool {
+		return db.First(&current, "id = ?", build.ID).Error == nil && current.Status == "cancelled"
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded.Error(), current.Error)
+}
+
+func TestBuildService_ForceFailBuild_QueuedAndRunning(t *testing.T) {
+	db := setupBuildTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// Background builds must share the single in-memory database connection
+	sqlDB.SetMaxOpenConns(1)
+
+	storage := &blockingStorage{MockStorage: &MockStorage{}, release: make(chan struct{})}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	buildService.SetWorkerCount(1)
+	service, running := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	pending, err := buildService.QueueBuild(service.ID, service.UserID)
+	assert.NoError(t, err)
+
+	load := func(build *models.Build) models.Build {
+		var current models.Build
+		assert.NoError(t, db.First(&current, "id = ?", build.ID).Error)
+		return current
+	}
+
+	buildService.ExecuteBuildAsync(running.ID)
+	buildService.ExecuteBuildAsync(pending.ID)
+	assert.Eventually(t, func() bool { return load(running).Status == "building" }, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, buildService.QueuePosition(pending.ID))
+
+	_, err = buildService.ForceFailBuild(pending.ID, "no longer needed")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, buildService.QueuePosition(pending.ID))
+	_, err = buildService.ForceFailBuild(running.ID, "worker stuck on upload")
+	assert.NoError(t, err)
+
+	// The running worker finishes its upload but must not overwrite the
+	// force-fail, and the pending build never starts
+	close(storage.release)
+	assert.Eventually(t, func() bool {
+		buildService.queueMu.Lock()
+		defer buildService.queueMu.Unlock()
+		return len(buildService.running) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	for build, reason := range map[*models.Build]string{running: "worker stuck on upload", pending: "no longer needed"} {
+		current := load(build)
+		assert.Equal(t, "failed", current.Status)
+		assert.Equal(t, reason, current.Error)
+		assert.Empty(t, current.DownloadURL)
+	}
+
+	// A build that is not queued is never executed
+	assert.ErrorIs(t, buildService.ExecuteBuild(context.Background(), pending.ID), errBuildNotQueued)
+}
+
+// cancellingStorage cancels the build context while the package is uploading
+type cancellingStorage struct {
+	*MockStorage
+	cancel context.CancelFunc
+}
+
+func (s *cancellingStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64) (string, error) {
+	s.cancel()
+	return "", ctx.Err()
+}
+
+func TestBuildService_ExecuteBuild_Cancelled(t *testing.T) {
+	db := setupBuildTestDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	storage := &cancellingStorage{MockStorage: &MockStorage{}, cancel: cancel}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	err := buildService.ExecuteBuild(ctx, build.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "cancelled", updated.Status)
+	assert.True(t, updated.IsCancelled())
+	assert.Empty(t, updated.DownloadURL)
+	assert.NotNil(t, updated.CompletedAt)
+
+	logs, err := buildService.GetBuildLogs(service.ID, build.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{BuildStageValidation, BuildStageMerge, BuildStageLint, BuildStagePackage}, buildLogStages(logs))
+	for _, log := range logs {
+		assert.False(t, log.IsError(), "unexpected error log: %s", log.Message)
+	}
+}
+
+// forceFailingStorage force-fails the build during its upload and then
+// fails the upload
+type forceFailingStorage struct {
+	*MockStorage
+	buildService *BuildService
+	buildID      uuid.UUID
+}
+
+func (s *forceFailingStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64) (string, error) {
+	if _, err := s.buildService.ForceFailBuild(s.buildID, "stopped by admin"); err != nil {
+		return "", err
+	}
+	return "", errors.New("bucket unavailable")
+}
+
+func TestBuildService_ExecuteBuild_FailureAfterForceFail(t *testing.T) {
+	db := setupBuildTestDB(t)
+	storage := &forceFailingStorage{MockStorage: &MockStorage{}}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	storage.buildService = buildService
+	storage.buildID = build.ID
+
+	err := buildService.ExecuteBuild(context.Background(), build.ID)
+	assert.ErrorIs(t, err, errBuildNotRunning)
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "failed", updated.Status)
+	assert.Equal(t, "stopped by admin", updated.Error, "the worker's failure does not overwrite the force-fail")
+}
+
+func TestBuildService_ExecuteBuild_CancelledBeforeMerge(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := buildService.ExecuteBuild(ctx, build.ID)
+	assert.Equal(t, context.Canceled, err)
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "cancelled", updated.Status)
+	assert.Empty(t, updated.ComposeYAML)
+
+	logs, err := buildService.GetBuildLogs(service.ID, build.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{BuildStageValidation, BuildStageMerge}, buildLogStages(logs))
+}
+
+func TestBuildService_ExecuteBuild_LintFailureKeepsProgress(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3\n    build: .")
+
+	err := buildService.ExecuteBuild(context.Background(), build.ID)
+	assert.Error(t, err)
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "failed", updated.Status)
+	assert.Equal(t, buildStageProgress[BuildStageMerge], updated.Progress)
+}
+
+func TestBuildService_DryRun(t *testing.T) {
+	db := setupBuildTestDB(t)
+	storage := &MockStorage{}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	compose, err := buildService.DryRun(context.Background(), service.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, compose, "web__app")
+	assert.False(t, storage.UploadCalled)
+
+	// The queued build is untouched and nothing is logged
+	var unchanged models.Build
+	assert.NoError(t, db.First(&unchanged, "id = ?", build.ID).Error)
+	assert.Equal(t, "queued", unchanged.Status)
+	var logCount int64
+	assert.NoError(t, db.Model(&models.BuildLog{}).Count(&logCount).Error)
+	assert.Zero(t, logCount)
+
+	_, err = buildService.DryRun(context.Background(), service.ID+1)
+	assert.EqualError(t, err, "service not found")
+}
+
+func TestBuildService_DefaultLogging(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, _ := createBuildableService(t, db, `services:
+  app:
+    image: nginx:1.25.3
+  worker:
+    image: busybox:1.36
+    logging:
+      driver: syslog
+`)
+
+	compose, err := buildService.DryRun(context.Background(), service.ID)
+	assert.NoError(t, err)
+	assert.NotContains(t, compose, "fluentd")
+
+	buildService.SetDefaultLogging(&LoggingConfig{Driver: "fluentd", Options: map[string]string{"fluentd-address": "logs:24224"}})
+	compose, err = buildService.DryRun(context.Background(), service.ID)
+	assert.NoError(t, err)
+
+	var parsed struct {
+		Services map[string]struct {
+			Logging struct {
+				Driver  string            `yaml:"driver"`
+				Options map[string]string `yaml:"options"`
+			} `yaml:"logging"`
+		} `yaml:"services"`
+	}
+	assert.NoError(t, yaml.Unmarshal([]byte(compose), &parsed))
+	assert.Equal(t, "fluentd", parsed.Services["web__app"].Logging.Driver)
+	assert.Equal(t, "logs:24224", parsed.Services["web__app"].Logging.Options["fluentd-address"])
+	assert.Equal(t, "syslog", parsed.Services["web__worker"].Logging.Driver, "a declared driver is kept")
+}
+
+func TestBuildService_ComposeInterpolation(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, _ := createBuildableService(t, db, `services:
+  app:
+    image: nginx:1.25.3
+    ports:
+      - "${PORT:-8080}:80"
+    environment:
+      LOG_LEVEL: ${LOG_LEVEL:-info}
+`)
+	service.Variables = datatypes.JSON(`{"LOG_LEVEL": "debug"}`)
+	assert.NoError(t, db.Save(service).Error)
+
+	compose, err := buildService.DryRun(context.Background(), service.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, compose, "${PORT:-8080}:80", "inline defaults are left for runtime when disabled")
+
+	buildService.SetComposeInterpolation(true)
+	compose, err = buildService.DryRun(context.Background(), service.ID)
+	assert.NoError(t, err)
+	assert.Contains(t, compose, "8080:80")
+	assert.NotContains(t, compose, "${PORT")
+	assert.Contains(t, compose, "LOG_LEVEL: debug", "service variables win over the inline default")
+}
+
+func TestBuildService_DryRun_LintFailure(t *testing.T) {
+	db := setupBuildTestDB(t)
+	storage := &MockStorage{}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	service, _ := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3\n    build: .")
+
+	_, err := buildService.DryRun(context.Background(), service.ID)
+	assert.EqualError(t, err, "compose validation failed with 1 errors")
+	assert.False(t, storage.UploadCalled)
+}
+
+func TestBuildService_ExecuteBuild_SecretFiles(t *testing.T) {
+	compose := "services:\n  app:\n    image: nginx:1.25.3\n    secrets: [api_key]\nsecrets:\n  api_key:\n    file: ./secrets/api_key.txt"
+
+	tests := []struct {
+		name           string
+		resourcePaths  string
+		expectedStatus string
+	}{
+		{name: "secret file among resources", resourcePaths: `["containers/web/v1.0.0/secrets/api_key.txt"]`, expectedStatus: "completed"},
+		{name: "secret file missing", resourcePaths: `["containers/web/v1.0.0/config.yaml"]`, expectedStatus: "failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupBuildTestDB(t)
+			storage := &MockStorage{}
+			buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+			_, build := createBuildableService(t, db, compose)
+			assert.NoError(t, db.Model(&models.ContainerVersion{}).Where("1 = 1").
+				Update("resource_paths", tt.resourcePaths).Error)
+
+			_ = buildService.ExecuteBuild(context.Background(), build.ID)
+
+			var updated models.Build
+			assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+			assert.Equal(t, tt.expectedStatus, updated.Status)
+
+			if tt.expectedStatus == "completed" {
+				assert.Contains(t, updated.ComposeYAML, "../secrets/web/secrets/api_key.txt")
+				files := packageFiles(t, storage.Uploaded)
+				assert.Equal(t, "mock content", files["secrets/web/secrets/api_key.txt"])
+			} else {
+				assert.Contains(t, updated.Error, "secret file './secrets/api_key.txt' of module 'web'")
+				assert.False(t, storage.UploadCalled)
+			}
+		})
+	}
+}
+
+func TestBuildService_ForceFailBuild(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+	assert.NoError(t, db.Model(build).Update("status", "building").Error)
+
+	failed, err := buildService.ForceFailBuild(build.ID, "worker stuck on image pull")
+	assert.NoError(t, err)
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, "worker stuck on image pull", failed.Error)
+	assert.NotNil(t, failed.CompletedAt)
+
+	logs, err := buildService.GetBuildLogs(service.ID, build.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, BuildStageAdmin, logs[len(logs)-1].Stage)
+	assert.True(t, logs[len(logs)-1].IsError())
+
+	_, err = buildService.ForceFailBuild(build.ID, "again")
+	assert.EqualError(t, err, "build is not in progress")
+
+	_, err = buildService.ForceFailBuild(uuid.New(), "missing")
+	assert.EqualError(t, err, "build not found")
+}
+
+func TestBuildService_RetryBuild(t *testing.T) {
+	db := setupBuildTestDB(t)
+	storage := &MockStorage{UploadError: errors.New("bucket unavailable")}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	_, err := buildService.RetryBuild(build.ID, "")
+	assert.EqualError(t, err, "only failed or cancelled builds can be retried")
+
+	assert.Error(t, buildService.ExecuteBuild(context.Background(), build.ID))
+
+	retried, err := buildService.RetryBuild(build.ID, "req-retry")
+	assert.NoError(t, err)
+	assert.Equal(t, "queued", retried.Status)
+	assert.Equal(t, "req-retry", retried.RequestID)
+	assert.Zero(t, retried.Progress)
+	assert.Empty(t, retried.Error)
+
+	// The storage recovered, so the retried build completes
+	storage.UploadError = nil
+	assert.NoError(t, buildService.ExecuteBuild(context.Background(), build.ID))
+
+	var updated models.Build
+	assert.NoError(t, db.First(&updated, "id = ?", build.ID).Error)
+	assert.Equal(t, "completed", updated.Status)
+	assert.NotEmpty(t, updated.DownloadURL)
+
+	// Log entries written since the retry carry the retrying request's ID
+	var retryLogs []models.BuildLog
+	assert.NoError(t, db.Where("build_id = ? AND request_id = ?", build.ID, "req-retry").Find(&retryLogs).Error)
+	assert.NotEmpty(t, retryLogs)
+	assert.Equal(t, "Build retried", retryLogs[0].Message)
+	assert.Equal(t, "Build completed", retryLogs[len(retryLogs)-1].Message)
+}
+
+func TestBuildService_RetryBuild_WhileWorkerRunning(t *testing.T) {
+	db := setupBuildTestDB(t)
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	// Background builds must share the single in-memory database connection
+	sqlDB.SetMaxOpenConns(1)
+
+	storage := &blockingStorage{MockStorage: &MockStorage{}, release: make(chan struct{})}
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(storage))
+	_, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	workerDone := func() bool {
+		buildService.queueMu.Lock()
+		defer buildService.queueMu.Unlock()
+		return !buildService.scheduledLocked(build.ID)
+	}
+
+	buildService.ExecuteBuildAsync(build.ID)
+	assert.Eventually(t, func() bool {
+		var current models.Build
+		assert.NoError(t, db.First(&current, "id = ?", build.ID).Error)
+		return current.Status == "building"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	_, err = buildService.ForceFailBuild(build.ID, "worker stuck on upload")
+	assert.NoError(t, err)
+
+	// The force-failed build's worker still holds it
+	_, err = buildService.RetryBuild(build.ID, "")
+	assert.EqualError(t, err, "build is still being processed")
+
+	close(storage.release)
+	assert.Eventually(t, workerDone, 2*time.Second, 10*time.Millisecond)
+
+	retried, err := buildService.RetryBuild(build.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "queued", retried.Status)
+
+	// A second retry of the same build is refused
+	_, err = buildService.RetryBuild(build.ID, "")
+	assert.EqualError(t, err, "only failed or cancelled builds can be retried")
+}
+
+func TestBuildService_GetBuildLogs_WrongService(t *testing.T) {
+	db := setupBuildTestDB(t)
+	buildService := NewBuildService(db, NewMerger(), NewLinter(), NewPackager(&MockStorage{}))
+	service, build := createBuildableService(t, db, "services:\n  app:\n    image: nginx:1.25.3")
+
+	_, err := buildService.GetBuildLogs(service.ID+1, build.ID)
+	assert.EqualError(t, err, "build not found")
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BuildSummaryFile is the package path of the build summary report
+const BuildSummaryFile = "BUILD_SUMMARY.md"
+
+// BuildSummary describes what went into a service build. The packager fills
+// in the files and assets it writes and renders it as BUILD_SUMMARY.md.
+type BuildSummary struct {
+	Service        string
+	BuildID        string
+	Containers     []SummaryContainer
+	Files          []string
+	EmbeddedAssets []string
+	DownloadAssets []DownloadAsset
+	LintWarnings   []string
+}
+
+// SummaryContainer is a container version included in a build
+type SummaryContainer struct {
+	Name    string
+	Version string
+}
+
+// Markdown renders the summary as a Markdown report
+func (s *BuildSummary) Markdown() string {
+	var b strings.Builder
+
+	b.WriteString("# Build Summary\n\n")
+	fmt.Fprintf(&b, "- Service: %s\n", s.Service)
+	fmt.Fprintf(&b, "- Build: %s\n", s.BuildID)
+
+	fmt.Fprintf(&b, "\n## Containers (%d)\n\n", len(s.Containers))
+	if len(s.Containers) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString("| Container | Version |\n|-----------|---------|\n")
+		for _, container := range s.Containers {
+			fmt.Fprintf(&b, "| %s | %s |\n", container.Name, container.Version)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Files (%d)\n\n", len(s.Files))
+	writeSummaryList(&b, s.Files)
+
+	fmt.Fprintf(&b, "\n## Assets (%d embedded, %d download)\n\n", len(s.EmbeddedAssets), len(s.DownloadAssets))
+	if len(s.EmbeddedAssets) == 0 && len(s.DownloadAssets) == 0 {
+		b.WriteString("None.\n")
+	}
+	for _, asset := range s.EmbeddedAssets {
+		fmt.Fprintf(&b, "- %s (embedded)\n", asset)
+	}
+	for _, asset := range s.DownloadAssets {
+		fmt.Fprintf(&b, "- %s (download from %s)\n", asset.Path, asset.URL)
+	}
+
+	fmt.Fprintf(&b, "\n## Lint Warnings (%d)\n\n", len(s.LintWarnings))
+	writeSummaryList(&b, s.LintWarnings)
+
+	return b.String()
+}
+
+// writeSummaryList writes items as a bullet list, or "None." when empty
+func writeSummaryList(b *strings.Builder, items []string) {
+	if len(items) == 0 {
+		b.WriteString("None.\n")
+		return
+	}
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
@@ -1,20 +1,34 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/burndler/burndler/internal/models"
 	"github.com/burndler/burndler/internal/storage"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
+// ErrNotFound is returned when a container has no version matching a lookup
+var ErrNotFound = errors.New("version not found")
+
 // ContainerService handles container management operations
 type ContainerService struct {
-	db      *gorm.DB
-	storage storage.Storage
-	linter  *Linter
+	db             *gorm.DB
+	storage        storage.Storage
+	linter         *Linter
+	publishWebhook *WebhookNotifier
+
+	// enforceVersionOrder rejects publishing a version below the latest
+	// published one
+	enforceVersionOrder bool
 }
 
 // NewContainerService creates a new ContainerService instance
@@ -26,6 +40,26 @@ func NewContainerService(db *gorm.DB, storage storage.Storage, linter *Linter) *
 	}
 }
 
+// SetPublishWebhook configures the webhook notified when a version is published
+func (s *ContainerService) SetPublishWebhook(notifier *WebhookNotifier) {
+	s.publishWebhook = notifier
+}
+
+// SetEnforceVersionOrder configures whether PublishVersion requires each
+// published version to be greater than the latest published version
+func (s *ContainerService) SetEnforceVersionOrder(enforce bool) {
+	s.enforceVersionOrder = enforce
+}
+
+// VersionPublishedEvent is the webhook payload sent when a version is published
+type VersionPublishedEvent struct {
+	Event       string    `json:"event"`
+	ContainerID uint      `json:"container_id"`
+	Container   string    `json:"container"`
+	Version     string    `json:"version"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
 // CreateContainerRequest represents the request to create a container
 type CreateContainerRequest struct {
 	Name        string `json:"name" binding:"required"`
@@ -49,6 +83,7 @@ type CreateVersionRequest struct {
 	Variables     map[string]interface{} `json:"variables"`
 	ResourcePaths []string               `json:"resource_paths"`
 	Dependencies  map[string]string      `json:"dependencies"`
+	Channel       string                 `json:"channel"` // defaults to stable
 }
 
 // UpdateVersionRequest represents the request to update a container version
@@ -57,6 +92,7 @@ type UpdateVersionRequest struct {
 	Variables     map[string]interface{} `json:"variables"`
 	ResourcePaths []string               `json:"resource_paths"`
 	Dependencies  map[string]string      `json:"dependencies"`
+	Channel       string                 `json:"channel"`
 }
 
 // ContainerFilters represents filters for listing containers
@@ -64,10 +100,49 @@ type ContainerFilters struct {
 	Active        *bool  `json:"active"`
 	Author        string `json:"author"`
 	PublishedOnly bool   `json:"published_only"`
+	Search        string `json:"search"` // case-insensitive match on name or description
+	Sort          string `json:"sort"`   // column name, prefixed with - for descending
 	Page          int    `json:"page"`
 	PageSize      int    `json:"page_size"`
 }
 
+// containerSortColumns maps the sort fields accepted by ListContainers to columns
+var containerSortColumns = map[string]string{
+	"name":       "containers.name",
+	"author":     "containers.author",
+	"created_at": "containers.created_at",
+	"updated_at": "containers.updated_at",
+}
+
+// likeEscaper escapes LIKE wildcards so user input matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes s for use in a LIKE pattern with ESCAPE '\'
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// containerOrder translates a sort field such as "name" or "-created_at" into
+// an ORDER BY clause, rejecting fields outside containerSortColumns
+func containerOrder(sort string) (string, error) {
+	if sort == "" {
+		sort = "name"
+	}
+
+	direction := "ASC"
+	field := sort
+	if strings.HasPrefix(sort, "-") {
+		direction = "DESC"
+		field = sort[1:]
+	}
+
+	column, ok := containerSortColumns[field]
+	if !ok {
+		return "", fmt.Errorf("invalid sort field '%s'", field)
+	}
+	return column + " " + direction, nil
+}
+
 // PaginatedResponse represents a paginated response
 type PaginatedResponse[T any] struct {
 	Data       []T   `json:"data"`
@@ -75,6 +150,20 @@ type PaginatedResponse[T any] struct {
 	Page       int   `json:"page"`
 	PageSize   int   `json:"page_size"`
 	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+}
+
+// newPaginatedResponse wraps one page of results with derived page metadata
+func newPaginatedResponse[T any](data []T, total int64, page, pageSize int) *PaginatedResponse[T] {
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	return &PaginatedResponse[T]{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}
 }
 
 // CreateContainer creates a new container
@@ -147,6 +236,11 @@ func (s *ContainerService) ListContainers(filters ContainerFilters) (*PaginatedR
 	var containers []models.Container
 	var total int64
 
+	order, err := containerOrder(filters.Sort)
+	if err != nil {
+		return nil, err
+	}
+
 	query := s.db.Model(&models.Container{})
 
 	// Apply filters
@@ -155,7 +249,12 @@ func (s *ContainerService) ListContainers(filters ContainerFilters) (*PaginatedR
 	}
 
 	if filters.Author != "" {
-		query = query.Where("author LIKE ?", "%"+filters.Author+"%")
+		query = query.Where(`author LIKE ? ESCAPE '\'`, "%"+escapeLike(filters.Author)+"%")
+	}
+
+	if filters.Search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filters.Search)) + "%"
+		query = query.Where(`LOWER(containers.name) LIKE ? ESCAPE '\' OR LOWER(containers.description) LIKE ? ESCAPE '\'`, pattern, pattern)
 	}
 
 	if filters.PublishedOnly {
@@ -183,19 +282,11 @@ func (s *ContainerService) ListContainers(filters ContainerFilters) (*PaginatedR
 	offset := (filters.Page - 1) * filters.PageSize
 
 	// Get paginated results
-	if err := query.Offset(offset).Limit(filters.PageSize).Order("created_at DESC").Find(&containers).Error; err != nil {
+	if err := query.Offset(offset).Limit(filters.PageSize).Order(order).Find(&containers).Error; err != nil {
 		return nil, fmt.Errorf("failed to list containers: %w", err)
 	}
 
-	totalPages := int((total + int64(filters.PageSize) - 1) / int64(filters.PageSize))
-
-	return &PaginatedResponse[models.Container]{
-		Data:       containers,
-		Total:      total,
-		Page:       filters.Page,
-		PageSize:   filters.PageSize,
-		TotalPages: totalPages,
-	}, nil
+	return newPaginatedResponse(containers, total, filters.Page, filters.PageSize), nil
 }
 
 // UpdateContainer updates an existing container
@@ -245,6 +336,28 @@ func (s *ContainerService) DeleteContainer(id uint) error {
 	return nil
 }
 
+// RestoreContainer undeletes a soft-deleted container. Live containers are
+// left untouched and reported as not deleted.
+func (s *ContainerService) RestoreContainer(id uint) (*models.Container, error) {
+	var container models.Container
+	if err := s.db.Unscoped().First(&container, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("container not found")
+		}
+		return nil, fmt.Errorf("failed to get container: %w", err)
+	}
+
+	if !container.DeletedAt.Valid {
+		return nil, fmt.Errorf("container is not deleted")
+	}
+
+	if err := s.db.Unscoped().Model(&container).Update("deleted_at", nil).Error; err != nil {
+		return nil, fmt.Errorf("failed to restore container: %w", err)
+	}
+
+	return s.GetContainer(id, false)
+}
+
 // CreateVersion creates a new version for a container
 func (s *ContainerService) CreateVersion(containerID uint, req CreateVersionRequest) (*models.ContainerVersion, error) {
 	// Verify container exists
@@ -253,15 +366,126 @@ func (s *ContainerService) CreateVersion(containerID uint, req CreateVersionRequ
 		return nil, err
 	}
 
+	if err := s.validateVersionRequest(container, req); err != nil {
+		return nil, err
+	}
+
+	version := newContainerVersion(containerID, req)
+	if err := s.db.Create(version).Error; err != nil {
+		return nil, fmt.Errorf("failed to create version: %w", err)
+	}
+
+	// Load the container relationship
+	if err := s.db.Preload("Container").First(version, version.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load version with container: %w", err)
+	}
+
+	return version, nil
+}
+
+// Bulk version import item statuses
+const (
+	BulkVersionCreated = "created"
+	BulkVersionInvalid = "invalid"
+	BulkVersionSkipped = "skipped" // valid, but not created because another item was invalid
+)
+
+// BulkVersionResult reports the outcome of one item of a bulk version import
+type BulkVersionResult struct {
+	Index            int                      `json:"index"`
+	Version          string                   `json:"version"`
+	Status           string                   `json:"status"`
+	Error            string                   `json:"error,omitempty"`
+	ContainerVersion *models.ContainerVersion `json:"container_version,omitempty"`
+}
+
+// CreateVersions creates several versions of a container at once. Every
+// item is validated first; if any is invalid nothing is created and the
+// results explain which items failed. Valid batches are created in a single
+// transaction.
+func (s *ContainerService) CreateVersions(containerID uint, reqs []CreateVersionRequest) ([]BulkVersionResult, error) {
+	container, err := s.GetContainer(containerID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BulkVersionResult, len(reqs))
+	seen := make(map[string]bool)
+	invalid := 0
+	for i, req := range reqs {
+		results[i] = BulkVersionResult{Index: i, Version: req.Version}
+
+		var err error
+		if _, semverErr := semver.NewVersion(req.Version); semverErr != nil {
+			err = fmt.Errorf("version '%s' is not a semantic version", req.Version)
+		} else if seen[req.Version] {
+			err = fmt.Errorf("version '%s' appears more than once in the batch", req.Version)
+		} else {
+			err = s.validateVersionRequest(container, req)
+		}
+		seen[req.Version] = true
+
+		if err != nil {
+			results[i].Status = BulkVersionInvalid
+			results[i].Error = err.Error()
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		for i := range results {
+			if results[i].Status == "" {
+				results[i].Status = BulkVersionSkipped
+			}
+		}
+		return results, fmt.Errorf("%d of %d versions are invalid", invalid, len(reqs))
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i, req := range reqs {
+			version := newContainerVersion(containerID, req)
+			if err := tx.Create(version).Error; err != nil {
+				return fmt.Errorf("failed to create version '%s': %w", req.Version, err)
+			}
+			version.Container = *container
+			results[i].Status = BulkVersionCreated
+			results[i].ContainerVersion = version
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// validateVersionRequest checks that a version can be created for a container
+func (s *ContainerService) validateVersionRequest(container *models.Container, req CreateVersionRequest) error {
 	// Check if version already exists
 	var existingVersion models.ContainerVersion
-	if err := s.db.Where("container_id = ? AND version = ?", containerID, req.Version).First(&existingVersion).Error; err == nil {
-		return nil, fmt.Errorf("version '%s' already exists for container '%s'", req.Version, container.Name)
+	if err := s.db.Where("container_id = ? AND version = ?", container.ID, req.Version).First(&existingVersion).Error; err == nil {
+		return fmt.Errorf("version '%s' already exists for container '%s'", req.Version, container.Name)
+	}
+
+	if req.Channel != "" && !models.IsValidVersionChannel(req.Channel) {
+		return fmt.Errorf("invalid channel '%s'", req.Channel)
 	}
 
 	// Validate compose content
 	if err := s.linter.ValidateCompose(req.Compose); err != nil {
-		return nil, fmt.Errorf("compose validation failed: %w", err)
+		return fmt.Errorf("compose validation failed: %w", err)
+	}
+
+	// Verify declared resources exist in storage
+	return s.checkResourcePaths(req.ResourcePaths)
+}
+
+// newContainerVersion builds an unpublished version from a create request
+func newContainerVersion(containerID uint, req CreateVersionRequest) *models.ContainerVersion {
+	channel := req.Channel
+	if channel == "" {
+		channel = models.VersionChannelStable
 	}
 
 	// Convert maps to JSON
@@ -269,30 +493,36 @@ func (s *ContainerService) CreateVersion(containerID uint, req CreateVersionRequ
 	resourcePathsBytes, _ := json.Marshal(req.ResourcePaths)
 	dependenciesBytes, _ := json.Marshal(req.Dependencies)
 
-	variablesJSON := datatypes.JSON(variablesBytes)
-	resourcePathsJSON := datatypes.JSON(resourcePathsBytes)
-	dependenciesJSON := datatypes.JSON(dependenciesBytes)
-
-	version := &models.ContainerVersion{
+	return &models.ContainerVersion{
 		ContainerID:    containerID,
 		Version:        req.Version,
 		ComposeContent: req.Compose,
-		Variables:      variablesJSON,
-		ResourcePaths:  resourcePathsJSON,
-		Dependencies:   dependenciesJSON,
+		Variables:      datatypes.JSON(variablesBytes),
+		ResourcePaths:  datatypes.JSON(resourcePathsBytes),
+		Dependencies:   datatypes.JSON(dependenciesBytes),
+		Channel:        channel,
 		Published:      false,
 	}
+}
 
-	if err := s.db.Create(version).Error; err != nil {
-		return nil, fmt.Errorf("failed to create version: %w", err)
+// checkResourcePaths returns an error listing any resource paths missing from storage
+func (s *ContainerService) checkResourcePaths(resourcePaths []string) error {
+	var missing []string
+	for _, resourcePath := range resourcePaths {
+		exists, err := s.storage.Exists(context.Background(), resourcePath)
+		if err != nil {
+			return fmt.Errorf("failed to check resource path '%s': %w", resourcePath, err)
+		}
+		if !exists {
+			missing = append(missing, resourcePath)
+		}
 	}
 
-	// Load the container relationship
-	if err := s.db.Preload("Container").First(version, version.ID).Error; err != nil {
-		return nil, fmt.Errorf("failed to load version with container: %w", err)
+	if len(missing) > 0 {
+		return fmt.Errorf("missing resource paths: %s", strings.Join(missing, ", "))
 	}
 
-	return version, nil
+	return nil
 }
 
 // GetVersion retrieves a specific version of a container
@@ -344,6 +574,13 @@ func (s *ContainerService) UpdateVersion(containerID uint, version string, req U
 		containerVersion.Dependencies = datatypes.JSON(dependenciesBytes)
 	}
 
+	if req.Channel != "" {
+		if !models.IsValidVersionChannel(req.Channel) {
+			return nil, fmt.Errorf("invalid channel '%s'", req.Channel)
+		}
+		containerVersion.Channel = req.Channel
+	}
+
 	if err := s.db.Save(containerVersion).Error; err != nil {
 		return nil, fmt.Errorf("failed to update version: %w", err)
 	}
@@ -362,6 +599,12 @@ func (s *ContainerService) PublishVersion(containerID uint, version string) (*mo
 		return nil, fmt.Errorf("version '%s' is already published", version)
 	}
 
+	if s.enforceVersionOrder {
+		if err := s.checkVersionOrder(containerVersion); err != nil {
+			return nil, err
+		}
+	}
+
 	// Final validation before publishing
 	if err := s.linter.ValidateCompose(containerVersion.ComposeContent); err != nil {
 		return nil, fmt.Errorf("cannot publish version with invalid compose: %w", err)
@@ -373,11 +616,63 @@ func (s *ContainerService) PublishVersion(containerID uint, version string) (*mo
 		return nil, fmt.Errorf("failed to publish version: %w", err)
 	}
 
+	s.notifyVersionPublished(containerVersion)
+
 	return containerVersion, nil
 }
 
-// ListVersions returns all versions for a container
-func (s *ContainerService) ListVersions(containerID uint, publishedOnly bool) ([]models.ContainerVersion, error) {
+// checkVersionOrder ensures a version is greater than the latest published
+// version of its container
+func (s *ContainerService) checkVersionOrder(version *models.ContainerVersion) error {
+	latest, err := s.GetLatestPublishedVersion(version.ContainerID)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	candidate, err := semver.NewVersion(version.Version)
+	if err != nil {
+		return fmt.Errorf("version '%s' is not a semantic version", version.Version)
+	}
+	if !candidate.GreaterThan(semver.MustParse(latest.Version)) {
+		return fmt.Errorf("version '%s' must be greater than the latest published version '%s'", version.Version, latest.Version)
+	}
+
+	return nil
+}
+
+// notifyVersionPublished fires the publish webhook in the background.
+// Delivery failures are logged and never fail the publish.
+func (s *ContainerService) notifyVersionPublished(version *models.ContainerVersion) {
+	if !s.publishWebhook.Enabled() {
+		return
+	}
+
+	timestamp := time.Now().UTC()
+	if version.PublishedAt != nil {
+		timestamp = version.PublishedAt.UTC()
+	}
+
+	event := VersionPublishedEvent{
+		Event:       "container.version.published",
+		ContainerID: version.ContainerID,
+		Container:   version.Container.Name,
+		Version:     version.Version,
+		Timestamp:   timestamp,
+	}
+
+	go func() {
+		if err := s.publishWebhook.Send(context.Background(), event.Event, event); err != nil {
+			log.Printf("Warning: publish webhook for %s %s failed: %v", event.Container, event.Version, err)
+		}
+	}()
+}
+
+// ListVersions returns all versions for a container, optionally limited to
+// published versions and to one release channel
+func (s *ContainerService) ListVersions(containerID uint, publishedOnly bool, channel string) ([]models.ContainerVersion, error) {
 	// Verify container exists
 	if _, err := s.GetContainer(containerID, false); err != nil {
 		return nil, err
@@ -389,6 +684,9 @@ func (s *ContainerService) ListVersions(containerID uint, publishedOnly bool) ([
 	if publishedOnly {
 		query = query.Where("published = ?", true)
 	}
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
 
 	if err := query.Order("created_at DESC").Find(&versions).Error; err != nil {
 		return nil, fmt.Errorf("failed to list versions: %w", err)
@@ -396,3 +694,53 @@ func (s *ContainerService) ListVersions(containerID uint, publishedOnly bool) ([
 
 	return versions, nil
 }
+
+// GetLatestPublishedVersion returns the highest published semantic version
+// of a container across all channels, or ErrNotFound if none is published
+func (s *ContainerService) GetLatestPublishedVersion(containerID uint) (*models.ContainerVersion, error) {
+	versions, err := s.ListVersions(containerID, true, "")
+	if err != nil {
+		return nil, err
+	}
+
+	latest := highestSemver(versions)
+	if latest == nil {
+		return nil, ErrNotFound
+	}
+
+	return s.GetVersion(containerID, latest.Version)
+}
+
+// GetLatestVersionInChannel returns the highest published semantic version
+// of a container in a release channel
+func (s *ContainerService) GetLatestVersionInChannel(containerID uint, channel string) (*models.ContainerVersion, error) {
+	versions, err := s.ListVersions(containerID, true, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := highestSemver(versions)
+	if latest == nil {
+		return nil, fmt.Errorf("no published version in channel '%s'", channel)
+	}
+
+	return s.GetVersion(containerID, latest.Version)
+}
+
+// highestSemver returns the version with the greatest semantic version,
+// skipping versions that do not parse, or nil if none do
+func highestSemver(versions []models.ContainerVersion) *models.ContainerVersion {
+	var latest *models.ContainerVersion
+	var latestSemver *semver.Version
+	for i := range versions {
+		version, err := semver.NewVersion(versions[i].Version)
+		if err != nil {
+			continue
+		}
+		if latestSemver == nil || version.GreaterThan(latestSemver) {
+			latest = &versions[i]
+			latestSemver = version
+		}
+	}
+	return latest
+}
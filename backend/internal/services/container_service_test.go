@@ -0,0 +1,382 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/burndler/burndler/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerService_CreateVersion_ResourcePaths(t *testing.T) {
+	compose := `services:
+  web:
+    image: nginx:1.25.3`
+
+	tests := []struct {
+		name          string
+		resourcePaths []string
+		missingKeys   map[string]bool
+		wantErr       bool
+		errMsg        string
+	}{
+		{
+			name:          "all resource paths present",
+			resourcePaths: []string{"resources/web/index.html", "resources/web/app.js"},
+			wantErr:       false,
+		},
+		{
+			name:          "some resource paths missing",
+			resourcePaths: []string{"resources/web/index.html", "resources/web/app.js", "resources/web/style.css"},
+			missingKeys: map[string]bool{
+				"resources/web/app.js":    true,
+				"resources/web/style.css": true,
+			},
+			wantErr: true,
+			errMsg:  "missing resource paths: resources/web/app.js, resources/web/style.css",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTestDB(t)
+			containerService := NewContainerService(db, &MockStorage{MissingKeys: tt.missingKeys}, NewLinter())
+
+			container := &models.Container{Name: "web"}
+			assert.NoError(t, db.Create(container).Error)
+
+			version, err := containerService.CreateVersion(container.ID, CreateVersionRequest{
+				Version:       "v1.0.0",
+				Compose:       compose,
+				ResourcePaths: tt.resourcePaths,
+			})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.errMsg, err.Error())
+				assert.Nil(t, version)
+
+				var count int64
+				db.Model(&models.ContainerVersion{}).Count(&count)
+				assert.Equal(t, int64(0), count)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, version)
+			}
+		})
+	}
+}
+
+func TestContainerService_PublishVersion_Webhook(t *testing.T) {
+	type delivery struct {
+		event     string
+		signature string
+		body      []byte
+	}
+	deliveries := make(chan delivery, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{
+			event:     r.Header.Get(WebhookEventHeader),
+			signature: r.Header.Get(WebhookSignatureHeader),
+			body:      body,
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	db := setupServiceTestDB(t)
+	containerService := NewContainerService(db, &MockStorage{}, NewLinter())
+	containerService.SetPublishWebhook(NewWebhookNotifier(server.URL, "test-secret"))
+
+	container := &models.Container{Name: "web"}
+	assert.NoError(t, db.Create(container).Error)
+	_, err := containerService.CreateVersion(container.ID, CreateVersionRequest{
+		Version: "v1.0.0",
+		Compose: "services:\n  web:\n    image: nginx:1.25.3",
+	})
+	assert.NoError(t, err)
+
+	published, err := containerService.PublishVersion(container.ID, "v1.0.0")
+	assert.NoError(t, err)
+
+	select {
+	case got := <-deliveries:
+		assert.Equal(t, "container.version.published", got.event)
+		assert.Equal(t, SignWebhookPayload("test-secret", got.body), got.signature)
+
+		var payload VersionPublishedEvent
+		assert.NoError(t, json.Unmarshal(got.body, &payload))
+		assert.Equal(t, container.ID, payload.ContainerID)
+		assert.Equal(t, "web", payload.Container)
+		assert.Equal(t, "v1.0.0", payload.Version)
+		assert.True(t, payload.Timestamp.Equal(published.PublishedAt.UTC()))
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected publish webhook to be delivered")
+	}
+}
+
+func TestContainerService_ListContainers_Pagination(t *testing.T) {
+	tests := []struct {
+		name           string
+		containers     int
+		page           int
+		wantTotalPages int
+		wantHasNext    bool
+	}{
+		{name: "exact multiple, first page", containers: 4, page: 1, wantTotalPages: 2, wantHasNext: true},
+		{name: "exact multiple, last page", containers: 4, page: 2, wantTotalPages: 2, wantHasNext: false},
+		{name: "partial final page", containers: 5, page: 3, wantTotalPages: 3, wantHasNext: false},
+		{name: "partial final page, middle page", containers: 5, page: 2, wantTotalPages: 3, wantHasNext: true},
+		{name: "no containers", containers: 0, page: 1, wantTotalPages: 0, wantHasNext: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTestDB(t)
+			containerService := NewContainerService(db, &MockStorage{}, NewLinter())
+			for i := 0; i < tt.containers; i++ {
+				assert.NoError(t, db.Create(&models.Container{Name: fmt.Sprintf("container-%d", i)}).Error)
+			}
+
+			result, err := containerService.ListContainers(ContainerFilters{Page: tt.page, PageSize: 2})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(tt.containers), result.Total)
+			assert.Equal(t, tt.wantTotalPages, result.TotalPages)
+			assert.Equal(t, tt.wantHasNext, result.HasNext)
+		})
+	}
+}
+
+func TestContainerService_VersionChannels(t *testing.T) {
+	db := setupServiceTestDB(t)
+	containerService := NewContainerService(db, &MockStorage{}, NewLinter())
+	compose := "services:\n  web:\n    image: nginx:1.25.3"
+
+	container := &models.Container{Name: "web"}
+	assert.NoError(t, db.Create(container).Error)
+
+	versions := []struct {
+		version string
+		channel string
+		publish bool
+	}{
+		{"v1.0.0", "", true},
+		{"v1.1.0", models.VersionChannelStable, true},
+		{"v1.2.0", models.VersionChannelStable, false},
+		{"v2.0.0-beta.1", models.VersionChannelBeta, true},
+		{"v2.0.0-beta.2", models.VersionChannelBeta, true},
+	}
+	for _, v := range versions {
+		_, err := containerService.CreateVersion(container.ID, CreateVersionRequest{Version: v.version, Compose: compose, Channel: v.channel})
+		assert.NoError(t, err)
+		if v.publish {
+			_, err = containerService.PublishVersion(container.ID, v.version)
+			assert.NoError(t, err)
+		}
+	}
+
+	_, err := containerService.CreateVersion(container.ID, CreateVersionRequest{Version: "v3.0.0", Compose: compose, Channel: "nightly"})
+	assert.EqualError(t, err, "invalid channel 'nightly'")
+
+	stable, err := containerService.ListVersions(container.ID, false, models.VersionChannelStable)
+	assert.NoError(t, err)
+	assert.Len(t, stable, 3, "versions without a channel default to stable")
+
+	beta, err := containerService.ListVersions(container.ID, false, models.VersionChannelBeta)
+	assert.NoError(t, err)
+	assert.Len(t, beta, 2)
+
+	all, err := containerService.ListVersions(container.ID, false, "")
+	assert.NoError(t, err)
+	assert.Len(t, all, 5)
+
+	latestStable, err := containerService.GetLatestVersionInChannel(container.ID, models.VersionChannelStable)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.1.0", latestStable.Version, "unpublished versions are not resolved")
+
+	latestBeta, err := containerService.GetLatestVersionInChannel(container.ID, models.VersionChannelBeta)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0-beta.2", latestBeta.Version)
+	assert.Equal(t, "web", latestBeta.Container.Name)
+
+	_, err = containerService.GetLatestVersionInChannel(container.ID, models.VersionChannelEdge)
+	assert.EqualError(t, err, "no published version in channel 'edge'")
+}
+
+func TestContainerService_GetLatestPublishedVersion(t *testing.T) {
+	db := setupServiceTestDB(t)
+	containerService := NewContainerService(db, &MockStorage{}, NewLinter())
+	compose := "services:\n  web:\n    image: nginx:1.25.3"
+
+	container := &models.Container{Name: "web"}
+	assert.NoError(t, db.Create(container).Error)
+
+	_, err := containerService.CreateVersion(container.ID, CreateVersionRequest{Version: "v1.0.0", Compose: compose})
+	assert.NoError(t, err)
+	_, err = containerService.GetLatestPublishedVersion(container.ID)
+	assert.ErrorIs(t, err, ErrNotFound, "unpublished versions are not resolved")
+
+	versions := []struct {
+		version string
+		publish bool
+	}{
+		{"v1.9.0", true},
+		{"v1.10.0", true},
+		{"v1.2.0", true},
+		{"v1.11.0", false},
+	}
+	for _, v := range versions {
+		_, err := containerService.CreateVersion(container.ID, CreateVersionRequest{Version: v.version, Compose: compose})
+		assert.NoError(t, err)
+		if v.publish {
+			_, err = containerService.PublishVersion(container.ID, v.version)
+			assert.NoError(t, err)
+		}
+	}
+
+	latest, err := containerService.GetLatestPublishedVersion(container.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.10.0", latest.Version, "v1.10.0 sorts above v1.9.0 by semver")
+	assert.Equal(t, "web", latest.Container.Name)
+
+	_, err = containerService.GetLatestPublishedVersion(container.ID + 1)
+	assert.EqualError(t, err, "container not found")
+}
+
+func TestContainerService_PublishVersion_EnforceOrder(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx:1.25.3"
+
+	tests := []struct {
+		name    string
+		enforce bool
+		publish string
+		wantErr string
+	}{
+		{name: "greater version is allowed", enforce: true, publish: "v2.1.0"},
+		{name: "lower version is rejected", enforce: true, publish: "v1.0.0",
+			wantErr: "version 'v1.0.0' must be greater than the latest published version 'v2.0.0'"},
+		{name: "lower version is allowed when the guard is disabled", enforce: false, publish: "v1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTestDB(t)
+			containerService := NewContainerService(db, &MockStorage{}, NewLinter())
+			containerService.SetEnforceVersionOrder(tt.enforce)
+
+			container := &models.Container{Name: "web"}
+			assert.NoError(t, db.Create(container).Error)
+			for _, version := range []string{"v1.0.0", "v2.0.0", "v2.1.0"} {
+				_, err := containerService.CreateVersion(container.ID, CreateVersionRequest{Version: version, Compose: compose})
+				assert.NoError(t, err)
+			}
+			_, err := containerService.PublishVersion(container.ID, "v2.0.0")
+			assert.NoError(t, err, "the first published version is always allowed")
+
+			published, err := containerService.PublishVersion(container.ID, tt.publish)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				version, err := containerService.GetVersion(container.ID, tt.publish)
+				assert.NoError(t, err)
+				assert.False(t, version.Published)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, published.Published)
+		})
+	}
+}
+
+func TestContainerService_RestoreContainer(t *testing.T) {
+	db := setupServiceTestDB(t)
+	containerService := NewContainerService(db, &MockStorage{}, NewLinter())
+
+	deleted := &models.Container{Name: "deleted"}
+	live := &models.Container{Name: "live"}
+	assert.NoError(t, db.Create(deleted).Error)
+	assert.NoError(t, db.Create(live).Error)
+	assert.NoError(t, containerService.DeleteContainer(deleted.ID))
+
+	_, err := containerService.GetContainer(deleted.ID, false)
+	assert.EqualError(t, err, "container not found")
+
+	restored, err := containerService.RestoreContainer(deleted.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", restored.Name)
+	assert.False(t, restored.DeletedAt.Valid)
+
+	_, err = containerService.GetContainer(deleted.ID, false)
+	assert.NoError(t, err, "restored container is visible again")
+
+	before, err := containerService.GetContainer(live.ID, false)
+	assert.NoError(t, err)
+	_, err = containerService.RestoreContainer(live.ID)
+	assert.EqualError(t, err, "container is not deleted")
+	after, err := containerService.GetContainer(live.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, before.UpdatedAt, after.UpdatedAt, "a live container is left untouched")
+
+	_, err = containerService.RestoreContainer(live.ID + 100)
+	assert.EqualError(t, err, "container not found")
+}
+
+func TestContainerService_ListContainers_Search(t *testing.T) {
+	db := setupServiceTestDB(t)
+	containerService := NewContainerService(db, &MockStorage{}, NewLinter())
+
+	for _, container := range []*models.Container{
+		{Name: "nginx-proxy", Description: "Edge reverse proxy"},
+		{Name: "metrics", Description: "Prometheus with a Reverse-Proxy sidecar"},
+		{Name: "legacy-gateway", Description: "Old reverse proxy"},
+		{Name: "postgres", Description: "Relational database"},
+	} {
+		assert.NoError(t, db.Create(container).Error)
+	}
+	assert.NoError(t, db.Model(&models.Container{}).Where("name = ?", "legacy-gateway").Update("active", false).Error)
+
+	names := func(result *PaginatedResponse[models.Container]) []string {
+		var names []string
+		for _, container := range result.Data {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+
+	result, err := containerService.ListContainers(ContainerFilters{Search: "DATABASE"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"postgres"}, names(result), "description-only match is found case-insensitively")
+
+	result, err = containerService.ListContainers(ContainerFilters{Search: "reverse"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"legacy-gateway", "metrics", "nginx-proxy"}, names(result))
+
+	active := true
+	result, err = containerService.ListContainers(ContainerFilters{Search: "reverse", Active: &active})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metrics", "nginx-proxy"}, names(result), "search is combined with the active filter")
+
+	result, err = containerService.ListContainers(ContainerFilters{Search: "nginx"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nginx-proxy"}, names(result))
+
+	// LIKE wildcards in the search match literally
+	assert.NoError(t, db.Create(&models.Container{Name: "cache_store", Description: "100% in-memory"}).Error)
+	result, err = containerService.ListContainers(ContainerFilters{Search: "_"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache_store"}, names(result))
+
+	result, err = containerService.ListContainers(ContainerFilters{Search: "%"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cache_store"}, names(result))
+
+	result, err = containerService.ListContainers(ContainerFilters{Search: `\`})
+	assert.NoError(t, err)
+	assert.Empty(t, names(result))
+}
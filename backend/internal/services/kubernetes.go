@@ -0,0 +1,344 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KubernetesConverter converts merged compose files into basic Kubernetes
+// manifests. It is experimental and only covers Deployments and Services.
+type KubernetesConverter struct{}
+
+// NewKubernetesConverter creates a new Kubernetes converter
+func NewKubernetesConverter() *KubernetesConverter {
+	return &KubernetesConverter{}
+}
+
+// KubernetesResult contains the generated manifests and conversion warnings
+type KubernetesResult struct {
+	Manifests string   `json:"manifests"`
+	Warnings  []string `json:"warnings"`
+}
+
+// supportedKubernetesKeys lists compose service keys the converter understands
+var supportedKubernetesKeys = map[string]bool{
+	"image":       true,
+	"ports":       true,
+	"environment": true,
+	"command":     true,
+	"entrypoint":  true,
+	"deploy":      true,
+}
+
+type k8sMetadata struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+type k8sEnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type k8sContainerPort struct {
+	ContainerPort int    `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol,omitempty"`
+}
+
+type k8sContainer struct {
+	Name    string             `yaml:"name"`
+	Image   string             `yaml:"image"`
+	Command []string           `yaml:"command,omitempty"`
+	Args    []string           `yaml:"args,omitempty"`
+	Env     []k8sEnvVar        `yaml:"env,omitempty"`
+	Ports   []k8sContainerPort `yaml:"ports,omitempty"`
+}
+
+type k8sDeployment struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   k8sMetadata `yaml:"metadata"`
+	Spec       struct {
+		Replicas int `yaml:"replicas"`
+		Selector struct {
+			MatchLabels map[string]string `yaml:"matchLabels"`
+		} `yaml:"selector"`
+		Template struct {
+			Metadata k8sMetadata `yaml:"metadata"`
+			Spec     struct {
+				Containers []k8sContainer `yaml:"containers"`
+			} `yaml:"spec"`
+		} `yaml:"template"`
+	} `yaml:"spec"`
+}
+
+type k8sServicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort int    `yaml:"targetPort"`
+	Protocol   string `yaml:"protocol,omitempty"`
+}
+
+type k8sService struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   k8sMetadata `yaml:"metadata"`
+	Spec       struct {
+		Selector map[string]string `yaml:"selector"`
+		Ports    []k8sServicePort  `yaml:"ports"`
+	} `yaml:"spec"`
+}
+
+// Convert generates a multi-document manifest from a compose file
+func (k *KubernetesConverter) Convert(composeContent string) (*KubernetesResult, error) {
+	var compose map[string]interface{}
+	if err := yaml.Unmarshal([]byte(composeContent), &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse compose: %w", err)
+	}
+
+	result := &KubernetesResult{
+		Warnings: []string{},
+	}
+
+	for _, key := range []string{"networks", "volumes", "secrets", "configs"} {
+		if _, ok := compose[key]; ok {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Top-level '%s' is not supported and was ignored", key))
+		}
+	}
+
+	services, _ := compose["services"].(map[string]interface{})
+	serviceNames := make([]string, 0, len(services))
+	for name := range services {
+		serviceNames = append(serviceNames, name)
+	}
+	sort.Strings(serviceNames)
+
+	var documents []interface{}
+	owners := make(map[string]string) // kubernetes name -> compose service
+	for _, serviceName := range serviceNames {
+		config, ok := services[serviceName].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name := kubernetesName(serviceName)
+		if owner, exists := owners[name]; exists {
+			return nil, fmt.Errorf("services '%s' and '%s' both convert to kubernetes name '%s'", owner, serviceName, name)
+		}
+		owners[name] = serviceName
+
+		deployment, service, warnings, err := k.convertService(serviceName, config)
+		if err != nil {
+			return nil, err
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+
+		documents = append(documents, deployment)
+		if service != nil {
+			documents = append(documents, service)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, fmt.Errorf("failed to marshal kubernetes manifest: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal kubernetes manifest: %w", err)
+	}
+
+	result.Manifests = buf.String()
+	return result, nil
+}
+
+// convertService builds the Deployment and, when ports are published, the Service
+func (k *KubernetesConverter) convertService(serviceName string, config map[string]interface{}) (*k8sDeployment, *k8sService, []string, error) {
+	var warnings []string
+
+	image, ok := config["image"].(string)
+	if !ok || image == "" {
+		return nil, nil, nil, fmt.Errorf("service '%s' has no image", serviceName)
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		if !supportedKubernetesKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		warnings = append(warnings, fmt.Sprintf("Service '%s': '%s' is not supported and was ignored", serviceName, key))
+	}
+
+	// Only deploy.replicas is converted
+	if deploy, ok := config["deploy"].(map[string]interface{}); ok {
+		deployKeys := make([]string, 0, len(deploy))
+		for key := range deploy {
+			if key != "replicas" {
+				deployKeys = append(deployKeys, key)
+			}
+		}
+		sort.Strings(deployKeys)
+		for _, key := range deployKeys {
+			warnings = append(warnings, fmt.Sprintf("Service '%s': 'deploy.%s' is not supported and was ignored", serviceName, key))
+		}
+	}
+
+	name := kubernetesName(serviceName)
+	labels := map[string]string{"app": name}
+
+	container := k8sContainer{
+		Name:    name,
+		Image:   image,
+		Command: stringList(config["entrypoint"]),
+		Args:    stringList(config["command"]),
+		Env:     kubernetesEnv(config["environment"]),
+	}
+
+	var servicePorts []k8sServicePort
+	if ports, ok := config["ports"].([]interface{}); ok {
+		for _, port := range ports {
+			published, target, protocol, err := parsePortMapping(fmt.Sprint(port))
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("Service '%s': %v", serviceName, err))
+				continue
+			}
+
+			container.Ports = append(container.Ports, k8sContainerPort{ContainerPort: target, Protocol: protocol})
+			servicePorts = append(servicePorts, k8sServicePort{
+				Name:       fmt.Sprintf("%s-%d", strings.ToLower(protocol), published),
+				Port:       published,
+				TargetPort: target,
+				Protocol:   protocol,
+			})
+		}
+	}
+
+	deployment := &k8sDeployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   k8sMetadata{Name: name, Labels: labels},
+	}
+	deployment.Spec.Replicas = replicaCount(config["deploy"])
+	deployment.Spec.Selector.MatchLabels = labels
+	deployment.Spec.Template.Metadata = k8sMetadata{Name: name, Labels: labels}
+	deployment.Spec.Template.Spec.Containers = []k8sContainer{container}
+
+	if len(servicePorts) == 0 {
+		return deployment, nil, warnings, nil
+	}
+
+	service := &k8sService{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Metadata:   k8sMetadata{Name: name, Labels: labels},
+	}
+	service.Spec.Selector = labels
+	service.Spec.Ports = servicePorts
+
+	return deployment, service, warnings, nil
+}
+
+var invalidKubernetesNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// kubernetesName converts a compose service name into a DNS-1123 label
+func kubernetesName(name string) string {
+	name = invalidKubernetesNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	return name
+}
+
+// kubernetesEnv converts list or map compose environment entries
+func kubernetesEnv(environment interface{}) []k8sEnvVar {
+	var env []k8sEnvVar
+
+	switch entries := environment.(type) {
+	case []interface{}:
+		for _, entry := range entries {
+			if str, ok := entry.(string); ok {
+				key, value, _ := strings.Cut(str, "=")
+				env = append(env, k8sEnvVar{Name: key, Value: value})
+			}
+		}
+	case map[string]interface{}:
+		for key, value := range entries {
+			if value == nil {
+				value = ""
+			}
+			env = append(env, k8sEnvVar{Name: key, Value: fmt.Sprint(value)})
+		}
+		sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+	}
+
+	return env
+}
+
+// stringList converts a compose string or list value into a slice
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list
+	}
+	return nil
+}
+
+// replicaCount reads deploy.replicas, defaulting to one
+func replicaCount(deploy interface{}) int {
+	if config, ok := deploy.(map[string]interface{}); ok {
+		if replicas, ok := config["replicas"].(int); ok && replicas > 0 {
+			return replicas
+		}
+	}
+	return 1
+}
+
+// parsePortMapping parses short-syntax compose ports into published and target ports
+func parsePortMapping(port string) (published, target int, protocol string, err error) {
+	protocol = "TCP"
+	if spec, proto, found := strings.Cut(port, "/"); found {
+		port = spec
+		protocol = strings.ToUpper(proto)
+	}
+
+	parts := strings.Split(port, ":")
+	targetStr := parts[len(parts)-1]
+	publishedStr := targetStr
+	if len(parts) >= 2 {
+		publishedStr = parts[len(parts)-2]
+	}
+
+	if strings.Contains(targetStr, "-") || strings.Contains(publishedStr, "-") {
+		return 0, 0, "", fmt.Errorf("port range '%s' is not supported", port)
+	}
+
+	target, err = strconv.Atoi(targetStr)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid port '%s'", port)
+	}
+	published, err = strconv.Atoi(publishedStr)
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("invalid port '%s'", port)
+	}
+
+	return published, target, protocol, nil
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestKubernetesConverter_Convert(t *testing.T) {
+	compose := `services:
+  web__app:
+    image: nginx:1.25
+    ports:
+      - "8080:80"
+    environment:
+      - MODE=production
+      - DEBUG=false
+    volumes:
+      - ./html:/usr/share/nginx/html
+`
+
+	result, err := NewKubernetesConverter().Convert(compose)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	var docs []map[string]interface{}
+	decoder := yaml.NewDecoder(strings.NewReader(result.Manifests))
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			break
+		}
+		docs = append(docs, doc)
+	}
+
+	if len(docs) != 2 {
+		t.Fatalf("Expected 2 documents, got %d:\n%s", len(docs), result.Manifests)
+	}
+
+	deployment := docs[0]
+	if deployment["kind"] != "Deployment" || deployment["apiVersion"] != "apps/v1" {
+		t.Errorf("Expected apps/v1 Deployment, got %v %v", deployment["apiVersion"], deployment["kind"])
+	}
+	if name := deployment["metadata"].(map[string]interface{})["name"]; name != "web-app" {
+		t.Errorf("Expected deployment name 'web-app', got %v", name)
+	}
+
+	spec := deployment["spec"].(map[string]interface{})
+	if spec["replicas"] != 1 {
+		t.Errorf("Expected 1 replica, got %v", spec["replicas"])
+	}
+	podSpec := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+	if container["image"] != "nginx:1.25" {
+		t.Errorf("Expected image 'nginx:1.25', got %v", container["image"])
+	}
+
+	env := container["env"].([]interface{})
+	if len(env) != 2 {
+		t.Fatalf("Expected 2 env vars, got %d", len(env))
+	}
+	if first := env[0].(map[string]interface{}); first["name"] != "MODE" || first["value"] != "production" {
+		t.Errorf("Expected MODE=production, got %v", first)
+	}
+
+	ports := container["ports"].([]interface{})
+	if port := ports[0].(map[string]interface{}); port["containerPort"] != 80 {
+		t.Errorf("Expected containerPort 80, got %v", port["containerPort"])
+	}
+
+	service := docs[1]
+	if service["kind"] != "Service" || service["apiVersion"] != "v1" {
+		t.Errorf("Expected v1 Service, got %v %v", service["apiVersion"], service["kind"])
+	}
+	serviceSpec := service["spec"].(map[string]interface{})
+	if selector := serviceSpec["selector"].(map[string]interface{}); selector["app"] != "web-app" {
+		t.Errorf("Expected selector app=web-app, got %v", selector)
+	}
+	servicePort := serviceSpec["ports"].([]interface{})[0].(map[string]interface{})
+	if servicePort["port"] != 8080 || servicePort["targetPort"] != 80 {
+		t.Errorf("Expected port 8080 -> 80, got %v -> %v", servicePort["port"], servicePort["targetPort"])
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "'volumes'") {
+		t.Errorf("Expected a single volumes warning, got %v", result.Warnings)
+	}
+}
+
+func TestKubernetesConverter_Convert_NoPorts(t *testing.T) {
+	compose := `services:
+  worker:
+    image: busybox:latest
+    deploy:
+      replicas: 3
+    ports:
+      - "9000-9010:9000-9010"
+`
+
+	result, err := NewKubernetesConverter().Convert(compose)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	if strings.Contains(result.Manifests, "kind: Service") {
+		t.Error("Expected no Service when no ports can be converted")
+	}
+	if !strings.Contains(result.Manifests, "replicas: 3") {
+		t.Errorf("Expected replicas from deploy section, got:\n%s", result.Manifests)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "port range") {
+		t.Errorf("Expected a port range warning, got %v", result.Warnings)
+	}
+}
+
+func TestKubernetesConverter_Convert_MissingImage(t *testing.T) {
+	_, err := NewKubernetesConverter().Convert(`services:
+  web:
+    ports:
+      - "80:80"
+`)
+	if err == nil {
+		t.Error("Expected error for service without image")
+	}
+}
+
+func TestKubernetesConverter_Convert_DeployWarnings(t *testing.T) {
+	result, err := NewKubernetesConverter().Convert(`services:
+  worker:
+    image: busybox:latest
+    deploy:
+      replicas: 2
+      resources:
+        limits:
+          memory: 512M
+      restart_policy:
+        condition: on-failure
+`)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	expected := []string{
+		"Service 'worker': 'deploy.resources' is not supported and was ignored",
+		"Service 'worker': 'deploy.restart_policy' is not supported and was ignored",
+	}
+	if strings.Join(result.Warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected warnings %v, got %v", expected, result.Warnings)
+	}
+	if !strings.Contains(result.Manifests, "replicas: 2") {
+		t.Errorf("Expected replicas from deploy section, got:\n%s", result.Manifests)
+	}
+}
+
+func TestKubernetesConverter_Convert_NameCollision(t *testing.T) {
+	_, err := NewKubernetesConverter().Convert(`services:
+  web__app:
+    image: nginx:1.25
+  web_app:
+    image: nginx:1.25
+`)
+	if err == nil || err.Error() != "services 'web__app' and 'web_app' both convert to kubernetes name 'web-app'" {
+		t.Errorf("Expected a name collision error, got %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"path"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -19,6 +21,11 @@ func NewLinter() *Linter {
 type LintRequest struct {
 	Compose    string `json:"compose"`
 	StrictMode bool   `json:"strict_mode"`
+	// ResourcePaths lists files packaged with the build. When set, relative
+	// bind mounts and env_file entries must resolve to one of them.
+	ResourcePaths []string `json:"resource_paths,omitempty"`
+	// TargetPlatform is the platform the installer targets, e.g. linux/arm64
+	TargetPlatform string `json:"target_platform,omitempty"`
 }
 
 // LintResult contains lint errors and warnings
@@ -33,6 +40,10 @@ type LintIssue struct {
 	Rule    string `json:"rule"`
 	Message string `json:"message"`
 	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+
+	// path locates the offending node, e.g. services/web/build
+	path []string
 }
 
 // ValidateCompose validates a compose file content
@@ -73,7 +84,7 @@ func (l *Linter) Lint(req *LintRequest) (*LintResult, error) {
 
 	// Check services
 	if services, ok := compose["services"].(map[string]interface{}); ok {
-		l.checkServices(services, compose, result)
+		l.checkServices(services, compose, req.StrictMode, result)
 	}
 
 	// Check for unresolved variables
@@ -84,6 +95,28 @@ func (l *Linter) Lint(req *LintRequest) (*LintResult, error) {
 		l.checkPortCollisions(services, result)
 	}
 
+	// Check for circular dependencies
+	if services, ok := compose["services"].(map[string]interface{}); ok {
+		l.checkDependencyCycles(services, result)
+	}
+
+	// Check image platforms
+	if services, ok := compose["services"].(map[string]interface{}); ok {
+		l.checkPlatforms(services, req.TargetPlatform, result)
+	}
+
+	// Check that referenced local files are packaged
+	if services, ok := compose["services"].(map[string]interface{}); ok && req.ResourcePaths != nil {
+		l.checkResourceReferences(services, req.ResourcePaths, result)
+	}
+
+	// Resolve issue positions from the YAML node tree
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(req.Compose), &root); err == nil {
+		l.resolvePositions(&root, result.Errors)
+		l.resolvePositions(&root, result.Warnings)
+	}
+
 	// Set valid flag based on errors
 	result.Valid = len(result.Errors) == 0
 
@@ -99,6 +132,7 @@ func (l *Linter) checkBuildDirective(compose map[string]interface{}, result *Lin
 					result.Errors = append(result.Errors, LintIssue{
 						Rule:    "no-build-directive",
 						Message: fmt.Sprintf("Service '%s' contains forbidden 'build:' directive. Use prebuilt images only.", serviceName),
+						path:    []string{"services", serviceName, "build"},
 					})
 				}
 			}
@@ -107,7 +141,7 @@ func (l *Linter) checkBuildDirective(compose map[string]interface{}, result *Lin
 }
 
 // checkServices validates service configurations
-func (l *Linter) checkServices(services map[string]interface{}, compose map[string]interface{}, result *LintResult) {
+func (l *Linter) checkServices(services map[string]interface{}, compose map[string]interface{}, strictMode bool, result *LintResult) {
 	networks := l.getDefinedNames(compose, "networks")
 	volumes := l.getDefinedNames(compose, "volumes")
 	serviceNames := l.getServiceNames(services)
@@ -128,6 +162,9 @@ func (l *Linter) checkServices(services map[string]interface{}, compose map[stri
 
 			// Check image format
 			l.checkImageFormat(serviceName, config, result)
+
+			// Check image tag pinning
+			l.checkImageTag(serviceName, config, strictMode, result)
 		}
 	}
 }
@@ -143,6 +180,7 @@ func (l *Linter) checkDependsOn(serviceName string, config map[string]interface{
 						result.Errors = append(result.Errors, LintIssue{
 							Rule:    "invalid-depends-on",
 							Message: fmt.Sprintf("Service '%s' depends on non-existent service '%s'", serviceName, depName),
+							path:    []string{"services", serviceName, "depends_on", depName},
 						})
 					}
 				}
@@ -153,13 +191,113 @@ func (l *Linter) checkDependsOn(serviceName string, config map[string]interface{
 					result.Errors = append(result.Errors, LintIssue{
 						Rule:    "invalid-depends-on",
 						Message: fmt.Sprintf("Service '%s' depends on non-existent service '%s'", serviceName, depName),
+						path:    []string{"services", serviceName, "depends_on", depName},
+					})
+				}
+			}
+		}
+	}
+}
+
+// checkDependencyCycles reports circular depends_on chains, which would
+// deadlock at startup. Each cycle is reported once, starting from its
+// alphabetically first service.
+func (l *Linter) checkDependencyCycles(services map[string]interface{}, result *LintResult) {
+	serviceNames := l.getServiceNames(services)
+	sort.Strings(serviceNames)
+
+	graph := make(map[string][]string)
+	for _, serviceName := range serviceNames {
+		if config, ok := services[serviceName].(map[string]interface{}); ok {
+			graph[serviceName] = dependencyNames(config)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	reported := make(map[string]bool)
+	var stack []string
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+
+		for _, dep := range graph[name] {
+			if _, exists := graph[dep]; !exists {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				cycle := dependencyCycle(stack, dep)
+				key := strings.Join(cycle, " -> ")
+				if !reported[key] {
+					reported[key] = true
+					result.Errors = append(result.Errors, LintIssue{
+						Rule:    "dependency-cycle",
+						Message: fmt.Sprintf("Circular depends_on detected: %s -> %s", key, cycle[0]),
+						path:    []string{"services", cycle[0], "depends_on"},
 					})
 				}
 			}
 		}
+
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+
+	for _, serviceName := range serviceNames {
+		if state[serviceName] == unvisited {
+			visit(serviceName)
+		}
 	}
 }
 
+// dependencyCycle extracts the cycle ending at start from the DFS stack and
+// rotates it so that it begins with its alphabetically first service
+func dependencyCycle(stack []string, start string) []string {
+	var cycle []string
+	for i, name := range stack {
+		if name == start {
+			cycle = append([]string{}, stack[i:]...)
+			break
+		}
+	}
+
+	first := 0
+	for i, name := range cycle {
+		if name < cycle[first] {
+			first = i
+		}
+	}
+	return append(cycle[first:], cycle[:first]...)
+}
+
+// dependencyNames returns the sorted service names from list or map depends_on
+func dependencyNames(config map[string]interface{}) []string {
+	var names []string
+	switch deps := config["depends_on"].(type) {
+	case []interface{}:
+		for _, dep := range deps {
+			if depName, ok := dep.(string); ok {
+				names = append(names, depName)
+			}
+		}
+	case map[string]interface{}:
+		for depName := range deps {
+			names = append(names, depName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // checkNetworkReferences validates network references
 func (l *Linter) checkNetworkReferences(serviceName string, config map[string]interface{}, validNetworks []string, result *LintResult) {
 	if networks, ok := config["networks"]; ok {
@@ -171,6 +309,7 @@ func (l *Linter) checkNetworkReferences(serviceName string, config map[string]in
 						result.Errors = append(result.Errors, LintIssue{
 							Rule:    "invalid-network",
 							Message: fmt.Sprintf("Service '%s' references non-existent network '%s'", serviceName, netName),
+							path:    []string{"services", serviceName, "networks", netName},
 						})
 					}
 				}
@@ -204,6 +343,7 @@ func (l *Linter) checkVolumeReferences(serviceName string, config map[string]int
 						result.Errors = append(result.Errors, LintIssue{
 							Rule:    "invalid-volume",
 							Message: fmt.Sprintf("Service '%s' references non-existent volume '%s'", serviceName, volumeName),
+							path:    []string{"services", serviceName, "volumes", volStr},
 						})
 					}
 				}
@@ -219,6 +359,7 @@ func (l *Linter) checkSecuritySettings(serviceName string, config map[string]int
 		result.Warnings = append(result.Warnings, LintIssue{
 			Rule:    "privileged-container",
 			Message: fmt.Sprintf("Service '%s' runs in privileged mode", serviceName),
+			path:    []string{"services", serviceName, "privileged"},
 		})
 	}
 
@@ -227,6 +368,7 @@ func (l *Linter) checkSecuritySettings(serviceName string, config map[string]int
 		result.Warnings = append(result.Warnings, LintIssue{
 			Rule:    "capability-add",
 			Message: fmt.Sprintf("Service '%s' adds Linux capabilities", serviceName),
+			path:    []string{"services", serviceName, "cap_add"},
 		})
 	}
 }
@@ -238,16 +380,48 @@ func (l *Linter) checkImageFormat(serviceName string, config map[string]interfac
 			result.Warnings = append(result.Warnings, LintIssue{
 				Rule:    "image-digest",
 				Message: fmt.Sprintf("Service '%s' image '%s' doesn't use SHA256 digest", serviceName, image),
+				path:    []string{"services", serviceName, "image"},
 			})
 		}
 	} else {
 		result.Errors = append(result.Errors, LintIssue{
 			Rule:    "missing-image",
 			Message: fmt.Sprintf("Service '%s' missing image specification", serviceName),
+			path:    []string{"services", serviceName},
 		})
 	}
 }
 
+// checkImageTag flags floating image tags (latest or no tag). These are
+// errors in strict mode and warnings otherwise.
+func (l *Linter) checkImageTag(serviceName string, config map[string]interface{}, strictMode bool, result *LintResult) {
+	image, ok := config["image"].(string)
+	if !ok || strings.Contains(image, "@sha256:") || strings.Contains(image, "${") {
+		return
+	}
+
+	// Only a colon after the last slash is a tag; earlier ones belong to a registry port
+	name := image[strings.LastIndex(image, "/")+1:]
+	tag := ""
+	if idx := strings.LastIndex(name, ":"); idx != -1 {
+		tag = name[idx+1:]
+	}
+	if tag != "" && tag != "latest" {
+		return
+	}
+
+	issue := LintIssue{
+		Rule:    "floating-image-tag",
+		Message: fmt.Sprintf("Service '%s' image '%s' uses a floating tag. Pin a specific version.", serviceName, image),
+		path:    []string{"services", serviceName, "image"},
+	}
+	if strictMode {
+		result.Errors = append(result.Errors, issue)
+	} else {
+		result.Warnings = append(result.Warnings, issue)
+	}
+}
+
 // checkUnresolvedVariables checks for unresolved environment variables
 func (l *Linter) checkUnresolvedVariables(compose string, result *LintResult) {
 	// Simple check for ${VAR} patterns that might be unresolved
@@ -273,20 +447,29 @@ func (l *Linter) checkUnresolvedVariables(compose string, result *LintResult) {
 	}
 }
 
-// checkPortCollisions checks for host port duplications
+// checkPortCollisions checks for host port duplications. A replicated
+// service publishing a fixed host port collides with itself and is
+// reported separately.
 func (l *Linter) checkPortCollisions(services map[string]interface{}, result *LintResult) {
 	usedPorts := make(map[string][]string) // port -> service names
 
 	for serviceName, serviceConfig := range services {
 		if config, ok := serviceConfig.(map[string]interface{}); ok {
+			replicas := serviceReplicas(config)
 			if ports, ok := config["ports"].([]interface{}); ok {
 				for _, port := range ports {
 					if portStr, ok := port.(string); ok {
-						// Extract host port
-						parts := strings.Split(portStr, ":")
-						if len(parts) >= 2 {
-							hostPort := parts[0]
+						if hostPort, ok := publishedHostPort(portStr); ok {
 							usedPorts[hostPort] = append(usedPorts[hostPort], serviceName)
+
+							if replicas > 1 && !strings.Contains(hostPort, "-") {
+								result.Errors = append(result.Errors, LintIssue{
+									Rule: "replicated-host-port",
+									Message: fmt.Sprintf("Service '%s' runs %d replicas but publishes fixed host port %s; replicas cannot share a host port, publish a port range or the container port only",
+										serviceName, replicas, hostPort),
+									path: []string{"services", serviceName, "ports"},
+								})
+							}
 						}
 					}
 				}
@@ -297,14 +480,249 @@ func (l *Linter) checkPortCollisions(services map[string]interface{}, result *Li
 	// Report collisions
 	for port, services := range usedPorts {
 		if len(services) > 1 {
+			sort.Strings(services)
 			result.Errors = append(result.Errors, LintIssue{
 				Rule:    "port-collision",
 				Message: fmt.Sprintf("Port %s used by multiple services: %s", port, strings.Join(services, ", ")),
+				path:    []string{"services", services[len(services)-1], "ports"},
 			})
 		}
 	}
 }
 
+// publishedHostPort extracts the host port of a short-syntax port mapping
+// such as "8080:80" or "127.0.0.1:8080:80". It reports false when only the
+// container port is given.
+func publishedHostPort(port string) (string, bool) {
+	parts := strings.Split(port, ":")
+	if len(parts) < 2 {
+		return "", false
+	}
+	return parts[len(parts)-2], true
+}
+
+// serviceReplicas returns deploy.replicas for a service, or 1 when unset
+func serviceReplicas(config map[string]interface{}) int {
+	deploy, ok := config["deploy"].(map[string]interface{})
+	if !ok {
+		return 1
+	}
+	if replicas, ok := deploy["replicas"].(int); ok {
+		return replicas
+	}
+	return 1
+}
+
+// checkPlatforms validates service platform declarations. With a target
+// platform, mismatches are errors and missing declarations are warnings.
+// Without one, services declaring different platforms are flagged.
+func (l *Linter) checkPlatforms(services map[string]interface{}, targetPlatform string, result *LintResult) {
+	serviceNames := l.getServiceNames(services)
+	sort.Strings(serviceNames)
+
+	target := normalizePlatform(targetPlatform)
+	declared := make(map[string][]string) // platform -> service names
+
+	for _, serviceName := range serviceNames {
+		config, ok := services[serviceName].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		platform, _ := config["platform"].(string)
+		platform = normalizePlatform(platform)
+
+		if platform == "" {
+			if target != "" {
+				result.Warnings = append(result.Warnings, LintIssue{
+					Rule:    "missing-platform",
+					Message: fmt.Sprintf("Service '%s' does not declare a platform; target is '%s'", serviceName, targetPlatform),
+					path:    []string{"services", serviceName},
+				})
+			}
+			continue
+		}
+
+		declared[platform] = append(declared[platform], serviceName)
+		if target != "" && platform != target {
+			result.Errors = append(result.Errors, LintIssue{
+				Rule:    "platform-mismatch",
+				Message: fmt.Sprintf("Service '%s' platform '%s' does not match target platform '%s'", serviceName, platform, targetPlatform),
+				path:    []string{"services", serviceName, "platform"},
+			})
+		}
+	}
+
+	if target == "" && len(declared) > 1 {
+		platforms := make([]string, 0, len(declared))
+		for platform, names := range declared {
+			platforms = append(platforms, fmt.Sprintf("%s (%s)", platform, strings.Join(names, ", ")))
+		}
+		sort.Strings(platforms)
+		result.Warnings = append(result.Warnings, LintIssue{
+			Rule:    "mixed-platforms",
+			Message: fmt.Sprintf("Services declare different platforms: %s", strings.Join(platforms, "; ")),
+		})
+	}
+}
+
+// normalizePlatform lowercases a platform and drops the default arm64 variant
+func normalizePlatform(platform string) string {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	return strings.TrimSuffix(platform, "/v8")
+}
+
+// checkResourceReferences checks relative bind mounts and env_file entries
+// against the files included in the package
+func (l *Linter) checkResourceReferences(services map[string]interface{}, resourcePaths []string, result *LintResult) {
+	available := make(map[string]bool)
+	for _, resourcePath := range resourcePaths {
+		available[normalizeResourcePath(resourcePath)] = true
+	}
+
+	serviceNames := l.getServiceNames(services)
+	sort.Strings(serviceNames)
+
+	for _, serviceName := range serviceNames {
+		config, ok := services[serviceName].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, reference := range l.localFileReferences(config) {
+			if !resourceAvailable(available, normalizeResourcePath(reference)) {
+				result.Errors = append(result.Errors, LintIssue{
+					Rule:    "missing-resource",
+					Message: fmt.Sprintf("Service '%s' references '%s' which is not included in the package resources", serviceName, reference),
+					path:    []string{"services", serviceName},
+				})
+			}
+		}
+	}
+}
+
+// localFileReferences returns relative bind mount sources and env_file paths
+func (l *Linter) localFileReferences(config map[string]interface{}) []string {
+	var references []string
+
+	if volumes, ok := config["volumes"].([]interface{}); ok {
+		for _, volume := range volumes {
+			switch v := volume.(type) {
+			case string:
+				source, _, found := strings.Cut(v, ":")
+				if found && isRelativePath(source) {
+					references = append(references, source)
+				}
+			case map[string]interface{}:
+				if volumeType, _ := v["type"].(string); volumeType == "bind" {
+					if source, ok := v["source"].(string); ok && isRelativePath(source) {
+						references = append(references, source)
+					}
+				}
+			}
+		}
+	}
+
+	switch envFile := config["env_file"].(type) {
+	case string:
+		references = append(references, envFile)
+	case []interface{}:
+		for _, entry := range envFile {
+			switch e := entry.(type) {
+			case string:
+				references = append(references, e)
+			case map[string]interface{}:
+				if envPath, ok := e["path"].(string); ok {
+					references = append(references, envPath)
+				}
+			}
+		}
+	}
+
+	var relative []string
+	for _, reference := range references {
+		if !strings.HasPrefix(reference, "/") && !strings.Contains(reference, "${") {
+			relative = append(relative, reference)
+		}
+	}
+	return relative
+}
+
+// isRelativePath reports whether a bind source is relative to the compose file
+func isRelativePath(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") || source == "."
+}
+
+// normalizeResourcePath cleans a path so ./config/app.yaml matches config/app.yaml
+func normalizeResourcePath(resourcePath string) string {
+	return strings.TrimPrefix(path.Clean(resourcePath), "./")
+}
+
+// resourceAvailable matches a file exactly or a directory by any file inside it
+func resourceAvailable(available map[string]bool, reference string) bool {
+	if available[reference] {
+		return true
+	}
+	if reference == "." {
+		return len(available) > 0
+	}
+	for resourcePath := range available {
+		if strings.HasPrefix(resourcePath, reference+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePositions fills in Line and Column for issues that carry a node path
+func (l *Linter) resolvePositions(root *yaml.Node, issues []LintIssue) {
+	for i := range issues {
+		if issues[i].Line != 0 || len(issues[i].path) == 0 {
+			continue
+		}
+		if node := findNode(root, issues[i].path); node != nil {
+			issues[i].Line = node.Line
+			issues[i].Column = node.Column
+		}
+	}
+}
+
+// findNode walks mapping keys and sequence values along path and returns the
+// deepest node found, or nil if the first segment is missing
+func findNode(root *yaml.Node, path []string) *yaml.Node {
+	current := root
+	if current.Kind == yaml.DocumentNode && len(current.Content) > 0 {
+		current = current.Content[0]
+	}
+
+	var found *yaml.Node
+	for _, segment := range path {
+		var next, position *yaml.Node
+		switch current.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(current.Content); i += 2 {
+				if current.Content[i].Value == segment {
+					position, next = current.Content[i], current.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			for _, item := range current.Content {
+				if item.Kind == yaml.ScalarNode && item.Value == segment {
+					position, next = item, item
+					break
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		found, current = position, next
+	}
+
+	return found
+}
+
 // Helper functions
 
 func (l *Linter) getDefinedNames(compose map[string]interface{}, key string) []string {
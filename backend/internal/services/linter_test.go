@@ -125,7 +125,7 @@ func TestLinter_Lint_DependsOnArray(t *testing.T) {
 		Compose: `version: '3'
 services:
   web:
-    image: nginx:latest
+    image: nginx:1.25.3
     depends_on:
       - db
       - cache
@@ -193,7 +193,7 @@ func TestLinter_Lint_DependsOnObject(t *testing.T) {
 		Compose: `version: '3'
 services:
   web:
-    image: nginx:latest
+    image: nginx:1.25.3
     depends_on:
       db:
         condition: service_healthy
@@ -216,6 +216,229 @@ services:
 	}
 }
 
+// Test checkDependencyCycles with acyclic and circular dependencies
+func TestLinter_Lint_DependencyCycles(t *testing.T) {
+	linter := NewLinter()
+
+	tests := []struct {
+		name          string
+		compose       string
+		expectedCycle string
+	}{
+		{
+			name: "acyclic graph",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    depends_on: [api, cache]
+  api:
+    image: app:1.0.0
+    depends_on:
+      db:
+        condition: service_healthy
+  cache:
+    image: redis:7.2
+  db:
+    image: postgres:16`,
+		},
+		{
+			name: "three node cycle",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    depends_on: [api]
+  api:
+    image: app:1.0.0
+    depends_on:
+      db:
+        condition: service_healthy
+  db:
+    image: postgres:16
+    depends_on: [web]
+  cache:
+    image: redis:7.2`,
+			expectedCycle: "Circular depends_on detected: api -> db -> web -> api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := linter.Lint(&LintRequest{Compose: tt.compose})
+			if err != nil {
+				t.Fatalf("Lint failed: %v", err)
+			}
+
+			var cycles []LintIssue
+			for _, issue := range result.Errors {
+				if issue.Rule == "dependency-cycle" {
+					cycles = append(cycles, issue)
+				}
+			}
+
+			if tt.expectedCycle == "" {
+				if len(cycles) != 0 || !result.Valid {
+					t.Errorf("Expected acyclic graph to pass, got %v", result.Errors)
+				}
+				return
+			}
+
+			if len(cycles) != 1 {
+				t.Fatalf("Expected exactly one dependency-cycle error, got %v", cycles)
+			}
+			if cycles[0].Message != tt.expectedCycle {
+				t.Errorf("Expected message %q, got %q", tt.expectedCycle, cycles[0].Message)
+			}
+			if result.Valid {
+				t.Error("Expected circular dependencies to fail validation")
+			}
+		})
+	}
+}
+
+func TestLinter_Lint_ReplicatedHostPort(t *testing.T) {
+	linter := NewLinter()
+
+	tests := []struct {
+		name            string
+		compose         string
+		expectedMessage string
+	}{
+		{
+			name: "replicated service with fixed host port",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    ports: ["8080:80"]
+    deploy:
+      replicas: 3`,
+			expectedMessage: "Service 'web' runs 3 replicas but publishes fixed host port 8080; replicas cannot share a host port, publish a port range or the container port only",
+		},
+		{
+			name: "replicated service with host IP and fixed host port",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    ports: ["127.0.0.1:8080:80"]
+    deploy:
+      replicas: 3`,
+			expectedMessage: "Service 'web' runs 3 replicas but publishes fixed host port 8080; replicas cannot share a host port, publish a port range or the container port only",
+		},
+		{
+			name: "replicated service with host port range",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    ports: ["8080-8082:80"]
+    deploy:
+      replicas: 3`,
+		},
+		{
+			name: "single replica with fixed host port",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    ports: ["8080:80"]
+    deploy:
+      replicas: 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := linter.Lint(&LintRequest{Compose: tt.compose})
+			if err != nil {
+				t.Fatalf("Lint failed: %v", err)
+			}
+
+			var issues []LintIssue
+			for _, issue := range result.Errors {
+				if issue.Rule == "port-collision" {
+					t.Errorf("Expected no generic port-collision error, got %q", issue.Message)
+				}
+				if issue.Rule == "replicated-host-port" {
+					issues = append(issues, issue)
+				}
+			}
+
+			if tt.expectedMessage == "" {
+				if len(issues) != 0 || !result.Valid {
+					t.Errorf("Expected compose to pass, got %v", result.Errors)
+				}
+				return
+			}
+
+			if len(issues) != 1 {
+				t.Fatalf("Expected exactly one replicated-host-port error, got %v", issues)
+			}
+			if issues[0].Message != tt.expectedMessage {
+				t.Errorf("Expected message %q, got %q", tt.expectedMessage, issues[0].Message)
+			}
+			if issues[0].Line != 4 {
+				t.Errorf("Expected issue on the ports line 4, got %d", issues[0].Line)
+			}
+		})
+	}
+}
+
+// Test port collisions compare host ports, not host IPs
+func TestLinter_Lint_PortCollisionWithHostIP(t *testing.T) {
+	linter := NewLinter()
+
+	tests := []struct {
+		name            string
+		compose         string
+		expectedMessage string
+	}{
+		{
+			name: "different host ports on the same IP",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    ports: ["127.0.0.1:80:80"]
+  api:
+    image: nginx:1.25.3
+    ports: ["127.0.0.1:81:81"]`,
+		},
+		{
+			name: "same host port",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    ports: ["127.0.0.1:80:80"]
+  api:
+    image: nginx:1.25.3
+    ports: ["80:8080"]`,
+			expectedMessage: "Port 80 used by multiple services: api, web",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := linter.Lint(&LintRequest{Compose: tt.compose})
+			if err != nil {
+				t.Fatalf("Lint failed: %v", err)
+			}
+
+			var messages []string
+			for _, issue := range result.Errors {
+				if issue.Rule == "port-collision" {
+					messages = append(messages, issue.Message)
+				}
+			}
+
+			if tt.expectedMessage == "" {
+				if len(messages) != 0 {
+					t.Errorf("Expected no port collision, got %v", messages)
+				}
+				return
+			}
+			if len(messages) != 1 || messages[0] != tt.expectedMessage {
+				t.Errorf("Expected %q, got %v", tt.expectedMessage, messages)
+			}
+		})
+	}
+}
+
 // Test checkNetworkReferences validation
 func TestLinter_Lint_NetworkReferences(t *testing.T) {
 	linter := NewLinter()
@@ -224,7 +447,7 @@ func TestLinter_Lint_NetworkReferences(t *testing.T) {
 		Compose: `version: '3'
 services:
   web:
-    image: nginx:latest
+    image: nginx:1.25.3
     networks:
       - frontend
       - backend
@@ -291,7 +514,7 @@ func TestLinter_Lint_VolumeReferences(t *testing.T) {
 		Compose: `version: '3'
 services:
   web:
-    image: nginx:latest
+    image: nginx:1.25.3
     volumes:
       - data_volume:/app/data
       - logs_volume:/app/logs
@@ -427,3 +650,279 @@ services:
 		t.Error("Expected warning about latest tag")
 	}
 }
+
+// Test checkImageTag flags floating tags as errors in strict mode
+func TestLinter_Lint_FloatingImageTags(t *testing.T) {
+	linter := NewLinter()
+
+	tests := []struct {
+		name        string
+		image       string
+		strictMode  bool
+		wantError   bool
+		wantWarning bool
+	}{
+		{name: "latest tag strict", image: "nginx:latest", strictMode: true, wantError: true},
+		{name: "no tag strict", image: "nginx", strictMode: true, wantError: true},
+		{name: "registry port without tag strict", image: "registry.local:5000/nginx", strictMode: true, wantError: true},
+		{name: "pinned tag strict", image: "nginx:1.25.3", strictMode: true},
+		{name: "latest tag non-strict", image: "nginx:latest", strictMode: false, wantWarning: true},
+		{name: "no tag non-strict", image: "nginx", strictMode: false, wantWarning: true},
+		{name: "pinned tag non-strict", image: "nginx:1.25.3", strictMode: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := linter.Lint(&LintRequest{
+				Compose:    "services:\n  web:\n    image: " + tt.image,
+				StrictMode: tt.strictMode,
+			})
+			if err != nil {
+				t.Fatalf("Lint failed: %v", err)
+			}
+
+			var gotError, gotWarning bool
+			for _, issue := range result.Errors {
+				if issue.Rule == "floating-image-tag" {
+					gotError = true
+					if !strings.Contains(issue.Message, "'web'") || !strings.Contains(issue.Message, "'"+tt.image+"'") {
+						t.Errorf("Expected error to name service and image, got: %s", issue.Message)
+					}
+				}
+			}
+			for _, issue := range result.Warnings {
+				if issue.Rule == "floating-image-tag" {
+					gotWarning = true
+				}
+			}
+
+			if gotError != tt.wantError {
+				t.Errorf("floating-image-tag error = %v, want %v", gotError, tt.wantError)
+			}
+			if gotWarning != tt.wantWarning {
+				t.Errorf("floating-image-tag warning = %v, want %v", gotWarning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+// Test checkResourceReferences requires bind mounts and env files to be packaged
+func TestLinter_Lint_ResourceReferences(t *testing.T) {
+	linter := NewLinter()
+
+	compose := `services:
+  web:
+    image: nginx:1.25.3
+    env_file:
+      - ./env/web.env
+    volumes:
+      - ./config/app.yaml:/etc/app.yaml:ro
+      - ./html:/usr/share/nginx/html
+      - /var/log/web:/var/log/nginx
+      - data:/data
+volumes:
+  data: {}`
+
+	tests := []struct {
+		name          string
+		resourcePaths []string
+		wantMissing   []string
+	}{
+		{
+			name:          "all referenced files present",
+			resourcePaths: []string{"config/app.yaml", "env/web.env", "html/index.html"},
+		},
+		{
+			name:          "config file missing",
+			resourcePaths: []string{"./env/web.env", "html/index.html"},
+			wantMissing:   []string{"./config/app.yaml"},
+		},
+		{
+			name:          "nothing packaged",
+			resourcePaths: []string{},
+			wantMissing:   []string{"./config/app.yaml", "./html", "./env/web.env"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := linter.Lint(&LintRequest{
+				Compose:       compose,
+				StrictMode:    true,
+				ResourcePaths: tt.resourcePaths,
+			})
+			if err != nil {
+				t.Fatalf("Lint failed: %v", err)
+			}
+
+			var missing []LintIssue
+			for _, issue := range result.Errors {
+				if issue.Rule == "missing-resource" {
+					missing = append(missing, issue)
+				}
+			}
+
+			if len(missing) != len(tt.wantMissing) {
+				t.Fatalf("Expected %d missing-resource errors, got %v", len(tt.wantMissing), missing)
+			}
+			for i, want := range tt.wantMissing {
+				if !strings.Contains(missing[i].Message, "'"+want+"'") {
+					t.Errorf("Expected error naming %s, got: %s", want, missing[i].Message)
+				}
+			}
+		})
+	}
+
+	// Without resource paths the check is skipped
+	result, err := linter.Lint(&LintRequest{Compose: compose, StrictMode: true})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, issue := range result.Errors {
+		if issue.Rule == "missing-resource" {
+			t.Errorf("Expected no missing-resource errors without resource paths, got: %s", issue.Message)
+		}
+	}
+}
+
+// Test checkPlatforms validates platform declarations
+func TestLinter_Lint_Platforms(t *testing.T) {
+	linter := NewLinter()
+
+	tests := []struct {
+		name           string
+		compose        string
+		targetPlatform string
+		wantErrors     []string
+		wantWarnings   []string
+	}{
+		{
+			name: "consistent platforms match target",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    platform: linux/arm64
+  db:
+    image: postgres:16
+    platform: linux/arm64/v8`,
+			targetPlatform: "linux/arm64",
+		},
+		{
+			name: "mixed platforms with target",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    platform: linux/arm64
+  db:
+    image: postgres:16
+    platform: linux/amd64`,
+			targetPlatform: "linux/arm64",
+			wantErrors:     []string{"platform-mismatch"},
+		},
+		{
+			name: "unspecified platform with target",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    platform: linux/arm64
+  db:
+    image: postgres:16`,
+			targetPlatform: "linux/arm64",
+			wantWarnings:   []string{"missing-platform"},
+		},
+		{
+			name: "mixed platforms without target",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+    platform: linux/arm64
+  db:
+    image: postgres:16
+    platform: linux/amd64`,
+			wantWarnings: []string{"mixed-platforms"},
+		},
+		{
+			name: "unspecified platforms without target",
+			compose: `services:
+  web:
+    image: nginx:1.25.3
+  db:
+    image: postgres:16`,
+		},
+	}
+
+	platformRules := map[string]bool{"platform-mismatch": true, "missing-platform": true, "mixed-platforms": true}
+	collect := func(issues []LintIssue) []string {
+		var rules []string
+		for _, issue := range issues {
+			if platformRules[issue.Rule] {
+				rules = append(rules, issue.Rule)
+			}
+		}
+		return rules
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := linter.Lint(&LintRequest{
+				Compose:        tt.compose,
+				StrictMode:     true,
+				TargetPlatform: tt.targetPlatform,
+			})
+			if err != nil {
+				t.Fatalf("Lint failed: %v", err)
+			}
+
+			if got := collect(result.Errors); strings.Join(got, ",") != strings.Join(tt.wantErrors, ",") {
+				t.Errorf("platform errors = %v, want %v", got, tt.wantErrors)
+			}
+			if got := collect(result.Warnings); strings.Join(got, ",") != strings.Join(tt.wantWarnings, ",") {
+				t.Errorf("platform warnings = %v, want %v", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+// Test lint issues report the YAML position of the offending node
+func TestLinter_Lint_IssuePositions(t *testing.T) {
+	linter := NewLinter()
+
+	req := &LintRequest{
+		Compose: `services:
+  web:
+    image: nginx:1.25.3
+    depends_on:
+      - db
+      - missing
+  api:
+    image: node:20.11.0
+    build: ./api
+  db:
+    image: postgres:16
+    platform: linux/amd64
+  cache:
+    image: redis:7
+    platform: linux/arm64`,
+		StrictMode: true,
+	}
+
+	result, err := linter.Lint(req)
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+
+	positions := map[string][2]int{}
+	for _, issue := range append(result.Errors, result.Warnings...) {
+		positions[issue.Rule] = [2]int{issue.Line, issue.Column}
+	}
+
+	if got := positions["no-build-directive"]; got != [2]int{9, 5} {
+		t.Errorf("no-build-directive position = %v, want line 9 column 5", got)
+	}
+	if got := positions["invalid-depends-on"]; got != [2]int{6, 9} {
+		t.Errorf("invalid-depends-on position = %v, want line 6 column 9", got)
+	}
+	if got := positions["mixed-platforms"]; got != [2]int{0, 0} {
+		t.Errorf("mixed-platforms position = %v, want unknown (0)", got)
+	}
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"path"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -19,6 +21,22 @@ func NewMerger() *Merger {
 type MergeRequest struct {
 	Modules          []Module          `json:"modules"`
 	ServiceVariables map[string]string `json:"service_variables"`
+	DefaultLogging   *LoggingConfig    `json:"default_logging,omitempty"`
+	// ComposeInterpolation also resolves docker-compose ${VAR:-default}
+	// syntax. Variables resolve as: service variables, then module
+	// variables, then the inline default; anything left unresolved is kept
+	// for runtime env substitution.
+	ComposeInterpolation bool `json:"compose_interpolation,omitempty"`
+	// CollectDiagnostics reports every merge problem in MergeResult.Diagnostics
+	// instead of failing on the first one. Offending modules and services are
+	// left out of the merged compose.
+	CollectDiagnostics bool `json:"collect_diagnostics,omitempty"`
+}
+
+// LoggingConfig is a compose logging driver applied to services without one
+type LoggingConfig struct {
+	Driver  string            `json:"driver"`
+	Options map[string]string `json:"options,omitempty"`
 }
 
 // Module represents a compose module to merge
@@ -33,6 +51,31 @@ type MergeResult struct {
 	MergedCompose string            `json:"merged_compose"`
 	Mappings      map[string]string `json:"mappings"`
 	Warnings      []string          `json:"warnings"`
+	SecretFiles   []SecretFile      `json:"secret_files,omitempty"`
+	Diagnostics   []MergeDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// Merge diagnostic kinds
+const (
+	MergeDiagnosticInvalidModule     = "invalid-module"
+	MergeDiagnosticDuplicateService  = "duplicate-service"
+	MergeDiagnosticDanglingReference = "dangling-reference"
+)
+
+// MergeDiagnostic is a problem found while merging with CollectDiagnostics
+type MergeDiagnostic struct {
+	Kind    string `json:"kind"`
+	Module  string `json:"module"`
+	Service string `json:"service,omitempty"`
+	Message string `json:"message"`
+}
+
+// SecretFile is a file-based compose secret that must ship with the package
+type SecretFile struct {
+	Module string `json:"module"`
+	Secret string `json:"secret"`
+	Source string `json:"source"` // file path as written in the module compose
+	Path   string `json:"path"`   // location inside the package
 }
 
 // Merge combines multiple compose files with namespace prefixing
@@ -43,27 +86,62 @@ func (m *Merger) Merge(req *MergeRequest) (*MergeResult, error) {
 	}
 
 	mergedServices := make(map[string]interface{})
+	serviceOwners := make(map[string]string) // namespaced service key -> module name
 	mergedNetworks := make(map[string]interface{})
 	mergedVolumes := make(map[string]interface{})
+	mergedSecrets := make(map[string]interface{})
 
 	for _, module := range req.Modules {
 		// Parse module compose
 		var compose map[string]interface{}
 		if err := yaml.Unmarshal([]byte(module.Compose), &compose); err != nil {
-			return nil, fmt.Errorf("failed to parse compose for module %s: %w", module.Name, err)
+			if !req.CollectDiagnostics {
+				return nil, fmt.Errorf("failed to parse compose for module %s: %w", module.Name, err)
+			}
+			result.Diagnostics = append(result.Diagnostics, MergeDiagnostic{
+				Kind:    MergeDiagnosticInvalidModule,
+				Module:  module.Name,
+				Message: fmt.Sprintf("failed to parse compose for module %s: %v", module.Name, err),
+			})
+			continue
 		}
 
+		// Namespace module-local networks and volumes; external ones keep their name
+		networkNames := m.namespaceResources(compose["networks"], module.Name)
+		volumeNames := m.namespaceResources(compose["volumes"], module.Name)
+		secretNames := m.namespaceResources(compose["secrets"], module.Name)
+
 		// Process services
 		if services, ok := compose["services"].(map[string]interface{}); ok {
 			for serviceName, serviceConfig := range services {
 				// Prefix service name with namespace
 				newName := fmt.Sprintf("%s__%s", module.Name, serviceName)
+				if owner, exists := serviceOwners[newName]; exists {
+					err := fmt.Errorf("duplicate service key '%s' produced by modules '%s' and '%s'", newName, owner, module.Name)
+					if !req.CollectDiagnostics {
+						return nil, err
+					}
+					result.Diagnostics = append(result.Diagnostics, MergeDiagnostic{
+						Kind:    MergeDiagnosticDuplicateService,
+						Module:  module.Name,
+						Service: newName,
+						Message: err.Error(),
+					})
+					continue
+				}
+				serviceOwners[newName] = module.Name
 				result.Mappings[serviceName] = newName
 
 				// Update depends_on references
 				if config, ok := serviceConfig.(map[string]interface{}); ok {
 					m.updateDependsOn(config, module.Name, result.Mappings)
-					m.substituteVariables(config, module.Variables, req.ServiceVariables)
+					m.updateNetworkReferences(config, networkNames)
+					m.updateVolumeReferences(config, volumeNames)
+					m.updateSecretReferences(config, secretNames)
+					m.substituteVariables(config, module.Variables, req.ServiceVariables, req.ComposeInterpolation)
+					m.mergeExtraHosts(newName, config, result)
+					m.dedupeCapabilities(config)
+					m.applyDefaultLogging(config, req.DefaultLogging)
 				}
 
 				mergedServices[newName] = serviceConfig
@@ -73,7 +151,7 @@ func (m *Merger) Merge(req *MergeRequest) (*MergeResult, error) {
 		// Process networks
 		if networks, ok := compose["networks"].(map[string]interface{}); ok {
 			for networkName, networkConfig := range networks {
-				newName := fmt.Sprintf("%s__%s", module.Name, networkName)
+				newName := networkNames[networkName]
 				result.Mappings[networkName] = newName
 				mergedNetworks[newName] = networkConfig
 			}
@@ -82,16 +160,36 @@ func (m *Merger) Merge(req *MergeRequest) (*MergeResult, error) {
 		// Process volumes
 		if volumes, ok := compose["volumes"].(map[string]interface{}); ok {
 			for volumeName, volumeConfig := range volumes {
-				newName := fmt.Sprintf("%s__%s", module.Name, volumeName)
+				newName := volumeNames[volumeName]
 				result.Mappings[volumeName] = newName
 				mergedVolumes[newName] = volumeConfig
 			}
 		}
+
+		// Process secrets
+		if secrets, ok := compose["secrets"].(map[string]interface{}); ok {
+			for secretName, secretConfig := range secrets {
+				newName := secretNames[secretName]
+				result.Mappings[secretName] = newName
+				if config, ok := secretConfig.(map[string]interface{}); ok {
+					m.relocateSecretFile(module.Name, newName, config, result)
+				}
+				mergedSecrets[newName] = secretConfig
+			}
+		}
 	}
 
+	sort.Slice(result.SecretFiles, func(i, j int) bool {
+		return result.SecretFiles[i].Path < result.SecretFiles[j].Path
+	})
+
 	// Check for port collisions
 	m.checkPortCollisions(mergedServices, result)
 
+	if req.CollectDiagnostics {
+		m.checkDanglingDependsOn(mergedServices, serviceOwners, result)
+	}
+
 	// Build final compose
 	finalCompose := map[string]interface{}{
 		"version": "3.9",
@@ -106,6 +204,9 @@ func (m *Merger) Merge(req *MergeRequest) (*MergeResult, error) {
 	if len(mergedVolumes) > 0 {
 		finalCompose["volumes"] = mergedVolumes
 	}
+	if len(mergedSecrets) > 0 {
+		finalCompose["secrets"] = mergedSecrets
+	}
 
 	// Convert to YAML
 	yamlBytes, err := yaml.Marshal(finalCompose)
@@ -117,6 +218,49 @@ func (m *Merger) Merge(req *MergeRequest) (*MergeResult, error) {
 	return result, nil
 }
 
+// checkDanglingDependsOn records a diagnostic for each depends_on entry
+// naming a service missing from the merged compose
+func (m *Merger) checkDanglingDependsOn(services map[string]interface{}, owners map[string]string, result *MergeResult) {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		config, ok := services[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var deps []string
+		switch dependsOn := config["depends_on"].(type) {
+		case []interface{}:
+			for _, dep := range dependsOn {
+				if depName, ok := dep.(string); ok {
+					deps = append(deps, depName)
+				}
+			}
+		case map[string]interface{}:
+			for depName := range dependsOn {
+				deps = append(deps, depName)
+			}
+			sort.Strings(deps)
+		}
+
+		for _, dep := range deps {
+			if _, exists := services[dep]; !exists {
+				result.Diagnostics = append(result.Diagnostics, MergeDiagnostic{
+					Kind:    MergeDiagnosticDanglingReference,
+					Module:  owners[name],
+					Service: name,
+					Message: fmt.Sprintf("Service '%s' depends on undefined service '%s'", name, dep),
+				})
+			}
+		}
+	}
+}
+
 // updateDependsOn updates depends_on references with namespace prefix
 func (m *Merger) updateDependsOn(service map[string]interface{}, namespace string, mappings map[string]string) {
 	if dependsOn, ok := service["depends_on"]; ok {
@@ -144,23 +288,159 @@ func (m *Merger) updateDependsOn(service map[string]interface{}, namespace strin
 	}
 }
 
-// substituteVariables replaces variables with service overrides > module defaults
-func (m *Merger) substituteVariables(config map[string]interface{}, moduleVars, serviceVars map[string]string) {
+// namespaceResources maps top-level network or volume names to their merged
+// names. Resources marked external: true are shared and keep their name.
+func (m *Merger) namespaceResources(resources interface{}, namespace string) map[string]string {
+	names := make(map[string]string)
+	declared, ok := resources.(map[string]interface{})
+	if !ok {
+		return names
+	}
+
+	for name, config := range declared {
+		if resourceConfig, ok := config.(map[string]interface{}); ok {
+			if external, _ := resourceConfig["external"].(bool); external {
+				names[name] = name
+				continue
+			}
+		}
+		names[name] = fmt.Sprintf("%s__%s", namespace, name)
+	}
+
+	return names
+}
+
+// updateNetworkReferences rewrites service network references to merged names
+func (m *Merger) updateNetworkReferences(service map[string]interface{}, networkNames map[string]string) {
+	switch networks := service["networks"].(type) {
+	case []interface{}:
+		for i, network := range networks {
+			if name, ok := network.(string); ok {
+				if newName, ok := networkNames[name]; ok {
+					networks[i] = newName
+				}
+			}
+		}
+	case map[string]interface{}:
+		renamed := make(map[string]interface{})
+		for name, config := range networks {
+			if newName, ok := networkNames[name]; ok {
+				name = newName
+			}
+			renamed[name] = config
+		}
+		service["networks"] = renamed
+	}
+}
+
+// updateVolumeReferences rewrites named volume mounts to merged names
+func (m *Merger) updateVolumeReferences(service map[string]interface{}, volumeNames map[string]string) {
+	volumes, ok := service["volumes"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for i, volume := range volumes {
+		switch v := volume.(type) {
+		case string:
+			// Short syntax: source:target[:mode]
+			source, rest, found := strings.Cut(v, ":")
+			if !found {
+				continue
+			}
+			if newName, ok := volumeNames[source]; ok {
+				volumes[i] = newName + ":" + rest
+			}
+		case map[string]interface{}:
+			// Long syntax with type: volume
+			if volumeType, _ := v["type"].(string); volumeType != "" && volumeType != "volume" {
+				continue
+			}
+			if source, ok := v["source"].(string); ok {
+				if newName, ok := volumeNames[source]; ok {
+					v["source"] = newName
+				}
+			}
+		}
+	}
+}
+
+// updateSecretReferences rewrites service secret references to merged names.
+// The original name is kept as the mount target so the secret still appears
+// at /run/secrets/<name> inside the container.
+func (m *Merger) updateSecretReferences(service map[string]interface{}, secretNames map[string]string) {
+	secrets, ok := service["secrets"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for i, secret := range secrets {
+		switch s := secret.(type) {
+		case string:
+			if newName, ok := secretNames[s]; ok && newName != s {
+				secrets[i] = map[string]interface{}{
+					"source": newName,
+					"target": s,
+				}
+			}
+		case map[string]interface{}:
+			source, ok := s["source"].(string)
+			if !ok {
+				continue
+			}
+			if newName, ok := secretNames[source]; ok && newName != source {
+				s["source"] = newName
+				if _, hasTarget := s["target"]; !hasTarget {
+					s["target"] = source
+				}
+			}
+		}
+	}
+}
+
+// relocateSecretFile points a file-based secret at its packaged location,
+// secrets/<module>/<file>, and records the file for packaging. Absolute paths
+// and paths outside the module are left alone with a warning.
+func (m *Merger) relocateSecretFile(module, secretName string, config map[string]interface{}, result *MergeResult) {
+	file, ok := config["file"].(string)
+	if !ok || file == "" {
+		return
+	}
+
+	cleaned := normalizeResourcePath(file)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Secret '%s' file '%s' is outside module '%s' and will not be packaged", secretName, file, module))
+		return
+	}
+
+	packagePath := path.Join("secrets", module, cleaned)
+	config["file"] = "../" + packagePath
+	result.SecretFiles = append(result.SecretFiles, SecretFile{
+		Module: module,
+		Secret: secretName,
+		Source: file,
+		Path:   packagePath,
+	})
+}
+
+// substituteVariables replaces variables with service overrides > module defaults.
+// With defaults set, ${VAR:-default} falls back to its inline default.
+func (m *Merger) substituteVariables(config map[string]interface{}, moduleVars, serviceVars map[string]string, defaults bool) {
 	for key, value := range config {
 		switch v := value.(type) {
 		case string:
 			// Check for variable substitution
 			if strings.Contains(v, "${") {
-				config[key] = m.replaceVariables(v, moduleVars, serviceVars)
+				config[key] = m.interpolateVariables(v, moduleVars, serviceVars, defaults)
 			}
 		case map[string]interface{}:
 			// Recurse into nested maps
-			m.substituteVariables(v, moduleVars, serviceVars)
+			m.substituteVariables(v, moduleVars, serviceVars, defaults)
 		case []interface{}:
 			// Process arrays
 			for i, item := range v {
 				if str, ok := item.(string); ok && strings.Contains(str, "${") {
-					v[i] = m.replaceVariables(str, moduleVars, serviceVars)
+					v[i] = m.interpolateVariables(str, moduleVars, serviceVars, defaults)
 				}
 			}
 		}
@@ -169,29 +449,156 @@ func (m *Merger) substituteVariables(config map[string]interface{}, moduleVars,
 
 // replaceVariables replaces ${VAR} with actual values
 func (m *Merger) replaceVariables(str string, moduleVars, serviceVars map[string]string) string {
-	result := str
+	return m.interpolateVariables(str, moduleVars, serviceVars, false)
+}
 
-	// Find all variables
-	for strings.Contains(result, "${") {
-		start := strings.Index(result, "${")
-		end := strings.Index(result[start:], "}")
+// interpolateVariables replaces each ${VAR}, and with defaults each
+// ${VAR:-default}, in str. Unresolved variables are left as-is for env
+// substitution at runtime.
+func (m *Merger) interpolateVariables(str string, moduleVars, serviceVars map[string]string, defaults bool) string {
+	var result strings.Builder
+	rest := str
+
+	for {
+		start := strings.Index(rest, "${")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(rest[start:], "}")
 		if end == -1 {
 			break
 		}
 		end += start
 
-		varName := result[start+2 : end]
+		result.WriteString(rest[:start])
+		if val, ok := m.lookupVariable(rest[start+2:end], moduleVars, serviceVars, defaults); ok {
+			result.WriteString(val)
+		} else {
+			result.WriteString(rest[start : end+1])
+		}
+		rest = rest[end+1:]
+	}
+	result.WriteString(rest)
+
+	return result.String()
+}
+
+// lookupVariable resolves a variable expression: service variables override
+// module variables, and with defaults an unset or empty variable written as
+// VAR:-default takes the inline default
+func (m *Merger) lookupVariable(expr string, moduleVars, serviceVars map[string]string, defaults bool) (string, bool) {
+	name, fallback, hasDefault := expr, "", false
+	if defaults {
+		if i := strings.Index(expr, ":-"); i != -1 {
+			name, fallback, hasDefault = expr[:i], expr[i+2:], true
+		}
+	}
+
+	val, ok := serviceVars[name]
+	if !ok {
+		val, ok = moduleVars[name]
+	}
+	if hasDefault && val == "" {
+		return fallback, true
+	}
+	return val, ok
+}
+
+// mergeExtraHosts normalizes extra_hosts to a deduplicated list and flags
+// hostnames mapped to more than one address
+func (m *Merger) mergeExtraHosts(serviceName string, service map[string]interface{}, result *MergeResult) {
+	extraHosts, ok := service["extra_hosts"]
+	if !ok {
+		return
+	}
 
-		// Service variables override module variables
-		if val, ok := serviceVars[varName]; ok {
-			result = strings.Replace(result, result[start:end+1], val, 1)
-		} else if val, ok := moduleVars[varName]; ok {
-			result = strings.Replace(result, result[start:end+1], val, 1)
+	var entries [][2]string
+	switch hosts := extraHosts.(type) {
+	case []interface{}:
+		for _, entry := range hosts {
+			if str, ok := entry.(string); ok {
+				sep := strings.IndexAny(str, "=:")
+				if sep == -1 {
+					continue
+				}
+				entries = append(entries, [2]string{str[:sep], str[sep+1:]})
+			}
+		}
+	case map[string]interface{}:
+		hostnames := make([]string, 0, len(hosts))
+		for hostname := range hosts {
+			hostnames = append(hostnames, hostname)
 		}
-		// Leave unresolved variables as-is for env substitution
+		sort.Strings(hostnames)
+		for _, hostname := range hostnames {
+			entries = append(entries, [2]string{hostname, fmt.Sprint(hosts[hostname])})
+		}
+	default:
+		return
 	}
 
-	return result
+	addresses := make(map[string]string) // hostname -> address
+	merged := []interface{}{}
+	for _, entry := range entries {
+		hostname, address := entry[0], entry[1]
+		if existing, exists := addresses[hostname]; exists {
+			if existing != address {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("Extra host conflict: %s maps %s to both %s and %s",
+						serviceName, hostname, existing, address))
+			}
+			continue
+		}
+		addresses[hostname] = address
+		merged = append(merged, hostname+":"+address)
+	}
+
+	service["extra_hosts"] = merged
+}
+
+// dedupeCapabilities removes repeated cap_add/cap_drop entries, treating
+// NET_ADMIN and CAP_NET_ADMIN as the same capability. ulimits and sysctls
+// are passed through unchanged.
+func (m *Merger) dedupeCapabilities(service map[string]interface{}) {
+	for _, key := range []string{"cap_add", "cap_drop"} {
+		caps, ok := service[key].([]interface{})
+		if !ok {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		deduped := []interface{}{}
+		for _, capability := range caps {
+			name := strings.TrimPrefix(strings.ToUpper(fmt.Sprint(capability)), "CAP_")
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			deduped = append(deduped, capability)
+		}
+		service[key] = deduped
+	}
+}
+
+// applyDefaultLogging sets the default logging driver on a service that does
+// not declare its own logging configuration
+func (m *Merger) applyDefaultLogging(service map[string]interface{}, logging *LoggingConfig) {
+	if logging == nil || logging.Driver == "" {
+		return
+	}
+	if _, ok := service["logging"]; ok {
+		return
+	}
+
+	config := map[string]interface{}{"driver": logging.Driver}
+	if len(logging.Options) > 0 {
+		options := make(map[string]interface{}, len(logging.Options))
+		for key, value := range logging.Options {
+			options[key] = value
+		}
+		config["options"] = options
+	}
+	service["logging"] = config
 }
 
 // checkPortCollisions detects host port conflicts
@@ -203,10 +610,7 @@ func (m *Merger) checkPortCollisions(services map[string]interface{}, result *Me
 			if ports, ok := config["ports"].([]interface{}); ok {
 				for _, port := range ports {
 					if portStr, ok := port.(string); ok {
-						// Extract host port
-						parts := strings.Split(portStr, ":")
-						if len(parts) >= 2 {
-							hostPort := parts[0]
+						if hostPort, ok := publishedHostPort(portStr); ok {
 							if existingService, exists := usedPorts[hostPort]; exists {
 								result.Warnings = append(result.Warnings,
 									fmt.Sprintf("Port collision: %s used by both %s and %s",
@@ -3,6 +3,8 @@ package services
 import (
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Test NewMerger constructor
@@ -87,3 +89,652 @@ services:
 		t.Error("Expected backend service to be prefixed")
 	}
 }
+
+// Test extra_hosts entries are combined, deduplicated, and conflicts flagged
+func TestMerger_Merge_ExtraHosts(t *testing.T) {
+	merger := NewMerger()
+
+	req := &MergeRequest{
+		Modules: []Module{
+			{
+				Name: "web",
+				Compose: `services:
+  app:
+    image: nginx:1.25.3
+    extra_hosts:
+      - "db.internal:10.0.0.5"
+      - "cache.internal=10.0.0.6"
+      - "db.internal:10.0.0.5"
+      - "db.internal:10.0.0.9"`,
+			},
+			{
+				Name: "api",
+				Compose: `services:
+  server:
+    image: node:20
+    extra_hosts:
+      registry.local: 10.0.0.7
+      mirror.local: 10.0.0.8`,
+			},
+		},
+	}
+
+	result, err := merger.Merge(req)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	var merged struct {
+		Services map[string]struct {
+			ExtraHosts []string `yaml:"extra_hosts"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(result.MergedCompose), &merged); err != nil {
+		t.Fatalf("Failed to parse merged compose: %v", err)
+	}
+
+	web := merged.Services["web__app"].ExtraHosts
+	wantWeb := []string{"db.internal:10.0.0.5", "cache.internal:10.0.0.6"}
+	if strings.Join(web, ",") != strings.Join(wantWeb, ",") {
+		t.Errorf("web__app extra_hosts = %v, want %v", web, wantWeb)
+	}
+
+	api := merged.Services["api__server"].ExtraHosts
+	wantAPI := []string{"mirror.local:10.0.0.8", "registry.local:10.0.0.7"}
+	if strings.Join(api, ",") != strings.Join(wantAPI, ",") {
+		t.Errorf("api__server extra_hosts = %v, want %v", api, wantAPI)
+	}
+
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %v", result.Warnings)
+	}
+	if !strings.Contains(result.Warnings[0], "web__app maps db.internal to both 10.0.0.5 and 10.0.0.9") {
+		t.Errorf("Unexpected conflict warning: %s", result.Warnings[0])
+	}
+}
+
+// Test merge rejects modules that produce the same namespaced service key
+func TestMerger_Merge_DuplicateServiceKeys(t *testing.T) {
+	merger := NewMerger()
+
+	tests := []struct {
+		name    string
+		modules []Module
+		wantKey string
+	}{
+		{
+			name: "modules sharing a name both define web",
+			modules: []Module{
+				{Name: "frontend", Compose: "services:\n  web:\n    image: nginx:1.25.3"},
+				{Name: "frontend", Compose: "services:\n  web:\n    image: httpd:2.4"},
+			},
+			wantKey: "frontend__web",
+		},
+		{
+			name: "namespaced keys collide",
+			modules: []Module{
+				{Name: "shop", Compose: "services:\n  api__web:\n    image: nginx:1.25.3"},
+				{Name: "shop__api", Compose: "services:\n  web:\n    image: httpd:2.4"},
+			},
+			wantKey: "shop__api__web",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := merger.Merge(&MergeRequest{Modules: tt.modules})
+			if err == nil {
+				t.Fatal("Expected duplicate service key error")
+			}
+
+			msg := err.Error()
+			if !strings.Contains(msg, "'"+tt.wantKey+"'") {
+				t.Errorf("Expected error to name service key %s, got: %s", tt.wantKey, msg)
+			}
+			for _, module := range tt.modules {
+				if !strings.Contains(msg, "'"+module.Name+"'") {
+					t.Errorf("Expected error to name module %s, got: %s", module.Name, msg)
+				}
+			}
+		})
+	}
+}
+
+// Test merge preserves low-level tuning keys and deduplicates capabilities
+func TestMerger_Merge_UlimitsSysctlsCapabilities(t *testing.T) {
+	merger := NewMerger()
+
+	req := &MergeRequest{
+		Modules: []Module{
+			{
+				Name: "db",
+				Compose: `services:
+  postgres:
+    image: postgres:16
+    ulimits:
+      nproc: 65535
+      nofile:
+        soft: 20000
+        hard: 40000
+    sysctls:
+      net.core.somaxconn: 1024
+      net.ipv4.tcp_syncookies: 0
+    cap_add:
+      - NET_ADMIN
+      - SYS_PTRACE
+      - CAP_NET_ADMIN
+      - NET_ADMIN
+    cap_drop:
+      - ALL
+      - all`,
+			},
+		},
+	}
+
+	result, err := merger.Merge(req)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	var merged struct {
+		Services map[string]struct {
+			Ulimits map[string]interface{} `yaml:"ulimits"`
+			Sysctls map[string]int         `yaml:"sysctls"`
+			CapAdd  []string               `yaml:"cap_add"`
+			CapDrop []string               `yaml:"cap_drop"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(result.MergedCompose), &merged); err != nil {
+		t.Fatalf("Failed to parse merged compose: %v", err)
+	}
+
+	service, ok := merged.Services["db__postgres"]
+	if !ok {
+		t.Fatalf("Expected db__postgres in merged compose:\n%s", result.MergedCompose)
+	}
+
+	if service.Ulimits["nproc"] != 65535 {
+		t.Errorf("ulimits.nproc = %v, want 65535", service.Ulimits["nproc"])
+	}
+	nofile, ok := service.Ulimits["nofile"].(map[string]interface{})
+	if !ok || nofile["soft"] != 20000 || nofile["hard"] != 40000 {
+		t.Errorf("ulimits.nofile = %v, want soft 20000 hard 40000", service.Ulimits["nofile"])
+	}
+
+	if service.Sysctls["net.core.somaxconn"] != 1024 || service.Sysctls["net.ipv4.tcp_syncookies"] != 0 || len(service.Sysctls) != 2 {
+		t.Errorf("sysctls = %v, want both entries preserved", service.Sysctls)
+	}
+
+	if got := strings.Join(service.CapAdd, ","); got != "NET_ADMIN,SYS_PTRACE" {
+		t.Errorf("cap_add = %v, want [NET_ADMIN SYS_PTRACE]", service.CapAdd)
+	}
+	if got := strings.Join(service.CapDrop, ","); got != "ALL" {
+		t.Errorf("cap_drop = %v, want [ALL]", service.CapDrop)
+	}
+}
+
+// Test merge namespaces module volumes and networks and rewrites references
+func TestMerger_Merge_VolumeAndNetworkNamespacing(t *testing.T) {
+	merger := NewMerger()
+
+	req := &MergeRequest{
+		Modules: []Module{
+			{
+				Name: "api",
+				Compose: `services:
+  server:
+    image: node:20
+    volumes:
+      - data:/var/lib/api
+      - ./config:/etc/api:ro
+      - shared:/shared
+    networks:
+      - backend
+volumes:
+  data: {}
+  shared:
+    external: true
+networks:
+  backend: {}`,
+			},
+			{
+				Name: "db",
+				Compose: `services:
+  postgres:
+    image: postgres:16
+    volumes:
+      - type: volume
+        source: data
+        target: /var/lib/postgresql/data
+    networks:
+      backend:
+        aliases:
+          - database
+volumes:
+  data: {}
+networks:
+  backend: {}`,
+			},
+		},
+	}
+
+	result, err := merger.Merge(req)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	var merged struct {
+		Services map[string]struct {
+			Volumes  []interface{} `yaml:"volumes"`
+			Networks interface{}   `yaml:"networks"`
+		} `yaml:"services"`
+		Volumes  map[string]interface{} `yaml:"volumes"`
+		Networks map[string]interface{} `yaml:"networks"`
+	}
+	if err := yaml.Unmarshal([]byte(result.MergedCompose), &merged); err != nil {
+		t.Fatalf("Failed to parse merged compose: %v", err)
+	}
+
+	for _, name := range []string{"api__data", "db__data", "shared"} {
+		if _, ok := merged.Volumes[name]; !ok {
+			t.Errorf("Expected volume %s in merged compose, got %v", name, merged.Volumes)
+		}
+	}
+	if _, ok := merged.Volumes["data"]; ok {
+		t.Error("Expected module volume 'data' to be namespaced")
+	}
+	for _, name := range []string{"api__backend", "db__backend"} {
+		if _, ok := merged.Networks[name]; !ok {
+			t.Errorf("Expected network %s in merged compose, got %v", name, merged.Networks)
+		}
+	}
+
+	api := merged.Services["api__server"]
+	wantVolumes := []interface{}{"api__data:/var/lib/api", "./config:/etc/api:ro", "shared:/shared"}
+	for i, want := range wantVolumes {
+		if i >= len(api.Volumes) || api.Volumes[i] != want {
+			t.Errorf("api__server volumes = %v, want %v", api.Volumes, wantVolumes)
+			break
+		}
+	}
+	if networks, ok := api.Networks.([]interface{}); !ok || len(networks) != 1 || networks[0] != "api__backend" {
+		t.Errorf("api__server networks = %v, want [api__backend]", api.Networks)
+	}
+
+	db := merged.Services["db__postgres"]
+	if len(db.Volumes) != 1 {
+		t.Fatalf("db__postgres volumes = %v, want one mount", db.Volumes)
+	}
+	if mount, ok := db.Volumes[0].(map[string]interface{}); !ok || mount["source"] != "db__data" {
+		t.Errorf("db__postgres volume source = %v, want db__data", db.Volumes[0])
+	}
+	networks, ok := db.Networks.(map[string]interface{})
+	if !ok {
+		t.Fatalf("db__postgres networks = %v, want a map", db.Networks)
+	}
+	if _, ok := networks["db__backend"]; !ok || len(networks) != 1 {
+		t.Errorf("db__postgres networks = %v, want db__backend", networks)
+	}
+}
+
+// Test Merge namespaces secrets and relocates secret files into the package
+func TestMerger_Merge_SecretFiles(t *testing.T) {
+	merger := NewMerger()
+
+	req := &MergeRequest{
+		Modules: []Module{
+			{
+				Name: "db",
+				Compose: `services:
+  postgres:
+    image: postgres:16
+    secrets:
+      - db_password
+      - source: tls_key
+        mode: 0400
+      - registry_token
+secrets:
+  db_password:
+    file: ./secrets/db_password.txt
+  tls_key:
+    file: certs/server.key
+  registry_token:
+    external: true`,
+			},
+		},
+	}
+
+	result, err := merger.Merge(req)
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	var merged struct {
+		Services map[string]struct {
+			Secrets []interface{} `yaml:"secrets"`
+		} `yaml:"services"`
+		Secrets map[string]map[string]interface{} `yaml:"secrets"`
+	}
+	if err := yaml.Unmarshal([]byte(result.MergedCompose), &merged); err != nil {
+		t.Fatalf("Failed to parse merged compose: %v", err)
+	}
+
+	if file := merged.Secrets["db__db_password"]["file"]; file != "../secrets/db/secrets/db_password.txt" {
+		t.Errorf("db__db_password file = %v, want ../secrets/db/secrets/db_password.txt", file)
+	}
+	if file := merged.Secrets["db__tls_key"]["file"]; file != "../secrets/db/certs/server.key" {
+		t.Errorf("db__tls_key file = %v, want ../secrets/db/certs/server.key", file)
+	}
+	if _, ok := merged.Secrets["registry_token"]; !ok {
+		t.Errorf("Expected external secret to keep its name, got %v", merged.Secrets)
+	}
+
+	// References are renamed but still mount at the original target
+	secrets := merged.Services["db__postgres"].Secrets
+	if len(secrets) != 3 {
+		t.Fatalf("db__postgres secrets = %v, want three entries", secrets)
+	}
+	if ref, ok := secrets[0].(map[string]interface{}); !ok || ref["source"] != "db__db_password" || ref["target"] != "db_password" {
+		t.Errorf("short secret reference = %v, want source db__db_password and target db_password", secrets[0])
+	}
+	if ref, ok := secrets[1].(map[string]interface{}); !ok || ref["source"] != "db__tls_key" || ref["target"] != "tls_key" || ref["mode"] != 0400 {
+		t.Errorf("long secret reference = %v, want source db__tls_key and target tls_key", secrets[1])
+	}
+	if secrets[2] != "registry_token" {
+		t.Errorf("external secret reference = %v, want registry_token", secrets[2])
+	}
+
+	want := []SecretFile{
+		{Module: "db", Secret: "db__tls_key", Source: "certs/server.key", Path: "secrets/db/certs/server.key"},
+		{Module: "db", Secret: "db__db_password", Source: "./secrets/db_password.txt", Path: "secrets/db/secrets/db_password.txt"},
+	}
+	if len(result.SecretFiles) != len(want) {
+		t.Fatalf("SecretFiles = %v, want %v", result.SecretFiles, want)
+	}
+	for i := range want {
+		if result.SecretFiles[i] != want[i] {
+			t.Errorf("SecretFiles[%d] = %v, want %v", i, result.SecretFiles[i], want[i])
+		}
+	}
+}
+
+// Test Merge leaves secret files outside the module untouched
+func TestMerger_Merge_SecretFilesOutsideModule(t *testing.T) {
+	merger := NewMerger()
+
+	result, err := merger.Merge(&MergeRequest{
+		Modules: []Module{
+			{
+				Name: "db",
+				Compose: `services:
+  postgres:
+    image: postgres:16
+secrets:
+  host_key:
+    file: /etc/ssl/private/host.key
+  parent_key:
+    file: ../shared/key.pem`,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	if len(result.SecretFiles) != 0 {
+		t.Errorf("Expected no packaged secret files, got %v", result.SecretFiles)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected two warnings, got %v", result.Warnings)
+	}
+	if !strings.Contains(result.MergedCompose, "/etc/ssl/private/host.key") {
+		t.Error("Expected absolute secret file path to be kept")
+	}
+}
+
+// Test Merge keeps network drivers and driver options while namespacing
+func TestMerger_Merge_NetworkDriverOptions(t *testing.T) {
+	merger := NewMerger()
+
+	result, err := merger.Merge(&MergeRequest{
+		Modules: []Module{
+			{
+				Name: "edge",
+				Compose: `services:
+  proxy:
+    image: traefik:v3.0
+    networks:
+      - lan
+      - mesh
+networks:
+  lan:
+    driver: macvlan
+    driver_opts:
+      parent: eth0
+      macvlan_mode: bridge
+    ipam:
+      config:
+        - subnet: 192.168.10.0/24
+  mesh:
+    driver: overlay
+    attachable: true
+    driver_opts:
+      encrypted: "true"`,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	var merged struct {
+		Networks map[string]struct {
+			Driver     string            `yaml:"driver"`
+			DriverOpts map[string]string `yaml:"driver_opts"`
+			Attachable bool              `yaml:"attachable"`
+			IPAM       struct {
+				Config []map[string]string `yaml:"config"`
+			} `yaml:"ipam"`
+		} `yaml:"networks"`
+	}
+	if err := yaml.Unmarshal([]byte(result.MergedCompose), &merged); err != nil {
+		t.Fatalf("Failed to parse merged compose: %v", err)
+	}
+
+	lan, ok := merged.Networks["edge__lan"]
+	if !ok {
+		t.Fatalf("Expected network edge__lan, got %v", merged.Networks)
+	}
+	if lan.Driver != "macvlan" || lan.DriverOpts["parent"] != "eth0" || lan.DriverOpts["macvlan_mode"] != "bridge" {
+		t.Errorf("edge__lan driver = %s %v, want macvlan with parent eth0 and macvlan_mode bridge", lan.Driver, lan.DriverOpts)
+	}
+	if len(lan.IPAM.Config) != 1 || lan.IPAM.Config[0]["subnet"] != "192.168.10.0/24" {
+		t.Errorf("edge__lan ipam = %v, want subnet 192.168.10.0/24", lan.IPAM.Config)
+	}
+
+	mesh, ok := merged.Networks["edge__mesh"]
+	if !ok {
+		t.Fatalf("Expected network edge__mesh, got %v", merged.Networks)
+	}
+	if mesh.Driver != "overlay" || !mesh.Attachable || mesh.DriverOpts["encrypted"] != "true" {
+		t.Errorf("edge__mesh = %+v, want attachable overlay with encrypted option", mesh)
+	}
+}
+
+func TestMerger_Merge_Logging(t *testing.T) {
+	merger := NewMerger()
+
+	modules := []Module{
+		{
+			Name: "app",
+			Compose: `services:
+  api:
+    image: api:1.0
+    logging:
+      driver: syslog
+      options:
+        syslog-address: udp://logs:514
+  worker:
+    image: worker:1.0`,
+		},
+	}
+
+	type mergedLogging struct {
+		Services map[string]struct {
+			Logging *struct {
+				Driver  string            `yaml:"driver"`
+				Options map[string]string `yaml:"options"`
+			} `yaml:"logging"`
+		} `yaml:"services"`
+	}
+
+	parse := func(t *testing.T, result *MergeResult) mergedLogging {
+		t.Helper()
+		var merged mergedLogging
+		if err := yaml.Unmarshal([]byte(result.MergedCompose), &merged); err != nil {
+			t.Fatalf("Failed to parse merged compose: %v", err)
+		}
+		return merged
+	}
+
+	t.Run("preserves service logging", func(t *testing.T) {
+		result, err := merger.Merge(&MergeRequest{Modules: modules})
+		if err != nil {
+			t.Fatalf("Merge failed: %v", err)
+		}
+
+		merged := parse(t, result)
+		api := merged.Services["app__api"].Logging
+		if api == nil || api.Driver != "syslog" || api.Options["syslog-address"] != "udp://logs:514" {
+			t.Errorf("app__api logging = %+v, want syslog to udp://logs:514", api)
+		}
+		if worker := merged.Services["app__worker"].Logging; worker != nil {
+			t.Errorf("app__worker logging = %+v, want none without a default", worker)
+		}
+	})
+
+	t.Run("injects default logging", func(t *testing.T) {
+		result, err := merger.Merge(&MergeRequest{
+			Modules: modules,
+			DefaultLogging: &LoggingConfig{
+				Driver:  "json-file",
+				Options: map[string]string{"max-size": "10m", "max-file": "3"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Merge failed: %v", err)
+		}
+
+		merged := parse(t, result)
+		if api := merged.Services["app__api"].Logging; api == nil || api.Driver != "syslog" {
+			t.Errorf("app__api logging = %+v, want its own syslog driver kept", api)
+		}
+		worker := merged.Services["app__worker"].Logging
+		if worker == nil || worker.Driver != "json-file" || worker.Options["max-size"] != "10m" || worker.Options["max-file"] != "3" {
+			t.Errorf("app__worker logging = %+v, want default json-file with max-size 10m and max-file 3", worker)
+		}
+	})
+}
+
+func TestMerger_Merge_CollectDiagnostics(t *testing.T) {
+	merger := NewMerger()
+
+	modules := []Module{
+		{Name: "broken", Compose: "services: [web"},
+		{Name: "frontend", Compose: "services:\n  web:\n    image: nginx:1.25.3"},
+		{Name: "frontend", Compose: "services:\n  web:\n    image: httpd:2.4"},
+		{Name: "api", Compose: "services:\n  api:\n    image: api:1.0\n    depends_on: [db]"},
+	}
+
+	if _, err := merger.Merge(&MergeRequest{Modules: modules}); err == nil {
+		t.Fatal("Expected merge to fail on the first issue without CollectDiagnostics")
+	}
+
+	result, err := merger.Merge(&MergeRequest{Modules: modules, CollectDiagnostics: true})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	expected := []MergeDiagnostic{
+		{Kind: MergeDiagnosticInvalidModule, Module: "broken"},
+		{Kind: MergeDiagnosticDuplicateService, Module: "frontend", Service: "frontend__web",
+			Message: "duplicate service key 'frontend__web' produced by modules 'frontend' and 'frontend'"},
+		{Kind: MergeDiagnosticDanglingReference, Module: "api", Service: "api__api",
+			Message: "Service 'api__api' depends on undefined service 'api__db'"},
+	}
+	if len(result.Diagnostics) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %+v", len(expected), result.Diagnostics)
+	}
+	for i, want := range expected {
+		got := result.Diagnostics[i]
+		if got.Kind != want.Kind || got.Module != want.Module || got.Service != want.Service {
+			t.Errorf("Diagnostic %d = %+v, want kind %s module %s service %s", i, got, want.Kind, want.Module, want.Service)
+		}
+		if want.Message != "" && got.Message != want.Message {
+			t.Errorf("Diagnostic %d message = %q, want %q", i, got.Message, want.Message)
+		}
+	}
+	if !strings.HasPrefix(result.Diagnostics[0].Message, "failed to parse compose for module broken") {
+		t.Errorf("Expected parse diagnostic to name the module, got %q", result.Diagnostics[0].Message)
+	}
+
+	// Valid parts are still merged; the duplicate keeps the first definition
+	if !strings.Contains(result.MergedCompose, "nginx:1.25.3") || strings.Contains(result.MergedCompose, "httpd:2.4") {
+		t.Errorf("Expected merged compose to keep the first frontend__web, got:\n%s", result.MergedCompose)
+	}
+	if !strings.Contains(result.MergedCompose, "api__api") {
+		t.Errorf("Expected merged compose to contain api__api, got:\n%s", result.MergedCompose)
+	}
+}
+
+func TestMerger_Merge_ComposeInterpolation(t *testing.T) {
+	merger := NewMerger()
+
+	tests := []struct {
+		name        string
+		image       string
+		moduleVars  map[string]string
+		serviceVars map[string]string
+		interpolate bool
+		want        string
+	}{
+		{name: "plain variable with value", image: "app:${PORT}", moduleVars: map[string]string{"PORT": "9090"}, want: "app:9090"},
+		{name: "plain variable without value", image: "app:${PORT}", want: "app:${PORT}"},
+		{name: "default with value", image: "app:${PORT:-8080}", moduleVars: map[string]string{"PORT": "9090"}, interpolate: true, want: "app:9090"},
+		{name: "default without value", image: "app:${PORT:-8080}", interpolate: true, want: "app:8080"},
+		{name: "default with empty value", image: "app:${PORT:-8080}", moduleVars: map[string]string{"PORT": ""}, interpolate: true, want: "app:8080"},
+		{name: "service variable wins", image: "app:${PORT:-8080}", moduleVars: map[string]string{"PORT": "9090"},
+			serviceVars: map[string]string{"PORT": "7070"}, interpolate: true, want: "app:7070"},
+		{name: "default left intact without the mode", image: "app:${PORT:-8080}", want: "app:${PORT:-8080}"},
+		{name: "unresolved and resolved together", image: "${REGISTRY}/app:${TAG:-1.0}", interpolate: true, want: "${REGISTRY}/app:1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := merger.Merge(&MergeRequest{
+				Modules: []Module{{
+					Name:      "web",
+					Compose:   "services:\n  app:\n    image: \"" + tt.image + "\"",
+					Variables: tt.moduleVars,
+				}},
+				ServiceVariables:     tt.serviceVars,
+				ComposeInterpolation: tt.interpolate,
+			})
+			if err != nil {
+				t.Fatalf("Merge failed: %v", err)
+			}
+
+			var merged struct {
+				Services map[string]struct {
+					Image string `yaml:"image"`
+				} `yaml:"services"`
+			}
+			if err := yaml.Unmarshal([]byte(result.MergedCompose), &merged); err != nil {
+				t.Fatalf("Failed to parse merged compose: %v", err)
+			}
+			if got := merged.Services["web__app"].Image; got != tt.want {
+				t.Errorf("image = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,34 +2,183 @@ package services
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/burndler/burndler/internal/storage"
 	"github.com/google/uuid"
 )
 
+// DefaultPackageKeyTemplate is the storage key layout used when none is configured
+const DefaultPackageKeyTemplate = "{name}-{build_id}"
+
+// Package archive formats
+const (
+	PackageFormatTarGz = "targz"
+	PackageFormatZip   = "zip"
+)
+
+// DefaultPackageMaxFileSize limits stored files loaded into a package when none is configured
+const DefaultPackageMaxFileSize = 10 * 1024 * 1024
+
 // Packager creates offline installer packages
 type Packager struct {
-	storage storage.Storage
+	storage          storage.Storage
+	keyTemplate      string
+	kubernetesOutput bool
+	maxFileSize      int64
+}
+
+// PackagerOptions configures optional Packager behavior
+type PackagerOptions struct {
+	// KeyTemplate is the storage key layout for packages, without extension.
+	// Supported placeholders: {name}, {service}, {build_id}, {date}
+	KeyTemplate string
+
+	// KubernetesOutput enables the experimental Kubernetes manifest output
+	KubernetesOutput bool
+
+	// MaxFileSize is the largest stored file, in bytes, that may be loaded
+	// into a package. Zero uses DefaultPackageMaxFileSize.
+	MaxFileSize int64
 }
 
 // NewPackager creates a new packager service
 func NewPackager(storage storage.Storage) *Packager {
+	return NewPackagerWithOptions(storage, PackagerOptions{})
+}
+
+// NewPackagerWithOptions creates a new packager service with the given options
+func NewPackagerWithOptions(storage storage.Storage, opts PackagerOptions) *Packager {
+	keyTemplate := opts.KeyTemplate
+	if keyTemplate == "" {
+		keyTemplate = DefaultPackageKeyTemplate
+	}
+	maxFileSize := opts.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = DefaultPackageMaxFileSize
+	}
+
 	return &Packager{
-		storage: storage,
+		storage:          storage,
+		keyTemplate:      keyTemplate,
+		kubernetesOutput: opts.KubernetesOutput,
+		maxFileSize:      maxFileSize,
 	}
 }
 
 // PackageRequest represents a package creation request
 type PackageRequest struct {
-	Name      string     `json:"name"`
-	Compose   string     `json:"compose"`
-	Resources []Resource `json:"resources"`
+	Name             string          `json:"name"`
+	Compose          string          `json:"compose"`
+	Resources        []Resource      `json:"resources"`
+	KubernetesOutput bool            `json:"kubernetes_output"`
+	IncludeOverride  bool            `json:"include_override"`
+	Format           string          `json:"format"` // targz (default) or zip
+	DownloadAssets   []DownloadAsset `json:"download_assets"`
+	Files            []PackageFile   `json:"-"`
+	BuildID          string          `json:"-"`
+	Service          string          `json:"-"`
+	Summary          *BuildSummary   `json:"-"` // adds BUILD_SUMMARY.md when set
+}
+
+// PackageFile is a stored file copied into the package at Path
+type PackageFile struct {
+	Path       string
+	StorageKey string
+}
+
+// DownloadAsset is a file the installer fetches at install time instead of
+// shipping it inside the package
+type DownloadAsset struct {
+	Path     string `json:"path"`
+	URL      string `json:"url"`
+	Checksum string `json:"checksum"`
+	Size     int64  `json:"size"`
+}
+
+// assetChecksumPattern matches a hex SHA-256 with an optional sha256: prefix
+var assetChecksumPattern = regexp.MustCompile(`^(sha256:)?[0-9a-fA-F]{64}$`)
+
+// ValidateDownloadAssets checks that every asset is written inside the
+// package directory, is fetched over HTTP(S) and carries a well-formed
+// checksum, since the values end up in the generated install scripts
+func ValidateDownloadAssets(assets []DownloadAsset) error {
+	for i, asset := range assets {
+		if err := validateAssetPath(asset.Path); err != nil {
+			return fmt.Errorf("download asset %d: %w", i, err)
+		}
+		if err := validateAssetURL(asset.URL); err != nil {
+			return fmt.Errorf("download asset %d: %w", i, err)
+		}
+		if asset.Checksum != "" && !assetChecksumPattern.MatchString(asset.Checksum) {
+			return fmt.Errorf("download asset %d: checksum must be a hex SHA-256, optionally prefixed with sha256:", i)
+		}
+	}
+	return nil
+}
+
+// validateAssetPath rejects paths that could resolve outside the package
+// directory on Linux or Windows
+func validateAssetPath(assetPath string) error {
+	if assetPath == "" {
+		return fmt.Errorf("path is required")
+	}
+	if !isPrintableASCII(assetPath) {
+		return fmt.Errorf("path must be printable ASCII")
+	}
+	if strings.ContainsAny(assetPath, `\:`) {
+		return fmt.Errorf("path must use forward slashes and no drive letters")
+	}
+	if path.IsAbs(assetPath) {
+		return fmt.Errorf("path must be relative to the package directory")
+	}
+	for _, segment := range strings.Split(assetPath, "/") {
+		if segment == ".." {
+			return fmt.Errorf("path must not contain '..'")
+		}
+	}
+	if cleaned := path.Clean(assetPath); cleaned == "." || strings.HasSuffix(assetPath, "/") {
+		return fmt.Errorf("path must name a file")
+	}
+	return nil
+}
+
+// validateAssetURL only accepts absolute http and https URLs
+func validateAssetURL(assetURL string) error {
+	if !isPrintableASCII(assetURL) {
+		return fmt.Errorf("url must be printable ASCII")
+	}
+	parsed, err := url.Parse(assetURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an http or https URL")
+	}
+	return nil
+}
+
+// isPrintableASCII reports whether a value only holds printable ASCII
+// characters, which every generated script can quote safely
+func isPrintableASCII(value string) bool {
+	for _, r := range value {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
 }
 
 // Resource represents a static resource to include
@@ -41,12 +190,16 @@ type Resource struct {
 
 // PackageManifest represents the manifest.json content
 type PackageManifest struct {
-	Name      string            `json:"name"`
-	Version   string            `json:"version"`
-	CreatedAt time.Time         `json:"created_at"`
-	Images    []ImageInfo       `json:"images"`
-	Resources []ResourceInfo    `json:"resources"`
-	Checksums map[string]string `json:"checksums"`
+	Name           string            `json:"name"`
+	Version        string            `json:"version"`
+	Service        string            `json:"service"`
+	BuildID        string            `json:"build_id"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Images         []ImageInfo       `json:"images"`
+	Resources      []ResourceInfo    `json:"resources"`
+	ResourcePaths  []string          `json:"resource_paths"`
+	DownloadAssets []DownloadAsset   `json:"download_assets"`
+	Checksums      map[string]string `json:"checksums"`
 }
 
 // ImageInfo represents Docker image metadata
@@ -64,104 +217,366 @@ type ResourceInfo struct {
 	Files   []string `json:"files"`
 }
 
-// CreatePackage builds an offline installer package
+// PackageResult describes an uploaded package
+type PackageResult struct {
+	URL      string
+	Key      string // storage key of the archive
+	Checksum string // hex-encoded SHA-256 of the archive
+	Size     int64
+	Summary  string // BUILD_SUMMARY.md content, when requested
+}
+
+// CreatePackage builds an offline installer package and returns its URL
 func (p *Packager) CreatePackage(ctx context.Context, req *PackageRequest) (string, error) {
-	buildID := uuid.New().String()
-	packageName := fmt.Sprintf("%s-%s.tar.gz", req.Name, buildID)
+	result, err := p.BuildPackage(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
 
-	// Create manifest
-	manifest := PackageManifest{
-		Name:      req.Name,
-		Version:   "1.0.0",
-		CreatedAt: time.Now(),
-		Images:    []ImageInfo{},
-		Resources: []ResourceInfo{},
-		Checksums: make(map[string]string),
+// BuildPackage builds and uploads an offline installer package, reporting
+// the checksum and size of the archive
+func (p *Packager) BuildPackage(ctx context.Context, req *PackageRequest) (*PackageResult, error) {
+	if req.KubernetesOutput && !p.kubernetesOutput {
+		return nil, fmt.Errorf("kubernetes output is not enabled")
+	}
+	if err := ValidateDownloadAssets(req.DownloadAssets); err != nil {
+		return nil, err
 	}
 
-	// Create tar.gz buffer
+	buildID := req.BuildID
+	if buildID == "" {
+		buildID = uuid.New().String()
+	}
+	packageName, err := p.storageKey(req, buildID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	// Create archive buffer
 	var buf bytes.Buffer
-	gzWriter := gzip.NewWriter(&buf)
-	tarWriter := tar.NewWriter(gzWriter)
+	formatArchive, extension, err := newPackageArchive(req.Format, &buf)
+	if err != nil {
+		return nil, err
+	}
+	packageName += extension
+	archive := &recordingArchive{packageArchive: formatArchive}
+
+	// Create manifest
+	service := req.Service
+	if service == "" {
+		service = req.Name
+	}
+	manifest := PackageManifest{
+		Name:           req.Name,
+		Version:        "1.0.0",
+		Service:        service,
+		BuildID:        buildID,
+		CreatedAt:      time.Now(),
+		Images:         []ImageInfo{},
+		Resources:      []ResourceInfo{},
+		ResourcePaths:  []string{},
+		DownloadAssets: []DownloadAsset{},
+		Checksums:      make(map[string]string),
+	}
 
 	// Add compose file
-	if err := p.addFileToTar(tarWriter, "compose/docker-compose.yaml", []byte(req.Compose)); err != nil {
-		return "", fmt.Errorf("failed to add compose file: %w", err)
+	if err := archive.AddFile("compose/docker-compose.yaml", []byte(req.Compose)); err != nil {
+		return nil, fmt.Errorf("failed to add compose file: %w", err)
+	}
+
+	// Add docker-compose.override.yaml template
+	if req.IncludeOverride {
+		if err := archive.AddFile("compose/docker-compose.override.yaml", []byte(p.generateOverrideTemplate())); err != nil {
+			return nil, fmt.Errorf("failed to add override template: %w", err)
+		}
+	}
+
+	// Add stored files such as compose secret files
+	for _, file := range req.Files {
+		content, err := p.downloadFile(ctx, file.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", file.Path, err)
+		}
+		if err := archive.AddFile(file.Path, content); err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", file.Path, err)
+		}
+		manifest.ResourcePaths = append(manifest.ResourcePaths, file.Path)
 	}
 
 	// Add .env.example
 	envExample := p.generateEnvExample()
-	if err := p.addFileToTar(tarWriter, "env/.env.example", []byte(envExample)); err != nil {
-		return "", fmt.Errorf("failed to add .env.example: %w", err)
+	if err := archive.AddFile("env/.env.example", []byte(envExample)); err != nil {
+		return nil, fmt.Errorf("failed to add .env.example: %w", err)
 	}
 
 	// Add install.sh
-	installScript := p.generateInstallScript()
-	if err := p.addFileToTar(tarWriter, "bin/install.sh", []byte(installScript)); err != nil {
-		return "", fmt.Errorf("failed to add install.sh: %w", err)
+	installScript := p.generateInstallScript(req.DownloadAssets)
+	if err := archive.AddFile("bin/install.sh", []byte(installScript)); err != nil {
+		return nil, fmt.Errorf("failed to add install.sh: %w", err)
+	}
+
+	// Add install.ps1
+	installPowerShell := p.generateInstallPowerShell(req.DownloadAssets)
+	if err := archive.AddFile("bin/install.ps1", []byte(installPowerShell)); err != nil {
+		return nil, fmt.Errorf("failed to add install.ps1: %w", err)
 	}
 
 	// Add verify.sh
 	verifyScript := p.generateVerifyScript()
-	if err := p.addFileToTar(tarWriter, "bin/verify.sh", []byte(verifyScript)); err != nil {
-		return "", fmt.Errorf("failed to add verify.sh: %w", err)
+	if err := archive.AddFile("bin/verify.sh", []byte(verifyScript)); err != nil {
+		return nil, fmt.Errorf("failed to add verify.sh: %w", err)
+	}
+
+	// Add experimental Kubernetes manifests
+	if req.KubernetesOutput {
+		converted, err := NewKubernetesConverter().Convert(req.Compose)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert to kubernetes: %w", err)
+		}
+		if err := archive.AddFile("kubernetes/manifests.yaml", []byte(p.kubernetesHeader(converted.Warnings)+converted.Manifests)); err != nil {
+			return nil, fmt.Errorf("failed to add kubernetes manifests: %w", err)
+		}
 	}
 
 	// Add resources
 	for _, resource := range req.Resources {
 		manifest.Resources = append(manifest.Resources, ResourceInfo(resource))
 	}
+	manifest.DownloadAssets = append(manifest.DownloadAssets, req.DownloadAssets...)
 
 	// Add manifest.json
 	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal manifest: %w", err)
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
 	}
-	if err := p.addFileToTar(tarWriter, "manifest.json", manifestJSON); err != nil {
-		return "", fmt.Errorf("failed to add manifest: %w", err)
+	if err := archive.AddFile("manifest.json", manifestJSON); err != nil {
+		return nil, fmt.Errorf("failed to add manifest: %w", err)
 	}
 
-	// Close tar and gzip writers
-	if err := tarWriter.Close(); err != nil {
-		return "", fmt.Errorf("failed to close tar writer: %w", err)
+	// Add BUILD_SUMMARY.md
+	var summaryMarkdown string
+	if req.Summary != nil {
+		summary := *req.Summary
+		summary.Files = append(append([]string{}, archive.names...), BuildSummaryFile)
+		summary.EmbeddedAssets = []string{}
+		for _, file := range req.Files {
+			summary.EmbeddedAssets = append(summary.EmbeddedAssets, file.Path)
+		}
+		summary.DownloadAssets = req.DownloadAssets
+		summaryMarkdown = summary.Markdown()
+		if err := archive.AddFile(BuildSummaryFile, []byte(summaryMarkdown)); err != nil {
+			return nil, fmt.Errorf("failed to add build summary: %w", err)
+		}
 	}
-	if err := gzWriter.Close(); err != nil {
-		return "", fmt.Errorf("failed to close gzip writer: %w", err)
+
+	// Close archive writers
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
 	}
 
 	// Upload to storage
+	checksum := sha256.Sum256(buf.Bytes())
 	reader := bytes.NewReader(buf.Bytes())
 	url, err := p.storage.Upload(ctx, packageName, reader, int64(buf.Len()))
 	if err != nil {
-		return "", fmt.Errorf("failed to upload package: %w", err)
+		return nil, fmt.Errorf("failed to upload package: %w", err)
 	}
 
-	return url, nil
+	return &PackageResult{
+		URL:      url,
+		Key:      packageName,
+		Checksum: hex.EncodeToString(checksum[:]),
+		Size:     int64(buf.Len()),
+		Summary:  summaryMarkdown,
+	}, nil
+}
+
+// DeletePackage removes an uploaded package from storage
+func (p *Packager) DeletePackage(ctx context.Context, key string) error {
+	return p.storage.Delete(ctx, key)
 }
 
-// addFileToTar adds a file to the tar archive
-func (p *Packager) addFileToTar(tw *tar.Writer, name string, content []byte) error {
+// storageKey renders the configured key template with sanitized build metadata
+func (p *Packager) storageKey(req *PackageRequest, buildID string, now time.Time) (string, error) {
+	service := req.Service
+	if service == "" {
+		service = req.Name
+	}
+
+	replacer := strings.NewReplacer(
+		"{name}", sanitizeKeySegment(req.Name),
+		"{service}", sanitizeKeySegment(service),
+		"{build_id}", sanitizeKeySegment(buildID),
+		"{date}", now.UTC().Format("2006-01-02"),
+	)
+
+	key := path.Clean("/" + replacer.Replace(p.keyTemplate))
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || key == "." {
+		return "", fmt.Errorf("package key template %q rendered an empty key", p.keyTemplate)
+	}
+
+	return key, nil
+}
+
+var unsafeKeyChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sanitizeKeySegment makes a metadata value safe to use as a single path segment
+func sanitizeKeySegment(value string) string {
+	value = unsafeKeyChars.ReplaceAllString(value, "-")
+	value = strings.Trim(value, ".-")
+	if value == "" {
+		return "unnamed"
+	}
+	return value
+}
+
+// kubernetesHeader lists conversion warnings as YAML comments
+func (p *Packager) kubernetesHeader(warnings []string) string {
+	var b strings.Builder
+	b.WriteString("# Generated by Burndler (experimental Kubernetes output)\n")
+	for _, warning := range warnings {
+		b.WriteString("# WARNING: " + warning + "\n")
+	}
+	return b.String()
+}
+
+// downloadFile reads a stored file into memory. Missing files and files
+// larger than the configured maximum are refused before they are read.
+func (p *Packager) downloadFile(ctx context.Context, key string) ([]byte, error) {
+	exists, err := p.storage.Exists(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("file not found in storage: %s", key)
+	}
+
+	reader, err := p.storage.Download(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, p.maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > p.maxFileSize {
+		return nil, fmt.Errorf("file exceeds the maximum size of %d bytes", p.maxFileSize)
+	}
+	return content, nil
+}
+
+// packageArchive writes package entries in one archive format
+type packageArchive interface {
+	AddFile(name string, content []byte) error
+	Close() error
+}
+
+// recordingArchive remembers the names of the entries written to an archive
+type recordingArchive struct {
+	packageArchive
+	names []string
+}
+
+// AddFile adds a file to the underlying archive and records its name
+func (a *recordingArchive) AddFile(name string, content []byte) error {
+	if err := a.packageArchive.AddFile(name, content); err != nil {
+		return err
+	}
+	a.names = append(a.names, name)
+	return nil
+}
+
+// newPackageArchive returns an archive writer for the format and the file
+// extension its packages are stored with
+func newPackageArchive(format string, w io.Writer) (packageArchive, string, error) {
+	switch format {
+	case "", PackageFormatTarGz:
+		gzWriter := gzip.NewWriter(w)
+		return &tarGzArchive{gz: gzWriter, tw: tar.NewWriter(gzWriter)}, ".tar.gz", nil
+	case PackageFormatZip:
+		return &zipArchive{zw: zip.NewWriter(w)}, ".zip", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported package format: %s", format)
+	}
+}
+
+// packageFileMode returns the permissions for a package entry
+func packageFileMode(name string) int64 {
+	// Make scripts executable
+	if name == "bin/install.sh" || name == "bin/verify.sh" {
+		return 0755
+	}
+	return 0644
+}
+
+// tarGzArchive writes a gzip-compressed tar archive
+type tarGzArchive struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+// AddFile adds a file to the tar archive
+func (a *tarGzArchive) AddFile(name string, content []byte) error {
 	header := &tar.Header{
 		Name:    name,
-		Mode:    0644,
+		Mode:    packageFileMode(name),
 		Size:    int64(len(content)),
 		ModTime: time.Now(),
 	}
 
-	// Make scripts executable
-	if name == "bin/install.sh" || name == "bin/verify.sh" {
-		header.Mode = 0755
+	if err := a.tw.WriteHeader(header); err != nil {
+		return err
 	}
 
-	if err := tw.WriteHeader(header); err != nil {
+	if _, err := a.tw.Write(content); err != nil {
 		return err
 	}
 
-	if _, err := tw.Write(content); err != nil {
+	return nil
+}
+
+// Close flushes the tar and gzip writers
+func (a *tarGzArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return fmt.Errorf("failed to close tar writer: %w", err)
+	}
+	if err := a.gz.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+	return nil
+}
+
+// zipArchive writes a deflate-compressed zip archive
+type zipArchive struct {
+	zw *zip.Writer
+}
+
+// AddFile adds a file to the zip archive
+func (a *zipArchive) AddFile(name string, content []byte) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	}
+	header.SetMode(os.FileMode(packageFileMode(name)))
+
+	writer, err := a.zw.CreateHeader(header)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = writer.Write(content)
+	return err
+}
+
+// Close finishes the zip central directory
+func (a *zipArchive) Close() error {
+	return a.zw.Close()
 }
 
 // generateEnvExample creates a template .env file
@@ -183,9 +598,11 @@ SERVER_PORT=8080
 `
 }
 
-// generateInstallScript creates the installation script
-func (p *Packager) generateInstallScript() string {
-	return `#!/bin/bash
+// generateInstallScript creates the installation script, fetching and
+// verifying download assets before anything else uses them
+func (p *Packager) generateInstallScript(assets []DownloadAsset) string {
+	var script strings.Builder
+	script.WriteString(`#!/bin/bash
 set -e
 
 echo "Burndler Offline Installer"
@@ -203,7 +620,44 @@ if ! command -v docker-compose &> /dev/null; then
     echo "ERROR: Docker Compose is not installed"
     exit 1
 fi
+`)
+
+	if len(assets) > 0 {
+		script.WriteString(`
+if ! command -v curl &> /dev/null; then
+    echo "ERROR: curl is required to fetch download assets"
+    exit 1
+fi
 
+# Fetch download assets
+fetch_asset() {
+    local path="$1" url="$2" checksum="$3"
+    echo "Fetching $path..."
+    mkdir -p "$(dirname "$path")"
+    curl -fsSL -o "$path" "$url"
+    if [ -z "$checksum" ]; then
+        echo "WARNING: no checksum for $path, skipping verification"
+        return
+    fi
+    local expected="${checksum#sha256:}"
+    local actual
+    actual=$(sha256sum "$path" | awk '{print $1}')
+    if [ "$actual" != "$expected" ]; then
+        echo "ERROR: checksum mismatch for $path (expected $expected, got $actual)" >&2
+        rm -f "$path"
+        exit 1
+    fi
+}
+
+echo "Fetching download assets..."
+`)
+		for _, asset := range assets {
+			fmt.Fprintf(&script, "fetch_asset %s %s %s\n",
+				shellQuote(asset.Path), shellQuote(asset.URL), shellQuote(asset.Checksum))
+		}
+	}
+
+	script.WriteString(`
 # Load images
 echo "Loading Docker images..."
 for image in images/*.tar; do
@@ -230,7 +684,12 @@ fi
 # Start services
 echo "Starting services..."
 cd compose
-docker-compose up -d
+if [ -f "docker-compose.override.yaml" ]; then
+    echo "Applying docker-compose.override.yaml..."
+    docker-compose -f docker-compose.yaml -f docker-compose.override.yaml up -d
+else
+    docker-compose up -d
+fi
 
 # Wait for health checks
 echo "Waiting for services to be healthy..."
@@ -238,6 +697,126 @@ sleep 10
 
 echo "Installation complete!"
 echo "Access the application at http://localhost:8080"
+`)
+	return script.String()
+}
+
+// generateInstallPowerShell creates the Windows installation script
+func (p *Packager) generateInstallPowerShell(assets []DownloadAsset) string {
+	var script strings.Builder
+	script.WriteString(`$ErrorActionPreference = "Stop"
+
+Write-Host "Burndler Offline Installer"
+Write-Host "=========================="
+
+# Check prerequisites
+Write-Host "Checking prerequisites..."
+
+if (-not (Get-Command docker -ErrorAction SilentlyContinue)) {
+    throw "Docker is not installed"
+}
+`)
+
+	if len(assets) > 0 {
+		script.WriteString(`
+# Fetch download assets
+function Get-Asset([string]$Path, [string]$Url, [string]$Checksum) {
+    Write-Host "Fetching $Path..."
+    $dir = Split-Path -Parent $Path
+    if ($dir) { New-Item -ItemType Directory -Force -Path $dir | Out-Null }
+    Invoke-WebRequest -UseBasicParsing -Uri $Url -OutFile $Path
+    if (-not $Checksum) {
+        Write-Warning "No checksum for $Path, skipping verification"
+        return
+    }
+    $expected = ($Checksum -replace '^sha256:', '').ToLower()
+    $actual = (Get-FileHash -Algorithm SHA256 -Path $Path).Hash.ToLower()
+    if ($actual -ne $expected) {
+        Remove-Item -Force $Path
+        throw "Checksum mismatch for $Path (expected $expected, got $actual)"
+    }
+}
+
+Write-Host "Fetching download assets..."
+`)
+		for _, asset := range assets {
+			fmt.Fprintf(&script, "Get-Asset %s %s %s\n",
+				powerShellQuote(asset.Path), powerShellQuote(asset.URL), powerShellQuote(asset.Checksum))
+		}
+	}
+
+	script.WriteString(`
+# Load images
+Write-Host "Loading Docker images..."
+Get-ChildItem -Path images -Filter *.tar -ErrorAction SilentlyContinue | ForEach-Object {
+    Write-Host "Loading $($_.FullName)..."
+    docker load -i $_.FullName
+}
+
+# Setup environment
+if (-not (Test-Path .env)) {
+    Write-Host "Creating .env from template..."
+    Copy-Item env/.env.example .env
+    Write-Host "Please edit .env with your configuration"
+}
+
+# Start services
+Write-Host "Starting services..."
+Set-Location compose
+if (Test-Path docker-compose.override.yaml) {
+    Write-Host "Applying docker-compose.override.yaml..."
+    docker compose -f docker-compose.yaml -f docker-compose.override.yaml up -d
+} else {
+    docker compose up -d
+}
+if ($LASTEXITCODE -ne 0) {
+    throw "docker compose up failed"
+}
+
+Write-Host "Installation complete!"
+Write-Host "Access the application at http://localhost:8080"
+`)
+	return script.String()
+}
+
+// shellQuote quotes a value for use as a single bash argument
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// powerShellSingleQuotes are the characters PowerShell accepts as single
+// quotes; each is escaped by doubling it
+var powerShellSingleQuotes = strings.NewReplacer(
+	"'", "''",
+	"\u2018", "\u2018\u2018",
+	"\u2019", "\u2019\u2019",
+	"\u201a", "\u201a\u201a",
+	"\u201b", "\u201b\u201b",
+)
+
+// powerShellQuote quotes a value as a PowerShell single-quoted string
+func powerShellQuote(value string) string {
+	return "'" + powerShellSingleQuotes.Replace(value) + "'"
+}
+
+// generateOverrideTemplate creates a commented compose override template
+// that operators can edit after delivery without touching the main file
+func (p *Packager) generateOverrideTemplate() string {
+	return `# docker-compose.override.yaml
+#
+# Local customizations applied on top of docker-compose.yaml by install.sh.
+# Only the keys listed here are changed; everything else comes from the main
+# compose file. Examples:
+#
+# services:
+#   web:
+#     ports:
+#       - "9090:80"
+#     environment:
+#       LOG_LEVEL: debug
+#     env_file:
+#       - ../.env
+services: {}
 `
 }
 
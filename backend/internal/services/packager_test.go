@@ -1,14 +1,21 @@
 package services
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/burndler/burndler/internal/storage"
+	"gopkg.in/yaml.v3"
 )
 
 // MockStorage implements storage.Storage for testing
@@ -19,6 +26,9 @@ type MockStorage struct {
 	UploadError    error
 	DownloadError  error
 	DeleteError    error
+	MissingKeys    map[string]bool
+	Uploaded       []byte
+	Deleted        []string
 }
 
 func (m *MockStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64) (string, error) {
@@ -26,6 +36,11 @@ func (m *MockStorage) Upload(ctx context.Context, key string, reader io.Reader,
 	if m.UploadError != nil {
 		return "", m.UploadError
 	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	m.Uploaded = data
 	return "http://mock-storage/" + key, nil
 }
 
@@ -39,11 +54,15 @@ func (m *MockStorage) Download(ctx context.Context, key string) (io.ReadCloser,
 
 func (m *MockStorage) Delete(ctx context.Context, key string) error {
 	m.DeleteCalled = true
-	return m.DeleteError
+	if m.DeleteError != nil {
+		return m.DeleteError
+	}
+	m.Deleted = append(m.Deleted, key)
+	return nil
 }
 
 func (m *MockStorage) Exists(ctx context.Context, key string) (bool, error) {
-	return true, nil
+	return !m.MissingKeys[key], nil
 }
 
 func (m *MockStorage) List(ctx context.Context, prefix string) ([]storage.FileInfo, error) {
@@ -123,3 +142,474 @@ services:
 	// Even on error, we might get a partial path
 	_ = packagePath
 }
+
+// Test CreatePackage stores the package under the configured key template
+func TestPackager_CreatePackage_KeyTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		req      *PackageRequest
+		want     string
+	}{
+		{
+			name:     "default layout",
+			template: "",
+			req:      &PackageRequest{Name: "demo", BuildID: "b-1"},
+			want:     "demo-b-1.tar.gz",
+		},
+		{
+			name:     "nested layout with service and date",
+			template: "builds/{service}/{date}/{build_id}",
+			req:      &PackageRequest{Name: "demo", Service: "billing", BuildID: "b-2"},
+			want:     "builds/billing/" + time.Now().UTC().Format("2006-01-02") + "/b-2.tar.gz",
+		},
+		{
+			name:     "service falls back to name",
+			template: "builds/{service}/{build_id}",
+			req:      &PackageRequest{Name: "demo", BuildID: "b-3"},
+			want:     "builds/demo/b-3.tar.gz",
+		},
+		{
+			name:     "metadata is sanitized",
+			template: "/builds/{service}/{name}",
+			req:      &PackageRequest{Name: "../../etc/passwd", Service: "my service", BuildID: "b-4"},
+			want:     "builds/my-service/etc-passwd.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &MockStorage{}
+			packager := NewPackagerWithOptions(mockStorage, PackagerOptions{KeyTemplate: tt.template})
+
+			url, err := packager.CreatePackage(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("CreatePackage failed: %v", err)
+			}
+
+			if url != "http://mock-storage/"+tt.want {
+				t.Errorf("CreatePackage stored at %q, want %q", url, "http://mock-storage/"+tt.want)
+			}
+		})
+	}
+}
+
+// Test CreatePackage rejects Kubernetes output unless the feature flag is on
+func TestPackager_CreatePackage_KubernetesOutput(t *testing.T) {
+	req := &PackageRequest{
+		Name: "demo",
+		Compose: `services:
+  web:
+    image: nginx:latest
+    ports:
+      - "8080:80"`,
+		KubernetesOutput: true,
+	}
+
+	disabled := NewPackager(&MockStorage{})
+	if _, err := disabled.CreatePackage(context.Background(), req); err == nil {
+		t.Error("Expected error when kubernetes output is disabled")
+	}
+
+	mockStorage := &MockStorage{}
+	enabled := NewPackagerWithOptions(mockStorage, PackagerOptions{KubernetesOutput: true})
+	if _, err := enabled.CreatePackage(context.Background(), req); err != nil {
+		t.Fatalf("CreatePackage failed: %v", err)
+	}
+	if !mockStorage.UploadCalled {
+		t.Error("Expected package to be uploaded")
+	}
+}
+
+// packageFiles extracts the file contents of an uploaded tar.gz package
+func packageFiles(t *testing.T, data []byte) map[string]string {
+	gzReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to open package: %v", err)
+	}
+	tarReader := tar.NewReader(gzReader)
+
+	files := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read package: %v", err)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", header.Name, err)
+		}
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+// Test CreatePackage with the docker-compose.override.yaml template
+func TestPackager_CreatePackage_IncludeOverride(t *testing.T) {
+	compose := `services:
+  web:
+    image: nginx:1.25.3`
+
+	mockStorage := &MockStorage{}
+	packager := NewPackager(mockStorage)
+
+	if _, err := packager.CreatePackage(context.Background(), &PackageRequest{Name: "demo", Compose: compose}); err != nil {
+		t.Fatalf("CreatePackage failed: %v", err)
+	}
+	if _, ok := packageFiles(t, mockStorage.Uploaded)["compose/docker-compose.override.yaml"]; ok {
+		t.Error("Expected no override template unless requested")
+	}
+
+	if _, err := packager.CreatePackage(context.Background(), &PackageRequest{Name: "demo", Compose: compose, IncludeOverride: true}); err != nil {
+		t.Fatalf("CreatePackage failed: %v", err)
+	}
+	files := packageFiles(t, mockStorage.Uploaded)
+
+	override, ok := files["compose/docker-compose.override.yaml"]
+	if !ok {
+		t.Fatal("Expected compose/docker-compose.override.yaml in package")
+	}
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(override), &parsed); err != nil {
+		t.Fatalf("Override template is not valid YAML: %v", err)
+	}
+	if _, ok := parsed["services"]; !ok {
+		t.Error("Expected override template to define services")
+	}
+
+	if !strings.Contains(files["bin/install.sh"], "-f docker-compose.yaml -f docker-compose.override.yaml") {
+		t.Error("Expected install.sh to apply docker-compose.override.yaml")
+	}
+}
+
+// Test CreatePackage copies stored files into the package
+func TestPackager_CreatePackage_Files(t *testing.T) {
+	mockStorage := &MockStorage{}
+	packager := NewPackager(mockStorage)
+
+	_, err := packager.CreatePackage(context.Background(), &PackageRequest{
+		Name:    "demo",
+		Compose: "services:\n  web:\n    image: nginx:1.25.3",
+		Files: []PackageFile{
+			{Path: "secrets/db/db_password.txt", StorageKey: "containers/db/v1/db_password.txt"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreatePackage failed: %v", err)
+	}
+	if !mockStorage.DownloadCalled {
+		t.Error("Expected stored file to be downloaded")
+	}
+
+	files := packageFiles(t, mockStorage.Uploaded)
+	if files["secrets/db/db_password.txt"] != "mock content" {
+		t.Errorf("Expected secrets/db/db_password.txt with stored content, got %q", files["secrets/db/db_password.txt"])
+	}
+
+	mockStorage.MissingKeys = map[string]bool{"containers/db/v1/missing.txt": true}
+	mockStorage.DownloadCalled = false
+	_, err = packager.CreatePackage(context.Background(), &PackageRequest{
+		Name:    "demo",
+		Compose: "services: {}",
+		Files:   []PackageFile{{Path: "secrets/db/missing.txt", StorageKey: "containers/db/v1/missing.txt"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "file not found in storage: containers/db/v1/missing.txt") {
+		t.Errorf("Expected file not found error, got %v", err)
+	}
+	if mockStorage.DownloadCalled {
+		t.Error("Expected missing file not to be downloaded")
+	}
+
+	mockStorage.MissingKeys = nil
+	mockStorage.DownloadError = errors.New("connection reset")
+	if _, err := packager.CreatePackage(context.Background(), &PackageRequest{
+		Name:    "demo",
+		Compose: "services: {}",
+		Files:   []PackageFile{{Path: "secrets/db/key.pem", StorageKey: "containers/db/v1/key.pem"}},
+	}); err == nil {
+		t.Error("Expected error when a stored file cannot be downloaded")
+	}
+}
+
+// Test CreatePackage enforces the maximum stored file size
+func TestPackager_CreatePackage_MaxFileSize(t *testing.T) {
+	content := "mock content" // returned by MockStorage.Download
+	req := &PackageRequest{
+		Name:    "demo",
+		Compose: "services: {}",
+		Files:   []PackageFile{{Path: "secrets/db/key.pem", StorageKey: "containers/db/v1/key.pem"}},
+	}
+
+	atLimit := NewPackagerWithOptions(&MockStorage{}, PackagerOptions{MaxFileSize: int64(len(content))})
+	if _, err := atLimit.CreatePackage(context.Background(), req); err != nil {
+		t.Errorf("Expected file at the size limit to be packaged, got %v", err)
+	}
+
+	mockStorage := &MockStorage{}
+	overLimit := NewPackagerWithOptions(mockStorage, PackagerOptions{MaxFileSize: int64(len(content) - 1)})
+	_, err := overLimit.CreatePackage(context.Background(), req)
+	if err == nil {
+		t.Fatal("Expected error for file over the size limit")
+	}
+	if !strings.Contains(err.Error(), "secrets/db/key.pem") || !strings.Contains(err.Error(), "maximum size of 11 bytes") {
+		t.Errorf("Expected error naming the file and limit, got %v", err)
+	}
+	if mockStorage.UploadCalled {
+		t.Error("Expected no upload when a file is too large")
+	}
+}
+
+// zipPackageFiles extracts the file contents and modes of an uploaded zip package
+func zipPackageFiles(t *testing.T, data []byte) (map[string]string, map[string]os.FileMode) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to open package: %v", err)
+	}
+
+	files := make(map[string]string)
+	modes := make(map[string]os.FileMode)
+	for _, file := range zipReader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+		files[file.Name] = string(content)
+		modes[file.Name] = file.Mode()
+	}
+	return files, modes
+}
+
+// Test CreatePackage writes the same layout as tar.gz and zip
+func TestPackager_CreatePackage_Format(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx:1.25.3"
+	expected := []string{
+		"compose/docker-compose.yaml",
+		"env/.env.example",
+		"bin/install.sh",
+		"bin/verify.sh",
+		"manifest.json",
+	}
+
+	t.Run("targz", func(t *testing.T) {
+		mockStorage := &MockStorage{}
+		packager := NewPackager(mockStorage)
+
+		url, err := packager.CreatePackage(context.Background(), &PackageRequest{Name: "demo", BuildID: "b-1", Compose: compose, Format: PackageFormatTarGz})
+		if err != nil {
+			t.Fatalf("CreatePackage failed: %v", err)
+		}
+		if url != "http://mock-storage/demo-b-1.tar.gz" {
+			t.Errorf("CreatePackage stored at %q, want a .tar.gz key", url)
+		}
+
+		files := packageFiles(t, mockStorage.Uploaded)
+		for _, name := range expected {
+			if _, ok := files[name]; !ok {
+				t.Errorf("Expected %s in tar.gz package", name)
+			}
+		}
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		mockStorage := &MockStorage{}
+		packager := NewPackager(mockStorage)
+
+		url, err := packager.CreatePackage(context.Background(), &PackageRequest{Name: "demo", BuildID: "b-2", Compose: compose, Format: PackageFormatZip})
+		if err != nil {
+			t.Fatalf("CreatePackage failed: %v", err)
+		}
+		if url != "http://mock-storage/demo-b-2.zip" {
+			t.Errorf("CreatePackage stored at %q, want a .zip key", url)
+		}
+
+		files, modes := zipPackageFiles(t, mockStorage.Uploaded)
+		for _, name := range expected {
+			if _, ok := files[name]; !ok {
+				t.Errorf("Expected %s in zip package", name)
+			}
+		}
+		if files["compose/docker-compose.yaml"] != compose {
+			t.Errorf("compose/docker-compose.yaml = %q, want %q", files["compose/docker-compose.yaml"], compose)
+		}
+		if modes["bin/install.sh"].Perm() != 0755 {
+			t.Errorf("bin/install.sh mode = %v, want 0755", modes["bin/install.sh"].Perm())
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		mockStorage := &MockStorage{}
+		packager := NewPackager(mockStorage)
+
+		if _, err := packager.CreatePackage(context.Background(), &PackageRequest{Name: "demo", Compose: compose, Format: "rar"}); err == nil {
+			t.Error("Expected error for an unsupported format")
+		}
+		if mockStorage.UploadCalled {
+			t.Error("Expected nothing to be uploaded for an unsupported format")
+		}
+	})
+}
+
+// Test CreatePackage records build metadata in manifest.json
+func TestPackager_CreatePackage_Manifest(t *testing.T) {
+	mockStorage := &MockStorage{}
+	packager := NewPackager(mockStorage)
+
+	assets := []DownloadAsset{
+		{Path: "images/app.tar", URL: "https://assets.example.com/app.tar", Checksum: "sha256:abababababababababababababababababababababababababababababababab", Size: 1048576},
+		{Path: "models/weights.bin", URL: "https://assets.example.com/weights.bin", Checksum: "sha256:dededededededededededededededededededededededededededededededede", Size: 2048},
+	}
+	_, err := packager.CreatePackage(context.Background(), &PackageRequest{
+		Name:    "demo",
+		Service: "billing",
+		BuildID: "b-42",
+		Compose: "services:\n  web:\n    image: nginx:1.25.3",
+		Files: []PackageFile{
+			{Path: "secrets/db/db_password.txt", StorageKey: "containers/db/v1/db_password.txt"},
+		},
+		DownloadAssets: assets,
+	})
+	if err != nil {
+		t.Fatalf("CreatePackage failed: %v", err)
+	}
+
+	var manifest PackageManifest
+	if err := json.Unmarshal([]byte(packageFiles(t, mockStorage.Uploaded)["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("Failed to parse manifest.json: %v", err)
+	}
+
+	if manifest.Service != "billing" || manifest.BuildID != "b-42" {
+		t.Errorf("manifest service/build = %s/%s, want billing/b-42", manifest.Service, manifest.BuildID)
+	}
+	if manifest.CreatedAt.IsZero() {
+		t.Error("Expected manifest to carry a generated timestamp")
+	}
+	if len(manifest.ResourcePaths) != 1 || manifest.ResourcePaths[0] != "secrets/db/db_password.txt" {
+		t.Errorf("manifest resource_paths = %v, want [secrets/db/db_password.txt]", manifest.ResourcePaths)
+	}
+	if len(manifest.DownloadAssets) != len(assets) {
+		t.Fatalf("manifest download_assets = %v, want %v", manifest.DownloadAssets, assets)
+	}
+	for i, asset := range assets {
+		if manifest.DownloadAssets[i] != asset {
+			t.Errorf("manifest download_assets[%d] = %+v, want %+v", i, manifest.DownloadAssets[i], asset)
+		}
+	}
+}
+
+// Test install scripts fetch and verify each download asset
+func TestPackager_CreatePackage_InstallScriptsFetchAssets(t *testing.T) {
+	mockStorage := &MockStorage{}
+	packager := NewPackager(mockStorage)
+
+	assets := []DownloadAsset{
+		{Path: "images/app.tar", URL: "https://assets.example.com/app.tar", Checksum: "sha256:abababababababababababababababababababababababababababababababab", Size: 1048576},
+		{Path: "models/it's.bin", URL: "https://assets.example.com/weights.bin", Checksum: "sha256:dededededededededededededededededededededededededededededededede", Size: 2048},
+	}
+	_, err := packager.CreatePackage(context.Background(), &PackageRequest{
+		Name:           "demo",
+		Compose:        "services:\n  web:\n    image: nginx:1.25.3",
+		DownloadAssets: assets,
+	})
+	if err != nil {
+		t.Fatalf("CreatePackage failed: %v", err)
+	}
+
+	files := packageFiles(t, mockStorage.Uploaded)
+	install, ok := files["bin/install.sh"]
+	if !ok {
+		t.Fatal("Expected bin/install.sh in package")
+	}
+	powerShell, ok := files["bin/install.ps1"]
+	if !ok {
+		t.Fatal("Expected bin/install.ps1 in package")
+	}
+
+	if !strings.Contains(install, "checksum mismatch") || !strings.Contains(install, "exit 1") {
+		t.Error("Expected install.sh to fail on checksum mismatch")
+	}
+	if !strings.Contains(powerShell, "Checksum mismatch") {
+		t.Error("Expected install.ps1 to fail on checksum mismatch")
+	}
+	if strings.Index(install, "fetch_asset 'images/app.tar'") > strings.Index(install, "up -d") {
+		t.Error("Expected install.sh to fetch assets before starting services")
+	}
+
+	wantSh := []string{
+		`fetch_asset 'images/app.tar' 'https://assets.example.com/app.tar' 'sha256:abababababababababababababababababababababababababababababababab'`,
+		`fetch_asset 'models/it'\''s.bin' 'https://assets.example.com/weights.bin' 'sha256:dededededededededededededededededededededededededededededededede'`,
+	}
+	for _, want := range wantSh {
+		if !strings.Contains(install, want) {
+			t.Errorf("Expected install.sh to contain %q", want)
+		}
+	}
+
+	wantPs := []string{
+		`Get-Asset 'images/app.tar' 'https://assets.example.com/app.tar' 'sha256:abababababababababababababababababababababababababababababababab'`,
+		`Get-Asset 'models/it''s.bin' 'https://assets.example.com/weights.bin' 'sha256:dededededededededededededededededededededededededededededededede'`,
+	}
+	for _, want := range wantPs {
+		if !strings.Contains(powerShell, want) {
+			t.Errorf("Expected install.ps1 to contain %q", want)
+		}
+	}
+}
+
+// Test download assets are rejected unless they stay inside the package,
+// use HTTP(S) and carry a well-formed checksum
+func TestValidateDownloadAssets(t *testing.T) {
+	checksum := strings.Repeat("a1", 32)
+	tests := []struct {
+		name    string
+		asset   DownloadAsset
+		wantErr bool
+	}{
+		{"relative path", DownloadAsset{Path: "images/app.tar", URL: "https://assets.example.com/app.tar", Checksum: "sha256:" + checksum}, false},
+		{"checksum without prefix", DownloadAsset{Path: "app.tar", URL: "http://assets.example.com/app.tar", Checksum: strings.ToUpper(checksum)}, false},
+		{"no checksum", DownloadAsset{Path: "app.tar", URL: "https://assets.example.com/app.tar"}, false},
+		{"parent directory", DownloadAsset{Path: "../../etc/cron.d/x", URL: "https://assets.example.com/x"}, true},
+		{"nested parent directory", DownloadAsset{Path: "images/../../x", URL: "https://assets.example.com/x"}, true},
+		{"absolute path", DownloadAsset{Path: "/usr/local/bin/docker", URL: "https://assets.example.com/docker"}, true},
+		{"windows absolute path", DownloadAsset{Path: `C:\Windows\x.exe`, URL: "https://assets.example.com/x.exe"}, true},
+		{"windows parent directory", DownloadAsset{Path: `..\x.exe`, URL: "https://assets.example.com/x.exe"}, true},
+		{"directory", DownloadAsset{Path: "images/", URL: "https://assets.example.com/x"}, true},
+		{"empty path", DownloadAsset{URL: "https://assets.example.com/x"}, true},
+		{"non-ASCII path", DownloadAsset{Path: "it\u2019s.bin", URL: "https://assets.example.com/x"}, true},
+		{"file URL", DownloadAsset{Path: "app.tar", URL: "file:///etc/passwd"}, true},
+		{"URL without host", DownloadAsset{Path: "app.tar", URL: "https:///app.tar"}, true},
+		{"short checksum", DownloadAsset{Path: "app.tar", URL: "https://assets.example.com/app.tar", Checksum: "sha256:abc123"}, true},
+		{"non-hex checksum", DownloadAsset{Path: "app.tar", URL: "https://assets.example.com/app.tar", Checksum: strings.Repeat("zz", 32)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDownloadAssets([]DownloadAsset{tt.asset})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDownloadAssets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Test every quote character PowerShell recognises is escaped
+func TestPowerShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":                 "'plain'",
+		"it's":                  "'it''s'",
+		"a\u2019; calc; \u2018": "'a\u2019\u2019; calc; \u2018\u2018'",
+		"\u201a\u201b":          "'\u201a\u201a\u201b\u201b'",
+	}
+	for value, want := range tests {
+		if got := powerShellQuote(value); got != want {
+			t.Errorf("powerShellQuote(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/burndler/burndler/internal/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ServiceExportVersion is the format version written by ExportServices
+const ServiceExportVersion = 1
+
+// ServiceExport is a portable document holding a user's service definitions.
+// Containers and versions are referenced by name so the document can be
+// imported into another installation.
+type ServiceExport struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Services   []ExportedService `json:"services"`
+}
+
+// ExportedService is the full definition of one exported service
+type ExportedService struct {
+	Name               string                     `json:"name"`
+	Description        string                     `json:"description"`
+	Variables          datatypes.JSON             `json:"variables,omitempty"`
+	EnvironmentVars    datatypes.JSON             `json:"environment_vars,omitempty"`
+	Active             bool                       `json:"active"`
+	Archived           bool                       `json:"archived"`
+	BuildRetentionDays *int                       `json:"build_retention_days,omitempty"`
+	Containers         []ExportedServiceContainer `json:"containers"`
+}
+
+// ExportedServiceContainer references a container version used by an exported service
+type ExportedServiceContainer struct {
+	Container    string         `json:"container"`
+	Version      string         `json:"version"`
+	Order        int            `json:"order"`
+	Enabled      bool           `json:"enabled"`
+	Pinned       bool           `json:"pinned"`
+	OverrideVars datatypes.JSON `json:"override_vars,omitempty"`
+}
+
+// ExportServices returns the definitions of all services owned by a user,
+// including archived ones
+func (s *ServiceService) ExportServices(userID uint) (*ServiceExport, error) {
+	var services []models.Service
+	if err := s.db.Where("user_id = ?", userID).
+		Preload("ServiceContainers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("\"order\"")
+		}).
+		Preload("ServiceContainers.Container").
+		Preload("ServiceContainers.ContainerVersion").
+		Order("name").
+		Find(&services).Error; err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	export := &ServiceExport{
+		Version:    ServiceExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Services:   []ExportedService{},
+	}
+	for _, service := range services {
+		exported := ExportedService{
+			Name:               service.Name,
+			Description:        service.Description,
+			Variables:          service.Variables,
+			EnvironmentVars:    service.EnvironmentVars,
+			Active:             service.Active,
+			Archived:           service.Archived,
+			BuildRetentionDays: service.BuildRetentionDays,
+			Containers:         []ExportedServiceContainer{},
+		}
+		for _, sc := range service.ServiceContainers {
+			exported.Containers = append(exported.Containers, ExportedServiceContainer{
+				Container:    sc.Container.Name,
+				Version:      sc.ContainerVersion.Version,
+				Order:        sc.Order,
+				Enabled:      sc.Enabled,
+				Pinned:       sc.Pinned,
+				OverrideVars: sc.OverrideVars,
+			})
+		}
+		export.Services = append(export.Services, exported)
+	}
+
+	return export, nil
+}
+
+// ImportServices creates services for a user from an export document. The
+// import is all or nothing: a name clash or a missing container version
+// rolls back every service in the document.
+func (s *ServiceService) ImportServices(userID uint, export *ServiceExport) ([]models.Service, error) {
+	if export.Version != ServiceExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+
+	imported := []models.Service{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, exported := range export.Services {
+			if exported.Name == "" {
+				return fmt.Errorf("name is required")
+			}
+			exists, err := serviceNameExists(tx, userID, exported.Name)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return fmt.Errorf("service with name '%s' already exists", exported.Name)
+			}
+			versions, problem, err := resolveImportVersions(tx, exported)
+			if err != nil {
+				return err
+			}
+			if problem != "" {
+				return errors.New(problem)
+			}
+
+			service := models.Service{
+				Name:               exported.Name,
+				Description:        exported.Description,
+				UserID:             userID,
+				Variables:          exported.Variables,
+				EnvironmentVars:    exported.EnvironmentVars,
+				Active:             exported.Active,
+				Archived:           exported.Archived,
+				BuildRetentionDays: exported.BuildRetentionDays,
+			}
+			if err := tx.Create(&service).Error; err != nil {
+				return fmt.Errorf("failed to create service: %w", err)
+			}
+			// Create skips false booleans in favour of column defaults
+			if err := tx.Model(&service).Updates(map[string]interface{}{
+				"active":   exported.Active,
+				"archived": exported.Archived,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to create service: %w", err)
+			}
+
+			for i, ec := range exported.Containers {
+				serviceContainer := models.ServiceContainer{
+					ServiceID:          service.ID,
+					ContainerID:        versions[i].ContainerID,
+					ContainerVersionID: versions[i].ID,
+					Order:              ec.Order,
+					Enabled:            ec.Enabled,
+					Pinned:             ec.Pinned,
+					OverrideVars:       ec.OverrideVars,
+				}
+				if err := tx.Create(&serviceContainer).Error; err != nil {
+					return fmt.Errorf("failed to add container to service: %w", err)
+				}
+				if !ec.Enabled {
+					if err := tx.Model(&serviceContainer).Update("enabled", false).Error; err != nil {
+						return fmt.Errorf("failed to add container to service: %w", err)
+					}
+				}
+			}
+
+			imported = append(imported, service)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return imported, nil
+}
+
+// ImportReportEntry describes what importing one service of a document
+// would do
+type ImportReportEntry struct {
+	Index  int    `json:"index"` // position in the document's services
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ImportReport is the outcome of ValidateImport
+type ImportReport struct {
+	// Valid is true when ImportServices would accept the whole document
+	Valid       bool                `json:"valid"`
+	WouldImport []ImportReportEntry `json:"would_import"`
+	Skipped     []ImportReportEntry `json:"skipped"` // name already taken by an existing service
+	Invalid     []ImportReportEntry `json:"invalid"`
+}
+
+// ValidateImport runs every lookup and check of ImportServices for each
+// service in the document without writing anything, and reports which
+// services would be imported, skipped because they already exist, or
+// rejected. Since imports are all or nothing, ImportServices only succeeds
+// when every service would be imported.
+func (s *ServiceService) ValidateImport(userID uint, export *ServiceExport) (*ImportReport, error) {
+	if export.Version != ServiceExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+
+	report := &ImportReport{
+		WouldImport: []ImportReportEntry{},
+		Skipped:     []ImportReportEntry{},
+		Invalid:     []ImportReportEntry{},
+	}
+	seen := make(map[string]bool)
+	for i, exported := range export.Services {
+		entry := ImportReportEntry{Index: i, Name: exported.Name}
+		if exported.Name == "" {
+			entry.Reason = "name is required"
+			report.Invalid = append(report.Invalid, entry)
+			continue
+		}
+		if seen[exported.Name] {
+			entry.Reason = fmt.Sprintf("service '%s' appears more than once in the document", exported.Name)
+			report.Invalid = append(report.Invalid, entry)
+			continue
+		}
+		seen[exported.Name] = true
+
+		exists, err := serviceNameExists(s.db, userID, exported.Name)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			entry.Reason = fmt.Sprintf("service with name '%s' already exists", exported.Name)
+			report.Skipped = append(report.Skipped, entry)
+			continue
+		}
+		_, problem, err := resolveImportVersions(s.db, exported)
+		if err != nil {
+			return nil, err
+		}
+		if problem != "" {
+			entry.Reason = problem
+			report.Invalid = append(report.Invalid, entry)
+			continue
+		}
+		report.WouldImport = append(report.WouldImport, entry)
+	}
+	report.Valid = len(report.Skipped) == 0 && len(report.Invalid) == 0
+
+	return report, nil
+}
+
+// serviceNameExists reports whether the user already has a service with
+// the given name
+func serviceNameExists(db *gorm.DB, userID uint, name string) (bool, error) {
+	var count int64
+	if err := db.Model(&models.Service{}).Where("name = ? AND user_id = ?", name, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check service name: %w", err)
+	}
+	return count > 0, nil
+}
+
+// resolveImportVersions looks up the container version referenced by each
+// container of an exported service. A missing container or version is
+// described by problem; err is only set when a lookup fails.
+func resolveImportVersions(db *gorm.DB, exported ExportedService) (versions []models.ContainerVersion, problem string, err error) {
+	versions = make([]models.ContainerVersion, 0, len(exported.Containers))
+	for _, ec := range exported.Containers {
+		var container models.Container
+		if err := db.Where("name = ?", ec.Container).First(&container).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, fmt.Sprintf("container '%s' not found", ec.Container), nil
+			}
+			return nil, "", fmt.Errorf("failed to get container: %w", err)
+		}
+		var version models.ContainerVersion
+		if err := db.Where("container_id = ? AND version = ?", container.ID, ec.Version).First(&version).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, fmt.Sprintf("version '%s' of container '%s' not found", ec.Version, ec.Container), nil
+			}
+			return nil, "", fmt.Errorf("failed to get container version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, "", nil
+}
@@ -3,9 +3,13 @@ package services
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/burndler/burndler/internal/models"
 	"github.com/burndler/burndler/internal/storage"
+	"gopkg.in/yaml.v3"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -32,9 +36,10 @@ type CreateServiceRequest struct {
 
 // UpdateServiceRequest represents the request to update a service
 type UpdateServiceRequest struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
-	Active      *bool   `json:"active"`
+	Name               *string `json:"name"`
+	Description        *string `json:"description"`
+	Active             *bool   `json:"active"`
+	BuildRetentionDays *int    `json:"build_retention_days"`
 }
 
 // AddContainerToServiceRequest represents the request to add a container to service
@@ -50,16 +55,18 @@ type AddContainerToServiceRequest struct {
 type UpdateServiceContainerRequest struct {
 	Order        *int                   `json:"order"`
 	Enabled      *bool                  `json:"enabled"`
+	Pinned       *bool                  `json:"pinned"`
 	OverrideVars map[string]interface{} `json:"override_vars"`
 }
 
 // ServiceFilters represents filters for listing services
 type ServiceFilters struct {
-	Active   *bool  `json:"active"`
-	UserID   uint   `json:"user_id"`
-	Name     string `json:"name"`
-	Page     int    `json:"page"`
-	PageSize int    `json:"page_size"`
+	Active          *bool  `json:"active"`
+	IncludeArchived bool   `json:"include_archived"`
+	UserID          uint   `json:"user_id"`
+	Name            string `json:"name"`
+	Page            int    `json:"page"`
+	PageSize        int    `json:"page_size"`
 }
 
 // ValidationResult represents the result of service validation
@@ -69,6 +76,41 @@ type ValidationResult struct {
 	Warnings []string `json:"warnings"`
 }
 
+// Variable sources, from lowest to highest precedence
+const (
+	VariableSourceContainer = "container"
+	VariableSourceService   = "service"
+	VariableSourceOverride  = "override"
+)
+
+// ResolvedVariable is a final variable value and the layer it came from
+type ResolvedVariable struct {
+	Name   string      `json:"name"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+	Masked bool        `json:"masked,omitempty"`
+}
+
+// ResolvedContainerVariables lists the resolved variables of one service container
+type ResolvedContainerVariables struct {
+	ServiceContainerID uint               `json:"service_container_id"`
+	ContainerID        uint               `json:"container_id"`
+	ContainerName      string             `json:"container_name"`
+	Version            string             `json:"version"`
+	Variables          []ResolvedVariable `json:"variables"`
+}
+
+// UpgradeCandidate describes a service container with a newer published version
+type UpgradeCandidate struct {
+	ServiceContainerID uint   `json:"service_container_id"`
+	ContainerID        uint   `json:"container_id"`
+	ContainerName      string `json:"container_name"`
+	FromVersionID      uint   `json:"from_version_id"`
+	FromVersion        string `json:"from_version"`
+	ToVersionID        uint   `json:"to_version_id"`
+	ToVersion          string `json:"to_version"`
+}
+
 // CreateService creates a new service
 func (s *ServiceService) CreateService(userID uint, req CreateServiceRequest) (*models.Service, error) {
 	if req.Name == "" {
@@ -114,6 +156,23 @@ func (s *ServiceService) GetService(id uint, includeContainers bool) (*models.Se
 	return &service, nil
 }
 
+// CheckServiceAccess verifies that a user may access a service. Unless
+// isAdmin, the service must belong to the user. A service owned by someone
+// else is reported as "service not found" so its existence is not revealed.
+func (s *ServiceService) CheckServiceAccess(serviceID, userID uint, isAdmin bool) error {
+	var service models.Service
+	if err := s.db.Select("id", "user_id").First(&service, serviceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("service not found")
+		}
+		return fmt.Errorf("failed to get service: %w", err)
+	}
+	if !isAdmin && service.UserID != userID {
+		return fmt.Errorf("service not found")
+	}
+	return nil
+}
+
 // GetServiceByName retrieves a service by name and user ID
 func (s *ServiceService) GetServiceByName(userID uint, name string, includeContainers bool) (*models.Service, error) {
 	var service models.Service
@@ -144,6 +203,9 @@ func (s *ServiceService) ListServices(filters ServiceFilters) (*PaginatedRespons
 	if filters.Active != nil {
 		query = query.Where("active = ?", *filters.Active)
 	}
+	if !filters.IncludeArchived {
+		query = query.Where("archived = ?", false)
+	}
 	if filters.UserID > 0 {
 		query = query.Where("user_id = ?", filters.UserID)
 	}
@@ -162,18 +224,7 @@ func (s *ServiceService) ListServices(filters ServiceFilters) (*PaginatedRespons
 		return nil, fmt.Errorf("failed to list services: %w", err)
 	}
 
-	totalPages := int(total) / filters.PageSize
-	if int(total)%filters.PageSize > 0 {
-		totalPages++
-	}
-
-	return &PaginatedResponse[models.Service]{
-		Data:       services,
-		Total:      total,
-		Page:       filters.Page,
-		PageSize:   filters.PageSize,
-		TotalPages: totalPages,
-	}, nil
+	return newPaginatedResponse(services, total, filters.Page, filters.PageSize), nil
 }
 
 // UpdateService updates an existing service
@@ -196,6 +247,15 @@ func (s *ServiceService) UpdateService(id uint, req UpdateServiceRequest) (*mode
 	if req.Active != nil {
 		service.Active = *req.Active
 	}
+	if req.BuildRetentionDays != nil {
+		// Zero clears the override and falls back to the global retention
+		if *req.BuildRetentionDays > 0 {
+			retentionDays := *req.BuildRetentionDays
+			service.BuildRetentionDays = &retentionDays
+		} else {
+			service.BuildRetentionDays = nil
+		}
+	}
 
 	if err := s.db.Save(&service).Error; err != nil {
 		return nil, fmt.Errorf("failed to update service: %w", err)
@@ -216,6 +276,35 @@ func (s *ServiceService) DeleteService(id uint) error {
 	return nil
 }
 
+// ArchiveService retires a service without deleting it. Archived services are
+// hidden from default listings and cannot be built.
+func (s *ServiceService) ArchiveService(id uint) (*models.Service, error) {
+	return s.setArchived(id, true)
+}
+
+// UnarchiveService restores an archived service
+func (s *ServiceService) UnarchiveService(id uint) (*models.Service, error) {
+	return s.setArchived(id, false)
+}
+
+// setArchived updates the archived state of a service
+func (s *ServiceService) setArchived(id uint, archived bool) (*models.Service, error) {
+	var service models.Service
+	if err := s.db.First(&service, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("service not found")
+		}
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	service.Archived = archived
+	if err := s.db.Save(&service).Error; err != nil {
+		return nil, fmt.Errorf("failed to update service: %w", err)
+	}
+
+	return &service, nil
+}
+
 // AddContainerToService adds a container to a service
 func (s *ServiceService) AddContainerToService(serviceID uint, req AddContainerToServiceRequest) (*models.ServiceContainer, error) {
 	// Verify service exists
@@ -298,6 +387,9 @@ func (s *ServiceService) UpdateServiceContainer(serviceContainerID uint, req Upd
 	if req.Enabled != nil {
 		serviceContainer.Enabled = *req.Enabled
 	}
+	if req.Pinned != nil {
+		serviceContainer.Pinned = *req.Pinned
+	}
 	if req.OverrideVars != nil {
 		jsonData, err := json.Marshal(req.OverrideVars)
 		if err != nil {
@@ -330,6 +422,90 @@ func (s *ServiceService) RemoveContainerFromService(serviceID, containerID uint)
 	return nil
 }
 
+// ResetServiceContainerConfig discards a service container's override variables
+// so that it falls back to the defaults declared by its container version
+func (s *ServiceService) ResetServiceContainerConfig(serviceID, containerID uint) (*models.ServiceContainer, error) {
+	var serviceContainer models.ServiceContainer
+	if err := s.db.Where("service_id = ? AND container_id = ?", serviceID, containerID).First(&serviceContainer).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("container not found in service")
+		}
+		return nil, fmt.Errorf("failed to get service container: %w", err)
+	}
+
+	serviceContainer.OverrideVars = nil
+
+	if err := s.db.Save(&serviceContainer).Error; err != nil {
+		return nil, fmt.Errorf("failed to reset service container configuration: %w", err)
+	}
+
+	// Load relationships
+	if err := s.db.Preload("Container").Preload("ContainerVersion").First(&serviceContainer, serviceContainer.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load service container relationships: %w", err)
+	}
+
+	return &serviceContainer, nil
+}
+
+// GetUpgradeCandidates lists service containers whose container has a newer
+// published version by semver. Pinned containers are never suggested for
+// upgrade, and versions that are not valid semver are ignored.
+func (s *ServiceService) GetUpgradeCandidates(serviceID uint) ([]UpgradeCandidate, error) {
+	var service models.Service
+	if err := s.db.First(&service, serviceID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("service not found")
+		}
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+
+	var serviceContainers []models.ServiceContainer
+	if err := s.db.Where("service_id = ? AND pinned = ?", serviceID, false).
+		Preload("Container").
+		Preload("Container.Versions", "published = ?", true).
+		Preload("ContainerVersion").
+		Order("\"order\"").
+		Find(&serviceContainers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get service containers: %w", err)
+	}
+
+	candidates := []UpgradeCandidate{}
+	for _, sc := range serviceContainers {
+		current, err := semver.NewVersion(sc.ContainerVersion.Version)
+		if err != nil {
+			continue
+		}
+
+		var latest *models.ContainerVersion
+		latestSemver := current
+		for i := range sc.Container.Versions {
+			version, err := semver.NewVersion(sc.Container.Versions[i].Version)
+			if err != nil {
+				continue
+			}
+			if version.GreaterThan(latestSemver) {
+				latest = &sc.Container.Versions[i]
+				latestSemver = version
+			}
+		}
+		if latest == nil {
+			continue
+		}
+
+		candidates = append(candidates, UpgradeCandidate{
+			ServiceContainerID: sc.ID,
+			ContainerID:        sc.ContainerID,
+			ContainerName:      sc.Container.Name,
+			FromVersionID:      sc.ContainerVersionID,
+			FromVersion:        sc.ContainerVersion.Version,
+			ToVersionID:        latest.ID,
+			ToVersion:          latest.Version,
+		})
+	}
+
+	return candidates, nil
+}
+
 // GetServiceContainers retrieves all containers for a service
 func (s *ServiceService) GetServiceContainers(serviceID uint) ([]models.ServiceContainer, error) {
 	var serviceContainers []models.ServiceContainer
@@ -380,9 +556,237 @@ func (s *ServiceService) ValidateService(serviceID uint) (*ValidationResult, err
 		}
 	}
 
+	// Check for environment keys defined with different values
+	result.Warnings = append(result.Warnings, findEnvironmentConflicts(service)...)
+
+	return result, nil
+}
+
+// findEnvironmentConflicts reports environment keys that resolve to different
+// values across service-level variables and enabled containers
+func findEnvironmentConflicts(service *models.Service) []string {
+	values := make(map[string]map[string][]string) // key -> value -> sources
+
+	addValue := func(key, value, source string) {
+		if values[key] == nil {
+			values[key] = make(map[string][]string)
+		}
+		if !contains(values[key][value], source) {
+			values[key][value] = append(values[key][value], source)
+		}
+	}
+
+	if service.EnvironmentVars != nil {
+		var serviceEnv map[string]interface{}
+		if err := json.Unmarshal(service.EnvironmentVars, &serviceEnv); err == nil {
+			for key, value := range serviceEnv {
+				addValue(key, fmt.Sprint(value), "service")
+			}
+		}
+	}
+
+	merger := NewMerger()
+	for _, sc := range service.GetEnabledContainers() {
+		container := sc.Container.Name
+		if container == "" {
+			container = sc.GetDisplayName()
+		}
+
+		// Resolve placeholders the way builds do
+		variables := make(map[string]string)
+		for name, variable := range resolveVariableLayers(service, sc) {
+			variables[name] = fmt.Sprint(variable.Value)
+		}
+
+		for composeService, env := range composeEnvironment(sc.ContainerVersion.ComposeContent) {
+			source := container + "/" + composeService
+			for key, value := range env {
+				addValue(key, merger.replaceVariables(value, variables, nil), source)
+			}
+		}
+	}
+
+	var warnings []string
+	for key, byValue := range values {
+		if len(byValue) < 2 {
+			continue
+		}
+
+		var sources []string
+		for _, valueSources := range byValue {
+			for _, source := range valueSources {
+				if !contains(sources, source) {
+					sources = append(sources, source)
+				}
+			}
+		}
+		sort.Strings(sources)
+
+		warnings = append(warnings, fmt.Sprintf("Environment variable '%s' has conflicting values across: %s", key, strings.Join(sources, ", ")))
+	}
+	sort.Strings(warnings)
+
+	return warnings
+}
+
+// composeEnvironment collects the environment entries of each service in a
+// compose file, keyed by compose service name
+func composeEnvironment(composeContent string) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+
+	var compose map[string]interface{}
+	if err := yaml.Unmarshal([]byte(composeContent), &compose); err != nil {
+		return result
+	}
+
+	services, ok := compose["services"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+
+	for serviceName, serviceConfig := range services {
+		config, ok := serviceConfig.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		env := make(map[string]string)
+		result[serviceName] = env
+
+		switch environment := config["environment"].(type) {
+		case []interface{}:
+			for _, entry := range environment {
+				if str, ok := entry.(string); ok {
+					key, value, _ := strings.Cut(str, "=")
+					env[key] = value
+				}
+			}
+		case map[string]interface{}:
+			for key, value := range environment {
+				if value == nil {
+					env[key] = ""
+				} else {
+					env[key] = fmt.Sprint(value)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// ResolveVariables returns every enabled container's final variables with
+// the layer each value came from. Secret-looking values are masked.
+func (s *ServiceService) ResolveVariables(serviceID uint) ([]ResolvedContainerVariables, error) {
+	service, err := s.GetService(serviceID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := service.GetEnabledContainers()
+	sort.SliceStable(containers, func(i, j int) bool {
+		return containers[i].Order < containers[j].Order
+	})
+
+	result := []ResolvedContainerVariables{}
+	for _, sc := range containers {
+		result = append(result, resolveContainerVariables(service, sc))
+	}
+
 	return result, nil
 }
 
+// ResolveContainerVariables returns the resolved variables of one container
+// in a service, with the layer each value came from
+func (s *ServiceService) ResolveContainerVariables(serviceID, containerID uint) (*ResolvedContainerVariables, error) {
+	service, err := s.GetService(serviceID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sc := range service.ServiceContainers {
+		if sc.ContainerID == containerID {
+			resolved := resolveContainerVariables(service, sc)
+			return &resolved, nil
+		}
+	}
+
+	return nil, fmt.Errorf("container not found in service")
+}
+
+// resolveContainerVariables resolves a service container's variables sorted
+// by name, masking secret values
+func resolveContainerVariables(service *models.Service, sc models.ServiceContainer) ResolvedContainerVariables {
+	resolved := resolveVariableLayers(service, sc)
+
+	names := make([]string, 0, len(resolved))
+	for name := range resolved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	variables := []ResolvedVariable{}
+	for _, name := range names {
+		variable := resolved[name]
+		if isSecretVariable(name) {
+			variable.Value = "********"
+			variable.Masked = true
+		}
+		variables = append(variables, variable)
+	}
+
+	return ResolvedContainerVariables{
+		ServiceContainerID: sc.ID,
+		ContainerID:        sc.ContainerID,
+		ContainerName:      sc.Container.Name,
+		Version:            sc.ContainerVersion.Version,
+		Variables:          variables,
+	}
+}
+
+// resolveVariableLayers applies container defaults, then service variables,
+// then the service container's overrides
+func resolveVariableLayers(service *models.Service, sc models.ServiceContainer) map[string]ResolvedVariable {
+	resolved := make(map[string]ResolvedVariable)
+
+	layers := []struct {
+		source string
+		data   datatypes.JSON
+	}{
+		{VariableSourceContainer, sc.ContainerVersion.Variables},
+		{VariableSourceService, service.Variables},
+		{VariableSourceOverride, sc.OverrideVars},
+	}
+
+	for _, layer := range layers {
+		if layer.data == nil {
+			continue
+		}
+		var vars map[string]interface{}
+		if err := json.Unmarshal(layer.data, &vars); err != nil {
+			continue
+		}
+		for name, value := range vars {
+			resolved[name] = ResolvedVariable{Name: name, Value: value, Source: layer.source}
+		}
+	}
+
+	return resolved
+}
+
+var secretVariableMarkers = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "PRIVATE", "CREDENTIAL", "API_KEY", "ACCESS_KEY"}
+
+// isSecretVariable reports whether a variable name looks like it holds a secret
+func isSecretVariable(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range secretVariableMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 // CanBuild checks if a service can be built
 func (s *ServiceService) CanBuild(serviceID uint) (bool, error) {
 	service, err := s.GetService(serviceID, true)
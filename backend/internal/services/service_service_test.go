@@ -1,10 +1,12 @@
 package services
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/burndler/burndler/internal/models"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -245,6 +247,40 @@ func TestServiceService_ListServices(t *testing.T) {
 	}
 }
 
+func TestServiceService_ListServices_Pagination(t *testing.T) {
+	tests := []struct {
+		name           string
+		services       int
+		page           int
+		wantTotalPages int
+		wantHasNext    bool
+	}{
+		{name: "exact multiple, first page", services: 6, page: 1, wantTotalPages: 2, wantHasNext: true},
+		{name: "exact multiple, last page", services: 6, page: 2, wantTotalPages: 2, wantHasNext: false},
+		{name: "partial final page", services: 7, page: 3, wantTotalPages: 3, wantHasNext: false},
+		{name: "partial final page, middle page", services: 7, page: 2, wantTotalPages: 3, wantHasNext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupServiceTestDB(t)
+			service := NewServiceService(db, nil)
+
+			user := &models.User{Email: "test@example.com", Name: "testuser", Role: "Developer"}
+			assert.NoError(t, db.Create(user).Error)
+			for i := 0; i < tt.services; i++ {
+				assert.NoError(t, db.Create(&models.Service{Name: fmt.Sprintf("service-%d", i), UserID: user.ID, Active: true}).Error)
+			}
+
+			result, err := service.ListServices(ServiceFilters{UserID: user.ID, Page: tt.page, PageSize: 3})
+			assert.NoError(t, err)
+			assert.Equal(t, int64(tt.services), result.Total)
+			assert.Equal(t, tt.wantTotalPages, result.TotalPages)
+			assert.Equal(t, tt.wantHasNext, result.HasNext)
+		})
+	}
+}
+
 func TestServiceService_UpdateService(t *testing.T) {
 	db := setupServiceTestDB(t)
 	service := NewServiceService(db, nil)
@@ -294,6 +330,13 @@ func TestServiceService_UpdateService(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "set build retention override",
+			req: UpdateServiceRequest{
+				BuildRetentionDays: &[]int{30}[0],
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -316,6 +359,9 @@ func TestServiceService_UpdateService(t *testing.T) {
 				if tt.req.Active != nil {
 					assert.Equal(t, *tt.req.Active, result.Active)
 				}
+				if tt.req.BuildRetentionDays != nil {
+					assert.Equal(t, *tt.req.BuildRetentionDays, result.GetBuildRetentionDays(7))
+				}
 			}
 		})
 	}
@@ -381,4 +427,387 @@ func TestServiceService_DeleteService(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestServiceService_ArchiveService(t *testing.T) {
+	db := setupServiceTestDB(t)
+	service := NewServiceService(db, nil)
+
+	user := &models.User{Email: "test@example.com", Name: "testuser", Role: "Developer"}
+	assert.NoError(t, db.Create(user).Error)
+	kept := &models.Service{Name: "kept", UserID: user.ID, Active: true}
+	retired := &models.Service{Name: "retired", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(kept).Error)
+	assert.NoError(t, db.Create(retired).Error)
+
+	archived, err := service.ArchiveService(retired.ID)
+	assert.NoError(t, err)
+	assert.True(t, archived.Archived)
+	assert.True(t, archived.Active, "archiving should not change the active flag")
+
+	result, err := service.ListServices(ServiceFilters{UserID: user.ID, Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Total)
+	assert.Equal(t, "kept", result.Data[0].Name)
+
+	result, err = service.ListServices(ServiceFilters{UserID: user.ID, IncludeArchived: true, Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+
+	restored, err := service.UnarchiveService(retired.ID)
+	assert.NoError(t, err)
+	assert.False(t, restored.Archived)
+
+	result, err = service.ListServices(ServiceFilters{UserID: user.ID, Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+
+	_, err = service.ArchiveService(999)
+	assert.EqualError(t, err, "service not found")
+}
+
+func TestServiceService_ResetServiceContainerConfig(t *testing.T) {
+	db := setupServiceTestDB(t)
+	service := NewServiceService(db, nil)
+
+	user := &models.User{Email: "test@example.com", Name: "testuser", Role: "Developer"}
+	assert.NoError(t, db.Create(user).Error)
+
+	testService := &models.Service{Name: "test-service", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+
+	container := &models.Container{Name: "postgres", Active: true}
+	assert.NoError(t, db.Create(container).Error)
+
+	version := &models.ContainerVersion{
+		ContainerID:    container.ID,
+		Version:        "v1.0.0",
+		ComposeContent: "services:\n  db:\n    image: postgres:16\n",
+		Variables:      datatypes.JSON(`{"DB_PORT":"5432","DB_NAME":"app"}`),
+	}
+	assert.NoError(t, db.Create(version).Error)
+
+	serviceContainer := &models.ServiceContainer{
+		ServiceID:          testService.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+		OverrideVars:       datatypes.JSON(`{"DB_PORT":"6543","EXTRA":"value"}`),
+	}
+	assert.NoError(t, db.Create(serviceContainer).Error)
+
+	t.Run("reset replaces overrides with version defaults", func(t *testing.T) {
+		result, err := service.ResetServiceContainerConfig(testService.ID, container.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.False(t, result.IsConfigured())
+		assert.Equal(t, map[string]interface{}{
+			"DB_PORT": "5432",
+			"DB_NAME": "app",
+		}, result.GetEffectiveVariables())
+
+		var stored models.ServiceContainer
+		assert.NoError(t, db.First(&stored, serviceContainer.ID).Error)
+		assert.False(t, stored.IsConfigured())
+	})
+
+	t.Run("container not in service", func(t *testing.T) {
+		result, err := service.ResetServiceContainerConfig(testService.ID, 999)
+		assert.Error(t, err)
+		assert.Equal(t, "container not found in service", err.Error())
+		assert.Nil(t, result)
+	})
+}
+
+func TestServiceService_ValidateService_EnvironmentConflicts(t *testing.T) {
+	db := setupServiceTestDB(t)
+	service := NewServiceService(db, nil)
+
+	user := &models.User{Email: "test@example.com", Name: "testuser", Role: "Developer"}
+	assert.NoError(t, db.Create(user).Error)
+
+	addContainer := func(serviceID uint, name, compose, variables string, order int) {
+		container := &models.Container{Name: name, Active: true}
+		assert.NoError(t, db.Create(container).Error)
+
+		version := &models.ContainerVersion{
+			ContainerID:    container.ID,
+			Version:        "v1.0.0",
+			ComposeContent: compose,
+			Variables:      datatypes.JSON(variables),
+		}
+		assert.NoError(t, db.Create(version).Error)
+
+		assert.NoError(t, db.Create(&models.ServiceContainer{
+			ServiceID:          serviceID,
+			ContainerID:        container.ID,
+			ContainerVersionID: version.ID,
+			Order:              order,
+			Enabled:            true,
+		}).Error)
+	}
+
+	t.Run("keys resolving to the same value do not conflict", func(t *testing.T) {
+		svc := &models.Service{Name: "consistent", UserID: user.ID, Active: true}
+		assert.NoError(t, db.Create(svc).Error)
+
+		addContainer(svc.ID, "api-consistent", "services:\n  api:\n    image: api:1.0.0\n    environment:\n      - DB_HOST=${DB_HOST}\n", `{"DB_HOST":"db"}`, 1)
+		addContainer(svc.ID, "worker-consistent", "services:\n  worker:\n    image: worker:1.0.0\n    environment:\n      DB_HOST: db\n", `{}`, 2)
+
+		result, err := service.ValidateService(svc.ID)
+		assert.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Warnings)
+	})
+
+	t.Run("keys resolving to different values are reported", func(t *testing.T) {
+		svc := &models.Service{
+			Name:            "conflicting",
+			UserID:          user.ID,
+			Active:          true,
+			EnvironmentVars: datatypes.JSON(`{"LOG_LEVEL":"info"}`),
+		}
+		assert.NoError(t, db.Create(svc).Error)
+
+		addContainer(svc.ID, "api-conflicting", "services:\n  api:\n    image: api:1.0.0\n    environment:\n      - DB_HOST=${DB_HOST}\n      - LOG_LEVEL=debug\n", `{"DB_HOST":"db-primary"}`, 1)
+		addContainer(svc.ID, "worker-conflicting", "services:\n  worker:\n    image: worker:1.0.0\n    environment:\n      DB_HOST: db-replica\n      LOG_LEVEL: info\n", `{}`, 2)
+
+		result, err := service.ValidateService(svc.ID)
+		assert.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, []string{
+			"Environment variable 'DB_HOST' has conflicting values across: api-conflicting/api, worker-conflicting/worker",
+			"Environment variable 'LOG_LEVEL' has conflicting values across: api-conflicting/api, service, worker-conflicting/worker",
+		}, result.Warnings)
+	})
+
+	t.Run("services within one container are compared", func(t *testing.T) {
+		svc := &models.Service{Name: "same-container", UserID: user.ID, Active: true}
+		assert.NoError(t, db.Create(svc).Error)
+
+		addContainer(svc.ID, "stack", "services:\n  api:\n    image: api:1.0.0\n    environment:\n      MODE: primary\n  worker:\n    image: worker:1.0.0\n    environment:\n      MODE: replica\n", `{}`, 1)
+
+		result, err := service.ValidateService(svc.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"Environment variable 'MODE' has conflicting values across: stack/api, stack/worker",
+		}, result.Warnings)
+	})
+
+	t.Run("placeholders resolve with service variables like builds", func(t *testing.T) {
+		svc := &models.Service{
+			Name:      "layered",
+			UserID:    user.ID,
+			Active:    true,
+			Variables: datatypes.JSON(`{"DB_HOST":"db-primary"}`),
+		}
+		assert.NoError(t, db.Create(svc).Error)
+
+		addContainer(svc.ID, "api-layered", "services:\n  api:\n    image: api:1.0.0\n    environment:\n      - DB_HOST=${DB_HOST}\n", `{"DB_HOST":"db"}`, 1)
+		addContainer(svc.ID, "worker-layered", "services:\n  worker:\n    image: worker:1.0.0\n    environment:\n      DB_HOST: db-primary\n", `{}`, 2)
+
+		result, err := service.ValidateService(svc.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, result.Warnings)
+	})
+}
+
+func TestServiceService_CheckServiceAccess(t *testing.T) {
+	db := setupServiceTestDB(t)
+	service := NewServiceService(db, nil)
+
+	owner := &models.User{Email: "owner@example.com", Name: "owner", Role: "Developer"}
+	other := &models.User{Email: "other@example.com", Name: "other", Role: "Developer"}
+	assert.NoError(t, db.Create([]*models.User{owner, other}).Error)
+
+	testService := &models.Service{Name: "test-service", UserID: owner.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+
+	assert.NoError(t, service.CheckServiceAccess(testService.ID, owner.ID, false))
+	assert.EqualError(t, service.CheckServiceAccess(testService.ID, other.ID, false), "service not found")
+	assert.NoError(t, service.CheckServiceAccess(testService.ID, other.ID, true))
+	assert.EqualError(t, service.CheckServiceAccess(999, owner.ID, true), "service not found")
+}
+
+func TestServiceService_ResolveVariables(t *testing.T) {
+	db := setupServiceTestDB(t)
+	service := NewServiceService(db, nil)
+
+	user := &models.User{Email: "test@example.com", Name: "testuser", Role: "Developer"}
+	assert.NoError(t, db.Create(user).Error)
+
+	testService := &models.Service{
+		Name:      "test-service",
+		UserID:    user.ID,
+		Active:    true,
+		Variables: datatypes.JSON(`{"LOG_LEVEL":"warn","DB_NAME":"shop"}`),
+	}
+	assert.NoError(t, db.Create(testService).Error)
+
+	container := &models.Container{Name: "postgres", Active: true}
+	assert.NoError(t, db.Create(container).Error)
+
+	version := &models.ContainerVersion{
+		ContainerID:    container.ID,
+		Version:        "v1.0.0",
+		ComposeContent: "services:\n  db:\n    image: postgres:16\n",
+		Variables:      datatypes.JSON(`{"DB_PORT":"5432","DB_NAME":"app","LOG_LEVEL":"info","DB_PASSWORD":"changeme"}`),
+	}
+	assert.NoError(t, db.Create(version).Error)
+
+	assert.NoError(t, db.Create(&models.ServiceContainer{
+		ServiceID:          testService.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+		OverrideVars:       datatypes.JSON(`{"DB_PORT":"6543","DB_PASSWORD":"s3cret"}`),
+	}).Error)
+
+	result, err := service.ResolveVariables(testService.ID)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "postgres", result[0].ContainerName)
+	assert.Equal(t, "v1.0.0", result[0].Version)
+
+	resolved := make(map[string]ResolvedVariable)
+	for _, variable := range result[0].Variables {
+		resolved[variable.Name] = variable
+	}
+
+	assert.Equal(t, ResolvedVariable{Name: "DB_NAME", Value: "shop", Source: VariableSourceService}, resolved["DB_NAME"])
+	assert.Equal(t, ResolvedVariable{Name: "LOG_LEVEL", Value: "warn", Source: VariableSourceService}, resolved["LOG_LEVEL"])
+	assert.Equal(t, ResolvedVariable{Name: "DB_PORT", Value: "6543", Source: VariableSourceOverride}, resolved["DB_PORT"])
+	assert.Equal(t, ResolvedVariable{Name: "DB_PASSWORD", Value: "********", Source: VariableSourceOverride, Masked: true}, resolved["DB_PASSWORD"])
+
+	_, err = service.ResolveVariables(999)
+	assert.EqualError(t, err, "service not found")
+}
+
+func TestServiceService_ResolveContainerVariables(t *testing.T) {
+	db := setupServiceTestDB(t)
+	service := NewServiceService(db, nil)
+
+	user := &models.User{Email: "test@example.com", Name: "testuser", Role: "Developer"}
+	assert.NoError(t, db.Create(user).Error)
+
+	testService := &models.Service{
+		Name:      "test-service",
+		UserID:    user.ID,
+		Active:    true,
+		Variables: datatypes.JSON(`{"LOG_LEVEL":"warn","DB_PORT":"5433"}`),
+	}
+	assert.NoError(t, db.Create(testService).Error)
+
+	container := &models.Container{Name: "postgres", Active: true}
+	assert.NoError(t, db.Create(container).Error)
+
+	version := &models.ContainerVersion{
+		ContainerID:    container.ID,
+		Version:        "v1.0.0",
+		ComposeContent: "services:\n  db:\n    image: postgres:16\n",
+		Variables:      datatypes.JSON(`{"DB_PORT":"5432","LOG_LEVEL":"info","DB_USER":"app"}`),
+	}
+	assert.NoError(t, db.Create(version).Error)
+
+	assert.NoError(t, db.Create(&models.ServiceContainer{
+		ServiceID:          testService.ID,
+		ContainerID:        container.ID,
+		ContainerVersionID: version.ID,
+		Enabled:            true,
+		OverrideVars:       datatypes.JSON(`{"DB_PORT":"6543"}`),
+	}).Error)
+
+	result, err := service.ResolveContainerVariables(testService.ID, container.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, container.ID, result.ContainerID)
+	assert.Equal(t, "postgres", result.ContainerName)
+	resolved := make(map[string]ResolvedVariable)
+	for _, variable := range result.Variables {
+		resolved[variable.Name] = variable
+	}
+	assert.Len(t, resolved, 3)
+	// Override shadows both the service variable and the container default
+	assert.Equal(t, ResolvedVariable{Name: "DB_PORT", Value: "6543", Source: VariableSourceOverride}, resolved["DB_PORT"])
+	// Service variable shadows the container default
+	assert.Equal(t, ResolvedVariable{Name: "LOG_LEVEL", Value: "warn", Source: VariableSourceService}, resolved["LOG_LEVEL"])
+	assert.Equal(t, ResolvedVariable{Name: "DB_USER", Value: "app", Source: VariableSourceContainer}, resolved["DB_USER"])
+
+	_, err = service.ResolveContainerVariables(testService.ID, container.ID+1)
+	assert.EqualError(t, err, "container not found in service")
+
+	_, err = service.ResolveContainerVariables(999, container.ID)
+	assert.EqualError(t, err, "service not found")
+}
+
+func TestServiceService_GetUpgradeCandidates(t *testing.T) {
+	db := setupServiceTestDB(t)
+	service := NewServiceService(db, nil)
+
+	user := &models.User{Email: "test@example.com", Name: "testuser", Role: "Developer"}
+	assert.NoError(t, db.Create(user).Error)
+
+	testService := &models.Service{Name: "test-service", UserID: user.ID, Active: true}
+	assert.NoError(t, db.Create(testService).Error)
+
+	// addContainer creates a container with the given versions and uses the first one
+	addContainer := func(name string, pinned bool, versions ...models.ContainerVersion) *models.ServiceContainer {
+		container := &models.Container{Name: name, Active: true}
+		assert.NoError(t, db.Create(container).Error)
+
+		for i := range versions {
+			versions[i].ContainerID = container.ID
+			versions[i].ComposeContent = "services: {}"
+			assert.NoError(t, db.Create(&versions[i]).Error)
+		}
+
+		sc := &models.ServiceContainer{
+			ServiceID:          testService.ID,
+			ContainerID:        container.ID,
+			ContainerVersionID: versions[0].ID,
+			Enabled:            true,
+			Pinned:             pinned,
+			Order:              int(container.ID),
+		}
+		assert.NoError(t, db.Create(sc).Error)
+		return sc
+	}
+
+	// Outdated: v10.0.0 is newest by semver even though v2.0.0 sorts later as a string
+	addContainer("redis", false,
+		models.ContainerVersion{Version: "v1.0.0", Published: true},
+		models.ContainerVersion{Version: "v10.0.0", Published: true},
+		models.ContainerVersion{Version: "v2.0.0", Published: true},
+		models.ContainerVersion{Version: "v11.0.0", Published: false},
+	)
+	// Up to date
+	addContainer("nginx", false,
+		models.ContainerVersion{Version: "v1.2.0", Published: true},
+		models.ContainerVersion{Version: "v1.1.0", Published: true},
+	)
+	// Outdated but pinned
+	pinned := addContainer("postgres", true,
+		models.ContainerVersion{Version: "v1.0.0", Published: true},
+		models.ContainerVersion{Version: "v1.0.1", Published: true},
+	)
+
+	candidates, err := service.GetUpgradeCandidates(testService.ID)
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, "redis", candidates[0].ContainerName)
+	assert.Equal(t, "v1.0.0", candidates[0].FromVersion)
+	assert.Equal(t, "v10.0.0", candidates[0].ToVersion)
+
+	// Unpinning makes the container an upgrade candidate again
+	unpin := false
+	_, err = service.UpdateServiceContainer(pinned.ID, UpdateServiceContainerRequest{Pinned: &unpin})
+	assert.NoError(t, err)
+
+	candidates, err = service.GetUpgradeCandidates(testService.ID)
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 2)
+	assert.Equal(t, "postgres", candidates[1].ContainerName)
+	assert.Equal(t, "v1.0.1", candidates[1].ToVersion)
+
+	_, err = service.GetUpgradeCandidates(999)
+	assert.EqualError(t, err, "service not found")
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// VersionDiff compares the compose files of two container versions
+type VersionDiff struct {
+	From            string          `json:"from"`
+	To              string          `json:"to"`
+	UnifiedDiff     string          `json:"unified_diff"`
+	AddedServices   []string        `json:"added_services"`
+	RemovedServices []string        `json:"removed_services"`
+	ChangedServices []ServiceChange `json:"changed_services"`
+}
+
+// ServiceChange lists the top-level keys that differ for one compose service
+type ServiceChange struct {
+	Service string   `json:"service"`
+	Fields  []string `json:"fields"`
+}
+
+// DiffVersions returns a unified diff of two versions' compose content and
+// the compose services added, removed or changed between them
+func (s *ContainerService) DiffVersions(containerID uint, from, to string) (*VersionDiff, error) {
+	fromVersion, err := s.GetVersion(containerID, from)
+	if err != nil {
+		return nil, err
+	}
+	toVersion, err := s.GetVersion(containerID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	unified, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(fromVersion.ComposeContent),
+		B:        difflib.SplitLines(toVersion.ComposeContent),
+		FromFile: from,
+		ToFile:   to,
+		Context:  3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff compose: %w", err)
+	}
+
+	fromServices, err := composeServices(fromVersion.ComposeContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse compose for version %s: %w", from, err)
+	}
+	toServices, err := composeServices(toVersion.ComposeContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse compose for version %s: %w", to, err)
+	}
+
+	diff := &VersionDiff{
+		From:            from,
+		To:              to,
+		UnifiedDiff:     unified,
+		AddedServices:   []string{},
+		RemovedServices: []string{},
+		ChangedServices: []ServiceChange{},
+	}
+	for _, name := range sortedKeys(toServices) {
+		if _, ok := fromServices[name]; !ok {
+			diff.AddedServices = append(diff.AddedServices, name)
+		}
+	}
+	for _, name := range sortedKeys(fromServices) {
+		toConfig, ok := toServices[name]
+		if !ok {
+			diff.RemovedServices = append(diff.RemovedServices, name)
+			continue
+		}
+		if fields := changedFields(fromServices[name], toConfig); len(fields) > 0 {
+			diff.ChangedServices = append(diff.ChangedServices, ServiceChange{Service: name, Fields: fields})
+		}
+	}
+
+	return diff, nil
+}
+
+// composeServices returns the service definitions of a compose file by name
+func composeServices(compose string) (map[string]interface{}, error) {
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(compose), &parsed); err != nil {
+		return nil, err
+	}
+	services, _ := parsed["services"].(map[string]interface{})
+	if services == nil {
+		services = map[string]interface{}{}
+	}
+	return services, nil
+}
+
+// changedFields returns the sorted top-level keys whose values differ
+// between two service definitions
+func changedFields(from, to interface{}) []string {
+	fromConfig, _ := from.(map[string]interface{})
+	toConfig, _ := to.(map[string]interface{})
+
+	keys := make(map[string]bool)
+	for key := range fromConfig {
+		keys[key] = true
+	}
+	for key := range toConfig {
+		keys[key] = true
+	}
+
+	var fields []string
+	for key := range keys {
+		if !reflect.DeepEqual(fromConfig[key], toConfig[key]) {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"io"
+	"strings"
 	"time"
 )
 
@@ -28,6 +29,17 @@ type Storage interface {
 	GetURL(ctx context.Context, key string, expiry time.Duration) (string, error)
 }
 
+// normalizeStoragePrefix turns a configured prefix such as "/tenant-a" into
+// "tenant-a/" so it can be prepended to keys. Empty prefixes stay empty.
+func normalizeStoragePrefix(prefix string) string {
+	prefix = strings.ReplaceAll(prefix, "..", "")
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "/"
+}
+
 // FileInfo contains metadata about a stored file
 type FileInfo struct {
 	Key          string
@@ -17,6 +17,7 @@ import (
 // Used for development and offline deployments
 type LocalFSStorage struct {
 	basePath     string
+	keyPrefix    string // STORAGE_PREFIX, a subdirectory of basePath
 	maxSize      string
 	maxSizeBytes int64
 }
@@ -36,6 +37,7 @@ func NewLocalFSStorage(cfg *config.Config) (*LocalFSStorage, error) {
 
 	return &LocalFSStorage{
 		basePath:     cfg.LocalStoragePath,
+		keyPrefix:    normalizeStoragePrefix(cfg.StoragePrefix),
 		maxSize:      cfg.LocalStorageMaxSize,
 		maxSizeBytes: maxSizeBytes,
 	}, nil
@@ -45,7 +47,12 @@ func (l *LocalFSStorage) getFullPath(key string) string {
 	// Sanitize key to prevent directory traversal
 	key = strings.ReplaceAll(key, "..", "")
 	key = filepath.Clean(key)
-	return filepath.Join(l.basePath, key)
+	return filepath.Join(l.rootPath(), key)
+}
+
+// rootPath is the directory keys are resolved against
+func (l *LocalFSStorage) rootPath() string {
+	return filepath.Join(l.basePath, l.keyPrefix)
 }
 
 func (l *LocalFSStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64) (string, error) {
@@ -162,7 +169,7 @@ func (l *LocalFSStorage) List(ctx context.Context, prefix string) ([]FileInfo, e
 
 		if !stat.IsDir() {
 			// Get relative path from base
-			relPath, err := filepath.Rel(l.basePath, match)
+			relPath, err := filepath.Rel(l.rootPath(), match)
 			if err != nil {
 				continue
 			}
@@ -184,7 +191,7 @@ func (l *LocalFSStorage) List(ctx context.Context, prefix string) ([]FileInfo, e
 			}
 
 			if !info.IsDir() {
-				relPath, err := filepath.Rel(l.basePath, path)
+				relPath, err := filepath.Rel(l.rootPath(), path)
 				if err != nil {
 					return nil
 				}
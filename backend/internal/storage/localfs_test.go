@@ -413,3 +413,50 @@ func TestLocalFS_UploadSizeLimit(t *testing.T) {
 		t.Error("Expected error when uploading file larger than size limit")
 	}
 }
+
+// Test STORAGE_PREFIX isolates keys under a subdirectory of the base path
+func TestLocalFS_StoragePrefix(t *testing.T) {
+	tempDir := t.TempDir()
+	tenantA, err := NewLocalFSStorage(&config.Config{
+		LocalStoragePath:    tempDir,
+		LocalStorageMaxSize: "100MB",
+		StoragePrefix:       "tenant-a",
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFSStorage failed: %v", err)
+	}
+	tenantB, err := NewLocalFSStorage(&config.Config{
+		LocalStoragePath:    tempDir,
+		LocalStorageMaxSize: "100MB",
+		StoragePrefix:       "tenant-b",
+	})
+	if err != nil {
+		t.Fatalf("NewLocalFSStorage failed: %v", err)
+	}
+
+	ctx := context.Background()
+	content := []byte("tenant data")
+	if _, err := tenantA.Upload(ctx, "files/data.txt", bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "tenant-a", "files", "data.txt")); err != nil {
+		t.Errorf("Expected file under tenant prefix: %v", err)
+	}
+
+	exists, err := tenantB.Exists(ctx, "files/data.txt")
+	if err != nil {
+		t.Fatalf("Exists check failed: %v", err)
+	}
+	if exists {
+		t.Error("Expected file to be invisible to another prefix")
+	}
+
+	objects, err := tenantA.List(ctx, "files/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(objects) != 1 || filepath.ToSlash(objects[0].Key) != "files/data.txt" {
+		t.Errorf("List = %+v, want a single files/data.txt", objects)
+	}
+}
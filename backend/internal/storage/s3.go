@@ -22,6 +22,7 @@ type S3Storage struct {
 	downloader *s3manager.Downloader
 	bucket     string
 	pathPrefix string
+	keyPrefix  string // STORAGE_PREFIX, applied after the S3 path prefix
 }
 
 // NewS3Storage creates a new S3 storage instance
@@ -36,10 +37,14 @@ func NewS3Storage(cfg *config.Config) (*S3Storage, error) {
 	}
 
 	awsConfig := &aws.Config{
-		Endpoint:         aws.String(cfg.S3Endpoint),
 		Region:           aws.String(cfg.S3Region),
 		DisableSSL:       aws.Bool(!cfg.S3UseSSL),
-		S3ForcePathStyle: aws.Bool(true),
+		S3ForcePathStyle: aws.Bool(cfg.S3ForcePathStyle),
+	}
+
+	// Without an endpoint override the SDK resolves the regional AWS endpoint
+	if cfg.S3Endpoint != "" {
+		awsConfig.Endpoint = aws.String(cfg.S3Endpoint)
 	}
 
 	if cfg.S3AccessKeyID != "" && cfg.S3SecretAccessKey != "" {
@@ -63,11 +68,12 @@ func NewS3Storage(cfg *config.Config) (*S3Storage, error) {
 		downloader: s3manager.NewDownloader(sess),
 		bucket:     cfg.S3Bucket,
 		pathPrefix: cfg.S3PathPrefix,
+		keyPrefix:  normalizeStoragePrefix(cfg.StoragePrefix),
 	}, nil
 }
 
 func (s *S3Storage) getFullKey(key string) string {
-	return s.pathPrefix + key
+	return s.pathPrefix + s.keyPrefix + key
 }
 
 func (s *S3Storage) Upload(ctx context.Context, key string, reader io.Reader, size int64) (string, error) {
@@ -129,8 +135,9 @@ func (s *S3Storage) Exists(ctx context.Context, key string) (bool, error) {
 
 	_, err := s.client.HeadObjectWithContext(ctx, input)
 	if err != nil {
-		// Check if the error is a not found error
-		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+		// HEAD responses have no body, so a missing key surfaces as a bare
+		// 404 "NotFound" rather than NoSuchKey
+		if aerr, ok := err.(awserr.Error); ok && (aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == "NotFound") {
 			return false, nil
 		}
 		return false, fmt.Errorf("failed to check S3 object existence: %w", err)
@@ -1,8 +1,15 @@
 package storage
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"        //nolint:staticcheck // AWS SDK v1 still in use, v2 migration planned
+	"github.com/aws/aws-sdk-go/service/s3" //nolint:staticcheck // AWS SDK v1 still in use, v2 migration planned
 	"github.com/burndler/burndler/internal/config"
 )
 
@@ -15,6 +22,7 @@ func TestNewS3Storage(t *testing.T) {
 		S3AccessKeyID:     "test-key",
 		S3SecretAccessKey: "test-secret",
 		S3UseSSL:          true,
+		S3ForcePathStyle:  true,
 		S3PathPrefix:      "packages/",
 	}
 
@@ -28,6 +36,66 @@ func TestNewS3Storage(t *testing.T) {
 	}
 }
 
+// Test NewS3Storage applies the endpoint override and addressing style
+func TestNewS3Storage_AddressingStyle(t *testing.T) {
+	tests := []struct {
+		name           string
+		forcePathStyle bool
+		expectedHost   string
+		expectedPath   string
+	}{
+		{
+			name:           "path-style",
+			forcePathStyle: true,
+			expectedHost:   "minio.internal:9000",
+			expectedPath:   "/test-bucket/packages/app.tar.gz",
+		},
+		{
+			name:           "virtual-hosted",
+			forcePathStyle: false,
+			expectedHost:   "test-bucket.minio.internal:9000",
+			expectedPath:   "/packages/app.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				S3Endpoint:        "http://minio.internal:9000",
+				S3Region:          "us-east-1",
+				S3Bucket:          "test-bucket",
+				S3AccessKeyID:     "test-key",
+				S3SecretAccessKey: "test-secret",
+				S3ForcePathStyle:  tt.forcePathStyle,
+				S3PathPrefix:      "packages/",
+			}
+
+			storage, err := NewS3Storage(cfg)
+			if err != nil {
+				t.Fatalf("NewS3Storage failed: %v", err)
+			}
+
+			if got := *storage.client.Config.S3ForcePathStyle; got != tt.forcePathStyle {
+				t.Errorf("S3ForcePathStyle = %v, want %v", got, tt.forcePathStyle)
+			}
+
+			req, _ := storage.client.GetObjectRequest(&s3.GetObjectInput{
+				Bucket: aws.String(storage.bucket),
+				Key:    aws.String(storage.getFullKey("app.tar.gz")),
+			})
+			if err := req.Build(); err != nil {
+				t.Fatalf("Failed to build request: %v", err)
+			}
+			if req.HTTPRequest.URL.Host != tt.expectedHost {
+				t.Errorf("Host = %q, want %q", req.HTTPRequest.URL.Host, tt.expectedHost)
+			}
+			if req.HTTPRequest.URL.Path != tt.expectedPath {
+				t.Errorf("Path = %q, want %q", req.HTTPRequest.URL.Path, tt.expectedPath)
+			}
+		})
+	}
+}
+
 // Test NewS3Storage with missing bucket
 func TestNewS3Storage_MissingBucket(t *testing.T) {
 	cfg := &config.Config{
@@ -37,6 +105,7 @@ func TestNewS3Storage_MissingBucket(t *testing.T) {
 		S3AccessKeyID:     "test-key",
 		S3SecretAccessKey: "test-secret",
 		S3UseSSL:          true,
+		S3ForcePathStyle:  true,
 		S3PathPrefix:      "packages/",
 	}
 
@@ -55,6 +124,7 @@ func TestNewS3Storage_MissingCredentials(t *testing.T) {
 		S3AccessKeyID:     "", // Missing credentials
 		S3SecretAccessKey: "",
 		S3UseSSL:          true,
+		S3ForcePathStyle:  true,
 		S3PathPrefix:      "packages/",
 	}
 
@@ -63,3 +133,127 @@ func TestNewS3Storage_MissingCredentials(t *testing.T) {
 		t.Error("Expected error when credentials are missing")
 	}
 }
+
+// Test Exists against a fake S3 endpoint for present and absent objects
+func TestS3Storage_Exists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/test-bucket/packages/present.txt":
+			w.Header().Set("Content-Length", "7")
+			w.WriteHeader(http.StatusOK)
+		case "/test-bucket/packages/broken.txt":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s3, err := NewS3Storage(&config.Config{
+		S3Endpoint:        server.URL,
+		S3Region:          "us-east-1",
+		S3Bucket:          "test-bucket",
+		S3AccessKeyID:     "test-key",
+		S3SecretAccessKey: "test-secret",
+		S3UseSSL:          false,
+		S3ForcePathStyle:  true,
+		S3PathPrefix:      "packages/",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage failed: %v", err)
+	}
+
+	ctx := context.Background()
+
+	exists, err := s3.Exists(ctx, "present.txt")
+	if err != nil {
+		t.Fatalf("Exists check failed: %v", err)
+	}
+	if !exists {
+		t.Error("Expected true for existing object")
+	}
+
+	exists, err = s3.Exists(ctx, "absent.txt")
+	if err != nil {
+		t.Fatalf("Exists check failed: %v", err)
+	}
+	if exists {
+		t.Error("Expected false for missing object")
+	}
+
+	if _, err := s3.Exists(ctx, "broken.txt"); err == nil {
+		t.Error("Expected error for a forbidden object")
+	}
+}
+
+// Test GetURL presigns a GET for the prefixed key without calling S3
+func TestS3Storage_GetURL(t *testing.T) {
+	s3, err := NewS3Storage(&config.Config{
+		S3Endpoint:        "https://s3.example.com",
+		S3Region:          "us-east-1",
+		S3Bucket:          "test-bucket",
+		S3AccessKeyID:     "test-key",
+		S3SecretAccessKey: "test-secret",
+		S3UseSSL:          true,
+		S3ForcePathStyle:  true,
+		S3PathPrefix:      "packages/",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage failed: %v", err)
+	}
+
+	presigned, err := s3.GetURL(context.Background(), "builds/demo.tar.gz", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GetURL failed: %v", err)
+	}
+
+	parsed, err := url.Parse(presigned)
+	if err != nil {
+		t.Fatalf("Invalid presigned URL %q: %v", presigned, err)
+	}
+	if parsed.Host != "s3.example.com" || parsed.Path != "/test-bucket/packages/builds/demo.tar.gz" {
+		t.Errorf("Presigned URL = %s, want s3.example.com/test-bucket/packages/builds/demo.tar.gz", presigned)
+	}
+	query := parsed.Query()
+	if query.Get("X-Amz-Expires") != "900" {
+		t.Errorf("X-Amz-Expires = %q, want 900", query.Get("X-Amz-Expires"))
+	}
+	if query.Get("X-Amz-Signature") == "" {
+		t.Error("Expected presigned URL to carry a signature")
+	}
+}
+
+// Test STORAGE_PREFIX is inserted between the path prefix and the key
+func TestS3Storage_StoragePrefix(t *testing.T) {
+	s3, err := NewS3Storage(&config.Config{
+		S3Endpoint:        "https://s3.example.com",
+		S3Region:          "us-east-1",
+		S3Bucket:          "test-bucket",
+		S3AccessKeyID:     "test-key",
+		S3SecretAccessKey: "test-secret",
+		S3UseSSL:          true,
+		S3ForcePathStyle:  true,
+		S3PathPrefix:      "packages/",
+		StoragePrefix:     "/tenant-a/",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage failed: %v", err)
+	}
+
+	presigned, err := s3.GetURL(context.Background(), "builds/demo.tar.gz", time.Minute)
+	if err != nil {
+		t.Fatalf("GetURL failed: %v", err)
+	}
+
+	parsed, err := url.Parse(presigned)
+	if err != nil {
+		t.Fatalf("Invalid presigned URL %q: %v", presigned, err)
+	}
+	if parsed.Path != "/test-bucket/packages/tenant-a/builds/demo.tar.gz" {
+		t.Errorf("Presigned path = %s, want /test-bucket/packages/tenant-a/builds/demo.tar.gz", parsed.Path)
+	}
+}
@@ -25,7 +25,7 @@ export default function PackageBuilder() {
       const status = await api.getBuildStatus(buildId);
       setCurrentBuild(status);
 
-      if (status.status === 'completed' || status.status === 'failed') {
+      if (status.status === 'completed' || status.status === 'failed' || status.status === 'cancelled') {
         if (pollingInterval) {
           clearInterval(pollingInterval);
           setPollingInterval(null);
@@ -28,6 +28,8 @@ class ContainerService {
       if (filters.show_deleted) params.append('show_deleted', filters.show_deleted.toString());
       if (filters.published_only)
         params.append('published_only', filters.published_only.toString());
+      if (filters.search) params.append('search', filters.search);
+      if (filters.sort) params.append('sort', filters.sort);
 
       const queryString = params.toString();
       const url = queryString ? `/containers?${queryString}` : '/containers';
@@ -71,6 +73,14 @@ class ContainerService {
     }
   }
 
+  async restoreContainer(id: number): Promise<Container> {
+    try {
+      return await this.client.post(`/containers/${id}/restore`);
+    } catch (error: any) {
+      throw this.handleError(error);
+    }
+  }
+
   // Container Version Operations
   async listVersions(
     containerId: number,
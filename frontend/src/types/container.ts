@@ -21,6 +21,7 @@ export interface ContainerVersion {
   variables: Record<string, any>;
   resource_paths: string[];
   dependencies: Record<string, string>;
+  channel?: 'stable' | 'beta' | 'edge';
   published: boolean;
   published_at?: string;
   created_at: string;
@@ -73,6 +74,7 @@ export interface PaginationInfo {
   page_size: number;
   total: number;
   total_pages: number;
+  has_next?: boolean;
 }
 
 // Query Parameters
@@ -84,6 +86,7 @@ export interface ContainerFilters {
   show_deleted?: boolean;
   published_only?: boolean;
   search?: string;
+  sort?: string; // name, author, created_at or updated_at; prefix with - for descending
 }
 
 export interface VersionFilters {
@@ -55,7 +55,7 @@ export interface Resource {
 export interface Build {
   id: string;
   name: string;
-  status: 'queued' | 'building' | 'completed' | 'failed';
+  status: 'queued' | 'building' | 'completed' | 'failed' | 'cancelled';
   progress: number;
   downloadUrl?: string;
   error?: string;
@@ -9,6 +9,7 @@ export interface Service {
   name: string;
   description: string;
   active: boolean;
+  archived?: boolean;
   user_id: number;
   created_at: string;
   updated_at: string;